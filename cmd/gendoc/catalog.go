@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	sectionAPIReference       = "API Reference"
+	sectionSampleData         = "Sample data"
+	sectionLocationInArchive  = "Location in archive"
+	sectionConfigID           = "Config ID"
+	sectionReleasedVersion    = "Released version"
+	sectionBackportedVersions = "Backported versions"
+	schemaFileSuffix          = ".schema.json"
+)
+
+var (
+	reSectionTitle     = regexp.MustCompile(`^###\s+(.*)$`)
+	reTableSeparator   = regexp.MustCompile(`^\|[\s|:-]*\|$`)
+	reSampleArchiveRef = regexp.MustCompile(`docs/insights-archive-sample/\S+`)
+	// rePlaceholder matches the placeholders of the archive locations, e.g. "{name}"
+	rePlaceholder = regexp.MustCompile(`\{[^/{}]+\}`)
+)
+
+// Catalog is the machine-readable description of all the gathering functions
+type Catalog struct {
+	GatheringFunctions []CatalogEntry `json:"gathering_functions"`
+}
+
+// CatalogEntry describes a single gathering function in the catalog
+type CatalogEntry struct {
+	Name               string   `json:"name"`
+	Function           string   `json:"function"`
+	Package            string   `json:"package"`
+	ConfigIDs          []string `json:"config_ids"`
+	Description        string   `json:"description"`
+	ArchiveLocations   []string `json:"archive_locations"`
+	SampleData         []string `json:"sample_data"`
+	ReleasedVersions   []string `json:"released_versions"`
+	BackportedVersions []string `json:"backported_versions"`
+	APIReferences      []string `json:"api_references"`
+	RecordSchema       string   `json:"record_schema,omitempty"`
+	// RecordSchemaLocations are the archive locations of the records described by the RecordSchema,
+	// the other locations are written with another marshaller (e.g. the Kubernetes resources)
+	RecordSchemaLocations []string `json:"record_schema_locations,omitempty"`
+}
+
+// parseSections splits the gatherer documentation to the description and the items of the "###" sections.
+// The section items are the list items, the backtick quoted values or the last column of the table rows.
+// "None" means the section is empty.
+func parseSections(doc string) (description string, sections map[string][]string) {
+	sections = map[string][]string{}
+	var descriptionLines []string
+	section := ""
+	for _, line := range strings.Split(doc, "\n") {
+		if m := reSectionTitle.FindStringSubmatch(line); m != nil {
+			section = strings.TrimSpace(m[1])
+			if _, exists := sections[section]; !exists {
+				sections[section] = []string{}
+			}
+			continue
+		}
+		if section == "" {
+			descriptionLines = append(descriptionLines, line)
+			continue
+		}
+		sections[section] = append(sections[section], sectionItems(line)...)
+	}
+	return strings.TrimSpace(strings.Join(descriptionLines, "\n")), sections
+}
+
+func sectionItems(line string) []string {
+	line = strings.TrimSpace(line)
+	switch {
+	case line == "" || line == "None":
+		return nil
+	case strings.HasPrefix(line, "|"):
+		if reTableSeparator.MatchString(line) {
+			return nil
+		}
+		cells := strings.Split(strings.Trim(line, "|"), "|")
+		item := strings.Trim(strings.TrimSpace(cells[len(cells)-1]), "`")
+		if item == "" || item == "Path" {
+			return nil
+		}
+		return []string{item}
+	case strings.HasPrefix(line, "- "):
+		return []string{strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "- ")), "`")}
+	}
+	if quoted := reBacktickContent.FindAllStringSubmatch(line, -1); len(quoted) > 0 {
+		items := make([]string, 0, len(quoted))
+		for _, q := range quoted {
+			items = append(items, q[1])
+		}
+		return items
+	}
+	return []string{line}
+}
+
+// newCatalogEntry creates the catalog entry from the parsed documentation of the gathering function
+func newCatalogEntry(name string, db *DocBlock) CatalogEntry {
+	description, sections := parseSections(db.RawDoc)
+	entry := CatalogEntry{
+		Name:               name,
+		Function:           db.Function,
+		Package:            db.Package,
+		ConfigIDs:          nonNil(sections[sectionConfigID]),
+		Description:        description,
+		ArchiveLocations:   nonNil(sections[sectionLocationInArchive]),
+		ReleasedVersions:   nonNil(sections[sectionReleasedVersion]),
+		BackportedVersions: nonNil(sections[sectionBackportedVersions]),
+		APIReferences:      nonNil(sections[sectionAPIReference]),
+		SampleData:         []string{},
+	}
+	for _, sample := range sections[sectionSampleData] {
+		entry.SampleData = append(entry.SampleData, reSampleArchiveRef.FindAllString(sample, -1)...)
+	}
+	if db.RecordSchema != nil && len(entry.ConfigIDs) > 0 {
+		entry.RecordSchema = entry.ConfigIDs[0] + schemaFileSuffix
+		entry.RecordSchemaLocations = recordLocations(entry.ArchiveLocations, db.RecordNames)
+	}
+	return entry
+}
+
+// recordLocations returns the archive locations matching any of the record name patterns.
+// The placeholders of the locations stand for any record name part.
+func recordLocations(locations []string, recordNames []*regexp.Regexp) []string {
+	var result []string
+	for _, location := range locations {
+		sample := rePlaceholder.ReplaceAllString(location, "x")
+		for _, name := range recordNames {
+			if name.MatchString(sample) {
+				result = append(result, location)
+				break
+			}
+		}
+	}
+	return result
+}
+
+func nonNil(items []string) []string {
+	if items == nil {
+		return []string{}
+	}
+	return items
+}
+
+// writeCatalog writes the JSON catalog of the gathering functions to the catalog path and the JSON schemas
+// of the recorded data to the schemas directory. The empty path disables the output.
+func writeCatalog(md map[string]*DocBlock, catalogPath, schemasDir string) error {
+	keys := make([]string, 0, len(md))
+	for k := range md {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	catalog := Catalog{GatheringFunctions: []CatalogEntry{}}
+	for _, k := range keys {
+		entry := newCatalogEntry(k, md[k])
+		catalog.GatheringFunctions = append(catalog.GatheringFunctions, entry)
+		if schemasDir == "" || entry.RecordSchema == "" {
+			continue
+		}
+		if err := writeJSON(filepath.Join(schemasDir, entry.RecordSchema), md[k].RecordSchema); err != nil {
+			return err
+		}
+	}
+
+	if catalogPath == "" {
+		return nil
+	}
+	return writeJSON(catalogPath, catalog)
+}
+
+func writeJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil { // nolint: gosec
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644) // nolint: gosec
+}
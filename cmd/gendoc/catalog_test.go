@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testGathererDoc = `Collects the nodes.
+
+### API Reference
+- https://docs.openshift.com/container-platform/4.3/rest_api/index.html#nodelist-v1core
+
+### Sample data
+- docs/insights-archive-sample/config/node/ip-10-0-135-228.us-east-2.compute.internal.json
+
+### Location in archive
+| Version   | Path                      |
+| --------- | ------------------------- |
+| >= 4.2.0  | config/node/{name}.json   |
+
+### Config ID
+` + "`clusterconfig/nodes`" + `
+
+### Released version
+- 4.2.0
+
+### Backported versions
+None
+`
+
+func Test_newCatalogEntry(t *testing.T) {
+	db := parseDoc("GatherNodes", testGathererDoc)
+	db.Function = "GatherNodes"
+	db.Package = "github.com/openshift/insights-operator/pkg/gatherers/clusterconfig"
+	db.RecordSchema = map[string]interface{}{"type": "object"}
+	db.RecordNames = []*regexp.Regexp{regexp.MustCompile(`^config/node/.+\.json$`)}
+
+	assert.Equal(t, CatalogEntry{
+		Name:                  "Nodes",
+		Function:              "GatherNodes",
+		Package:               "github.com/openshift/insights-operator/pkg/gatherers/clusterconfig",
+		ConfigIDs:             []string{"clusterconfig/nodes"},
+		Description:           "Collects the nodes.",
+		ArchiveLocations:      []string{"config/node/{name}.json"},
+		SampleData:            []string{"docs/insights-archive-sample/config/node/ip-10-0-135-228.us-east-2.compute.internal.json"},
+		ReleasedVersions:      []string{"4.2.0"},
+		BackportedVersions:    []string{},
+		APIReferences:         []string{"https://docs.openshift.com/container-platform/4.3/rest_api/index.html#nodelist-v1core"},
+		RecordSchema:          "clusterconfig/nodes.schema.json",
+		RecordSchemaLocations: []string{"config/node/{name}.json"},
+	}, newCatalogEntry("Nodes", db))
+}
+
+const testGathererSource = `package clusterconfig
+
+type CompactedEvent struct {
+	Namespace     string    ` + "`json:\"namespace\"`" + `
+	LastTimestamp time.Time ` + "`json:\"lastTimestamp\"`" + `
+	Count         int       ` + "`json:\"count,omitempty\"`" + `
+	internal      string
+}
+
+type CompactedEventList struct {
+	Items []CompactedEvent ` + "`json:\"items\"`" + `
+}
+
+type Gatherer struct{}
+
+func (g *Gatherer) GatherEvents(ctx context.Context) ([]record.Record, []error) {
+	return g.gatherEvents(ctx)
+}
+
+func (g *Gatherer) gatherEvents(ctx context.Context) ([]record.Record, []error) {
+	events, err := compactEvents()
+	if err != nil {
+		return nil, []error{err}
+	}
+	return []record.Record{
+		{Name: "events/test", Item: record.JSONMarshaller{Object: &events}},
+		{Name: fmt.Sprintf("%s/%v-100%%", eventsDir, ns), Item: &record.JSONMarshaller{Object: &events}},
+	}, nil
+}
+
+const eventsDir = "events/namespaces"
+
+func compactEvents() (CompactedEventList, error) {
+	return CompactedEventList{}, nil
+}
+
+func (g *Gatherer) GatherResource(ctx context.Context) ([]record.Record, []error) {
+	return []record.Record{{Name: "config/resource", Item: record.ResourceMarshaller{Resource: resource}}}, nil
+}
+`
+
+func Test_packageIndex_recordSchema(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "gather_events.go", testGathererSource, parser.ParseComments)
+	assert.NoError(t, err)
+	idx := newPackageIndex(&ast.Package{Name: "clusterconfig", Files: map[string]*ast.File{"gather_events.go": file}}) //nolint: staticcheck
+
+	recordSchema, recordNames := idx.recordSchema(idx.funcs["Gatherer.GatherEvents"])
+	schema, err := json.Marshal(recordSchema)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"title": "CompactedEventList",
+		"type": "object",
+		"properties": {
+			"items": {
+				"type": ["array", "null"],
+				"items": {
+					"title": "CompactedEvent",
+					"type": "object",
+					"properties": {
+						"namespace": {"type": "string"},
+						"lastTimestamp": {"type": "string", "format": "date-time"},
+						"count": {"type": "integer"}
+					},
+					"required": ["lastTimestamp", "namespace"]
+				}
+			}
+		},
+		"required": ["items"]
+	}`, string(schema))
+
+	patterns := make([]string, 0, len(recordNames))
+	for _, name := range recordNames {
+		patterns = append(patterns, name.String())
+	}
+	assert.Equal(t, []string{`^events/test\.json$`, `^events/namespaces/.+-100%\.json$`}, patterns)
+	assert.Equal(t,
+		[]string{"events/test.json", "events/namespaces/{namespace}-100%.json"},
+		recordLocations([]string{"events/test.json", "events/namespaces/{namespace}-100%.json", "config/resource.json"}, recordNames))
+
+	recordSchema, recordNames = idx.recordSchema(idx.funcs["Gatherer.GatherResource"])
+	assert.Nil(t, recordSchema)
+	assert.Empty(t, recordNames)
+}
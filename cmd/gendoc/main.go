@@ -34,9 +34,11 @@ const (
 var (
 	inPath                   string
 	outPath                  string
+	catalogPath              string
+	schemasDir               string
 	mdf                      *os.File
 	randSource               = rand.NewSource(time.Now().UnixNano())
-	reGather                 = regexp.MustCompile(`^((Build)?Gather)(.*)`)
+	reGather                 = regexp.MustCompile(`^(Build)?(Legacy)?Gather(.*)`)
 	reExample                = regexp.MustCompile(`^(Example)(.*)`)
 	reSampleArchive          = regexp.MustCompile(`docs/(insights-archive-sample/.*)`)
 	reGathererNameValidation = regexp.MustCompile("^[a-z]+[_a-z]*[a-z]([/a-z][_a-z]*)?[a-z]$")
@@ -48,8 +50,14 @@ var (
 )
 
 type DocBlock struct {
-	Doc      string
-	Examples map[string]string
+	Doc          string
+	RawDoc       string
+	Examples     map[string]string
+	Function     string
+	Package      string
+	RecordSchema map[string]interface{}
+	// RecordNames are the patterns of the names of the records described by the RecordSchema
+	RecordNames []*regexp.Regexp
 }
 
 func main() {
@@ -59,6 +67,8 @@ func main() {
 func run() int {
 	flag.StringVar(&inPath, "in", "gatherers", "Package where to find Gather methods")
 	flag.StringVar(&outPath, "out", "gathered-data.md", "File to which MD doc will be generated")
+	flag.StringVar(&catalogPath, "catalog", "", "File to which JSON catalog of the gathering functions will be generated")
+	flag.StringVar(&schemasDir, "schemas", "", "Directory to which JSON schemas of the recorded data will be generated")
 
 	flag.Parse()
 	var err error
@@ -144,6 +154,10 @@ func run() int {
 			}
 		}
 	}
+	if err := writeCatalog(md, catalogPath, schemasDir); err != nil {
+		log.Printf(errWritingFile, catalogPath, err)
+		return exitError
+	}
 	log.Println("Done")
 	return exitOk
 }
@@ -170,6 +184,7 @@ func validateGathererName(gatherer string) error {
 func walkDir(cleanRoot string, md map[string]*DocBlock) error {
 	expPath := ""
 	fset := token.NewFileSet() // positions are relative to fset
+	indexes := map[string]*packageIndex{}
 	return filepath.Walk(cleanRoot, func(path string, info os.FileInfo, _ error) error {
 		if !info.IsDir() {
 			return nil
@@ -205,12 +220,20 @@ func walkDir(cleanRoot string, md map[string]*DocBlock) error {
 				// handle function declarations
 				fn, ok := n.(*ast.FuncDecl)
 				if ok {
-					gatherMethodWithSuff := reGather.ReplaceAllString(fn.Name.Name, "$3")
+					gatherMethodWithSuff := reGather.ReplaceAllString(fn.Name.Name, "$2$3")
 					_, ok2 := md[gatherMethodWithSuff]
-					startsWithGatherOrBuildGather := strings.HasPrefix(fn.Name.Name, "Gather") || strings.HasPrefix(fn.Name.Name, "BuildGather")
+					startsWithGatherOrBuildGather := strings.HasPrefix(fn.Name.Name, "Gather") ||
+						strings.HasPrefix(fn.Name.Name, "BuildGather") || strings.HasPrefix(fn.Name.Name, "BuildLegacyGather")
 					if !ok2 && fn.Name.IsExported() && startsWithGatherOrBuildGather && len(fn.Name.Name) > len("Gather") {
 						doc := fn.Doc.Text()
-						md[gatherMethodWithSuff] = parseDoc(fn.Name.Name, doc)
+						db := parseDoc(fn.Name.Name, doc)
+						db.Function = fn.Name.Name
+						db.Package = mustGetPackageName(cleanRoot, astPackage)
+						if indexes[db.Package] == nil {
+							indexes[db.Package] = newPackageIndex(astPackage)
+						}
+						db.RecordSchema, db.RecordNames = indexes[db.Package].recordSchema(fn)
+						md[gatherMethodWithSuff] = db
 						fmt.Printf("%s", fn.Name.Name+"\n")
 					}
 					// Example methods will have Example prefix, and might have additional case suffix:
@@ -393,8 +416,9 @@ func parseDoc(method, doc string) *DocBlock {
 		doc = strings.TrimLeft(doc, method)
 	}
 	doc = strings.TrimLeft(doc, " ")
+	rawDoc := doc
 	// generates the link to the sample archive
 	doc = reSampleArchive.ReplaceAllString(doc, "[$0](./$1)")
-	db := &DocBlock{Doc: doc}
+	db := &DocBlock{Doc: doc, RawDoc: rawDoc}
 	return db
 }
@@ -0,0 +1,567 @@
+package main
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/token"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	recordPackageName   = "record"
+	jsonMarshallerType  = "JSONMarshaller"
+	jsonMarshallerField = "Object"
+	jsonSchemaDraft     = "http://json-schema.org/draft-07/schema#"
+	// jsonRecordExtension is the extension the recorder adds to the names of the records with the record.JSONMarshaller
+	jsonRecordExtension = ".json"
+)
+
+// reFormatVerb matches the verbs of the fmt.Sprintf format of the record names
+var reFormatVerb = regexp.MustCompile(`%[-+# 0]*[0-9]*(\.[0-9]+)?[a-zA-Z%]`)
+
+// knownExternalTypes are the JSON schemas of the types declared outside of the gatherer packages
+// which have a simple JSON representation
+var knownExternalTypes = map[string]map[string]interface{}{
+	"time.Time":     {"type": "string", "format": "date-time"},
+	"metav1.Time":   {"type": "string", "format": "date-time"},
+	"time.Duration": {"type": "integer"},
+}
+
+// packageIndex holds the declarations of a single package needed to find the recorded Go types.
+// The methods are indexed by the receiver type and the method name (e.g. "Gatherer.GatherNodes").
+type packageIndex struct {
+	funcs      map[string]*ast.FuncDecl
+	types      map[string]*ast.TypeSpec
+	values     map[string]ast.Expr
+	marshalers map[string]bool
+}
+
+func newPackageIndex(pkg *ast.Package) *packageIndex { //nolint: staticcheck
+	idx := &packageIndex{
+		funcs:      map[string]*ast.FuncDecl{},
+		types:      map[string]*ast.TypeSpec{},
+		values:     map[string]ast.Expr{},
+		marshalers: map[string]bool{},
+	}
+	for fileName, file := range pkg.Files {
+		if strings.HasSuffix(fileName, "_test.go") {
+			continue
+		}
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil || len(d.Recv.List) == 0 {
+					idx.funcs[d.Name.Name] = d
+					continue
+				}
+				recvType := namedType(d.Recv.List[0].Type)
+				idx.funcs[recvType+"."+d.Name.Name] = d
+				if d.Name.Name == "MarshalJSON" {
+					idx.marshalers[recvType] = true
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch sp := spec.(type) {
+					case *ast.TypeSpec:
+						idx.types[sp.Name.Name] = sp
+					case *ast.ValueSpec:
+						for i, name := range sp.Names {
+							if i < len(sp.Values) {
+								idx.values[name.Name] = sp.Values[i]
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return idx
+}
+
+// recordSchema returns the JSON schema of the data recorded by the function with the record.JSONMarshaller
+// or nil when the recorded types are not known. The functions of the same package called by the function
+// are searched as well. The patterns of the names of the records with the record.JSONMarshaller are returned
+// too, so that the schema can be assigned to the archive locations of the records.
+func (idx *packageIndex) recordSchema(fn *ast.FuncDecl) (map[string]interface{}, []*regexp.Regexp) {
+	var schemas []map[string]interface{}
+	var namePatterns []*regexp.Regexp
+	seen := map[string]bool{}
+	visited := map[*ast.FuncDecl]bool{}
+	queue := []*ast.FuncDecl{fn}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if visited[current] || current.Body == nil {
+			continue
+		}
+		visited[current] = true
+
+		ast.Inspect(current.Body, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.CallExpr:
+				if called := idx.calledFunc(node); called != nil {
+					queue = append(queue, called)
+				}
+			case *ast.CompositeLit:
+				if pattern := idx.jsonRecordNamePattern(node); pattern != nil {
+					namePatterns = append(namePatterns, pattern)
+				}
+				object := jsonMarshallerObject(node)
+				if object == nil {
+					return true
+				}
+				typ := idx.exprType(object)
+				if typ == nil {
+					return true
+				}
+				schema := idx.typeSchema(typ, map[string]bool{})
+				key := schemaKey(schema)
+				if len(schema) > 0 && !seen[key] {
+					seen[key] = true
+					schemas = append(schemas, schema)
+				}
+			}
+			return true
+		})
+	}
+
+	switch len(schemas) {
+	case 0:
+		return nil, nil
+	case 1:
+		schemas[0]["$schema"] = jsonSchemaDraft
+		return schemas[0], namePatterns
+	default:
+		sort.Slice(schemas, func(i, j int) bool { return schemaKey(schemas[i]) < schemaKey(schemas[j]) })
+		list := make([]interface{}, 0, len(schemas))
+		for _, schema := range schemas {
+			list = append(list, schema)
+		}
+		return map[string]interface{}{"$schema": jsonSchemaDraft, "anyOf": list}, namePatterns
+	}
+}
+
+// jsonRecordNamePattern returns the pattern of the archive path of the record.Record composite literal
+// with the record.JSONMarshaller item or nil when it's not such a record or its name can't be resolved
+func (idx *packageIndex) jsonRecordNamePattern(lit *ast.CompositeLit) *regexp.Regexp {
+	var name, item ast.Expr
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		if key, ok := kv.Key.(*ast.Ident); ok {
+			switch key.Name {
+			case "Name":
+				name = kv.Value
+			case "Item":
+				item = kv.Value
+			}
+		}
+	}
+	if unary, ok := item.(*ast.UnaryExpr); ok {
+		item = unary.X
+	}
+	itemLit, ok := item.(*ast.CompositeLit)
+	if name == nil || !ok || jsonMarshallerObject(itemLit) == nil {
+		return nil
+	}
+	pattern, ok := idx.namePattern(name)
+	if !ok {
+		return nil
+	}
+	return regexp.MustCompile("^" + pattern + regexp.QuoteMeta(jsonRecordExtension) + "$")
+}
+
+// namePattern converts the record name expression to the regular expression. The string literals, the constants,
+// the variables and the fmt.Sprintf calls are resolved, the formatted values which can't be resolved match any text.
+func (idx *packageIndex) namePattern(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		value, ok := stringLiteral(e)
+		return regexp.QuoteMeta(value), ok
+	case *ast.ParenExpr:
+		return idx.namePattern(e.X)
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		x, ok := idx.namePattern(e.X)
+		if !ok {
+			return "", false
+		}
+		y, ok := idx.namePattern(e.Y)
+		return x + y, ok
+	case *ast.Ident:
+		if value := idx.identValue(e); value != nil {
+			return idx.namePattern(value)
+		}
+	case *ast.CallExpr:
+		sel, ok := e.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Sprintf" || len(e.Args) == 0 {
+			return "", false
+		}
+		if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "fmt" {
+			return "", false
+		}
+		format, ok := e.Args[0].(*ast.BasicLit)
+		if !ok {
+			return "", false
+		}
+		value, ok := stringLiteral(format)
+		if !ok {
+			return "", false
+		}
+		var pattern strings.Builder
+		last := 0
+		arg := 1
+		for _, loc := range reFormatVerb.FindAllStringIndex(value, -1) {
+			pattern.WriteString(regexp.QuoteMeta(value[last:loc[0]]))
+			last = loc[1]
+			if value[loc[0]:loc[1]] == "%%" {
+				pattern.WriteString("%")
+				continue
+			}
+			argPattern := ".+"
+			if arg < len(e.Args) {
+				if resolved, ok := idx.namePattern(e.Args[arg]); ok {
+					argPattern = resolved
+				}
+			}
+			pattern.WriteString(argPattern)
+			arg++
+		}
+		pattern.WriteString(regexp.QuoteMeta(value[last:]))
+		return pattern.String(), true
+	}
+	return "", false
+}
+
+// identValue returns the value the identifier was declared or first assigned with
+// or nil when it can't be found from the declarations
+func (idx *packageIndex) identValue(ident *ast.Ident) ast.Expr {
+	if ident.Obj == nil {
+		return idx.values[ident.Name]
+	}
+	switch decl := ident.Obj.Decl.(type) {
+	case *ast.ValueSpec:
+		for i, name := range decl.Names {
+			if name.Name == ident.Name && i < len(decl.Values) {
+				return decl.Values[i]
+			}
+		}
+	case *ast.AssignStmt:
+		if len(decl.Lhs) != len(decl.Rhs) {
+			return nil
+		}
+		for i, lhs := range decl.Lhs {
+			if name, ok := lhs.(*ast.Ident); ok && name.Name == ident.Name {
+				return decl.Rhs[i]
+			}
+		}
+	}
+	return nil
+}
+
+func stringLiteral(lit *ast.BasicLit) (string, bool) {
+	if lit.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	return value, err == nil
+}
+
+// jsonMarshallerObject returns the Object value of the record.JSONMarshaller composite literal
+func jsonMarshallerObject(lit *ast.CompositeLit) ast.Expr {
+	sel, ok := lit.Type.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != jsonMarshallerType {
+		return nil
+	}
+	if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != recordPackageName {
+		return nil
+	}
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if key, ok := kv.Key.(*ast.Ident); ok && key.Name == jsonMarshallerField {
+				return kv.Value
+			}
+		}
+	}
+	return nil
+}
+
+// calledFunc returns the declaration of the package function or method called by the expression
+// or nil when it's not declared in the package
+func (idx *packageIndex) calledFunc(call *ast.CallExpr) *ast.FuncDecl {
+	switch f := call.Fun.(type) {
+	case *ast.Ident:
+		return idx.funcs[f.Name]
+	case *ast.SelectorExpr:
+		if typ := idx.exprType(f.X); typ != nil {
+			if recvType := namedType(typ); recvType != "" {
+				return idx.funcs[recvType+"."+f.Sel.Name]
+			}
+		}
+	}
+	return nil
+}
+
+// exprType returns the type expression of the value or nil when it can't be found
+// from the declarations without the full type checking
+func (idx *packageIndex) exprType(expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return idx.exprType(e.X)
+	case *ast.UnaryExpr:
+		return idx.exprType(e.X)
+	case *ast.StarExpr:
+		if typ := idx.exprType(e.X); typ != nil {
+			if star, ok := typ.(*ast.StarExpr); ok {
+				return star.X
+			}
+			return typ
+		}
+	case *ast.CompositeLit:
+		return e.Type
+	case *ast.CallExpr:
+		if ident, ok := e.Fun.(*ast.Ident); ok && ident.Name == "make" && len(e.Args) > 0 {
+			return e.Args[0]
+		}
+		return idx.resultType(e, 0)
+	case *ast.SelectorExpr:
+		return idx.fieldType(e)
+	case *ast.Ident:
+		return idx.identType(e)
+	}
+	return nil
+}
+
+// resultType returns the type of the i-th result of the package function called by the expression
+func (idx *packageIndex) resultType(call *ast.CallExpr, i int) ast.Expr {
+	called := idx.calledFunc(call)
+	if called == nil || called.Type.Results == nil {
+		return nil
+	}
+	n := 0
+	for _, result := range called.Type.Results.List {
+		count := len(result.Names)
+		if count == 0 {
+			count = 1
+		}
+		if i < n+count {
+			return result.Type
+		}
+		n += count
+	}
+	return nil
+}
+
+// fieldType returns the type of the field of the struct declared in the package
+func (idx *packageIndex) fieldType(sel *ast.SelectorExpr) ast.Expr {
+	typ := idx.exprType(sel.X)
+	if typ == nil {
+		return nil
+	}
+	ts, ok := idx.types[namedType(typ)]
+	if !ok {
+		return nil
+	}
+	st, ok := ts.Type.(*ast.StructType)
+	if !ok {
+		return nil
+	}
+	for _, field := range st.Fields.List {
+		for _, name := range field.Names {
+			if name.Name == sel.Sel.Name {
+				return field.Type
+			}
+		}
+	}
+	return nil
+}
+
+// identType finds the type of the variable from its declaration
+func (idx *packageIndex) identType(ident *ast.Ident) ast.Expr {
+	if ident.Obj == nil {
+		return nil
+	}
+	switch decl := ident.Obj.Decl.(type) {
+	case *ast.Field:
+		return decl.Type
+	case *ast.ValueSpec:
+		if decl.Type != nil {
+			return decl.Type
+		}
+		for i, name := range decl.Names {
+			if name.Name == ident.Name && i < len(decl.Values) {
+				return idx.exprType(decl.Values[i])
+			}
+		}
+	case *ast.AssignStmt:
+		for i, lhs := range decl.Lhs {
+			if name, ok := lhs.(*ast.Ident); !ok || name.Name != ident.Name {
+				continue
+			}
+			if len(decl.Rhs) == len(decl.Lhs) {
+				return idx.exprType(decl.Rhs[i])
+			}
+			if call, ok := decl.Rhs[0].(*ast.CallExpr); ok && len(decl.Rhs) == 1 {
+				return idx.resultType(call, i)
+			}
+		}
+	}
+	return nil
+}
+
+// typeSchema converts the Go type expression to the JSON schema. The types declared in other packages
+// are described only by their name, unless their JSON representation is known.
+func (idx *packageIndex) typeSchema(typ ast.Expr, visiting map[string]bool) map[string]interface{} {
+	switch t := typ.(type) {
+	case *ast.Ident:
+		return idx.identSchema(t.Name, visiting)
+	case *ast.StarExpr:
+		return idx.typeSchema(t.X, visiting)
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{"type": []string{"array", "null"}, "items": idx.typeSchema(t.Elt, visiting)}
+	case *ast.MapType:
+		return map[string]interface{}{"type": []string{"object", "null"}, "additionalProperties": idx.typeSchema(t.Value, visiting)}
+	case *ast.StructType:
+		return idx.structSchema(t, visiting)
+	case *ast.SelectorExpr:
+		name := t.Sel.Name
+		if pkg, ok := t.X.(*ast.Ident); ok {
+			name = pkg.Name + "." + name
+		}
+		if schema, ok := knownExternalTypes[name]; ok {
+			return copySchema(schema)
+		}
+		return map[string]interface{}{"description": name}
+	}
+	return map[string]interface{}{}
+}
+
+func (idx *packageIndex) identSchema(name string, visiting map[string]bool) map[string]interface{} {
+	switch name {
+	case "string":
+		return map[string]interface{}{"type": "string"}
+	case "bool":
+		return map[string]interface{}{"type": "boolean"}
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "byte", "rune":
+		return map[string]interface{}{"type": "integer"}
+	case "float32", "float64":
+		return map[string]interface{}{"type": "number"}
+	}
+
+	ts, ok := idx.types[name]
+	if !ok || visiting[name] || idx.marshalers[name] {
+		// builtin interfaces, recursive types and the types with the custom marshalling can be any JSON value
+		return map[string]interface{}{}
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+	schema := idx.typeSchema(ts.Type, visiting)
+	if _, isStruct := ts.Type.(*ast.StructType); isStruct {
+		schema["title"] = name
+	}
+	return schema
+}
+
+func (idx *packageIndex) structSchema(st *ast.StructType, visiting map[string]bool) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+	for _, field := range st.Fields.List {
+		tagName, omitEmpty, skip := jsonTag(field)
+		if skip {
+			continue
+		}
+		if len(field.Names) == 0 {
+			// the fields of the embedded structs are promoted to the parent object
+			if tagName == "" {
+				embedded := idx.typeSchema(field.Type, visiting)
+				if props, ok := embedded["properties"].(map[string]interface{}); ok {
+					for k, v := range props {
+						properties[k] = v
+					}
+					if req, ok := embedded["required"].([]string); ok {
+						required = append(required, req...)
+					}
+				}
+				continue
+			}
+			properties[tagName] = idx.typeSchema(field.Type, visiting)
+			if !omitEmpty {
+				required = append(required, tagName)
+			}
+			continue
+		}
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			propertyName := name.Name
+			if tagName != "" {
+				propertyName = tagName
+			}
+			properties[propertyName] = idx.typeSchema(field.Type, visiting)
+			if !omitEmpty {
+				required = append(required, propertyName)
+			}
+		}
+	}
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+// jsonTag parses the json struct tag of the field
+func jsonTag(field *ast.Field) (name string, omitEmpty, skip bool) {
+	if field.Tag == nil {
+		return "", false, false
+	}
+	tag := reflect.StructTag(strings.Trim(field.Tag.Value, "`")).Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	for _, option := range parts[1:] {
+		if option == "omitempty" || option == "omitzero" {
+			omitEmpty = true
+		}
+	}
+	return parts[0], omitEmpty, false
+}
+
+// namedType returns the name of the (pointer to) named type
+func namedType(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.StarExpr:
+		return namedType(e.X)
+	}
+	return ""
+}
+
+// schemaKey is the canonical form of the schema used to find the duplicates
+func schemaKey(schema map[string]interface{}) string {
+	data, _ := json.Marshal(schema) // nolint: errchkjson
+	return string(data)
+}
+
+func copySchema(schema map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(schema))
+	for k, v := range schema {
+		result[k] = v
+	}
+	return result
+}
@@ -11,6 +11,9 @@ import (
 	"k8s.io/component-base/logs"
 	"k8s.io/klog/v2"
 
+	"github.com/openshift/insights-operator/pkg/cmd/archive"
+	"github.com/openshift/insights-operator/pkg/cmd/config"
+	"github.com/openshift/insights-operator/pkg/cmd/rules"
 	"github.com/openshift/insights-operator/pkg/cmd/start"
 )
 
@@ -61,6 +64,9 @@ func NewOperatorCommand() *cobra.Command {
 	cmd.AddCommand(start.NewReceiver())
 	cmd.AddCommand(start.NewGather())
 	cmd.AddCommand(start.NewGatherAndUpload())
+	cmd.AddCommand(archive.NewArchive())
+	cmd.AddCommand(rules.NewRules())
+	cmd.AddCommand(config.NewConfig())
 
 	return cmd
 }
@@ -1,114 +1,539 @@
 package main
 
 import (
-	"archive/tar"
-	"compress/gzip"
+	"bytes"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
 
 	configv1 "github.com/openshift/api/config/v1"
 	insightsv1 "github.com/openshift/api/insights/v1"
 	"github.com/openshift/insights-operator/pkg/anonymization"
+	"github.com/openshift/insights-operator/pkg/config"
 	"github.com/openshift/insights-operator/pkg/gather"
 	"github.com/openshift/insights-operator/pkg/record"
 	"github.com/openshift/insights-operator/pkg/recorder"
 	"github.com/openshift/insights-operator/pkg/recorder/diskrecorder"
+	"sigs.k8s.io/yaml"
 )
 
+const (
+	archiveSuffix    = ".tar.gz"
+	obfuscatedSuffix = "-obfuscated" + archiveSuffix
+	// stdio is the path used to read the archive from the standard input and to write it to the standard output
+	stdio = "-"
+
+	networkingPolicy    = "networking"
+	workloadNamesPolicy = "workload_names"
+	rulesPolicy         = "rules"
+)
+
+// options are the obfuscation options applied to all the processed archives
+type options struct {
+	networking    bool
+	workloadNames bool
+	rules         []config.ObfuscationRule
+	verify        bool
+}
+
+func (o *options) policies() []string {
+	var policies []string
+	if o.networking {
+		policies = append(policies, networkingPolicy)
+	}
+	if o.workloadNames {
+		policies = append(policies, workloadNamesPolicy)
+	}
+	if len(o.rules) > 0 {
+		policies = append(policies, rulesPolicy)
+	}
+	return policies
+}
+
+// summary is the JSON summary of the substitutions made in all the processed archives.
+// It contains the original values, so it must be handled as sensitive data.
+type summary struct {
+	Archives []*archiveSummary `json:"archives"`
+}
+
+// archiveSummary is the summary of the substitutions made in a single archive
+type archiveSummary struct {
+	Input           string            `json:"input"`
+	Output          string            `json:"output,omitempty"`
+	Policies        []string          `json:"policies"`
+	Records         int               `json:"records"`
+	ModifiedRecords int               `json:"modifiedRecords"`
+	RenamedRecords  map[string]string `json:"renamedRecords,omitempty"`
+	Substitutions   map[string]string `json:"substitutions,omitempty"`
+	RuleMatches     map[string]int    `json:"ruleMatches,omitempty"`
+	Error           string            `json:"error,omitempty"`
+}
+
+func newArchiveSummary(input string, opts *options) *archiveSummary {
+	return &archiveSummary{
+		Input:          input,
+		Policies:       opts.policies(),
+		RenamedRecords: make(map[string]string),
+		RuleMatches:    make(map[string]int),
+	}
+}
+
 func main() {
-	if len(os.Args) < 2 {
-		_, _ = fmt.Fprintf(os.Stderr, "Path to the archive was not provided\n\n"+
-			"Usage: go run ./cmd/obfuscate-archive/main.go PATH_TO_THE_ARCHIVE\n\n"+
-			"Obfuscates the archive located at PATH_TO_THE_ARCHIVE\n")
-		return
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout))
+}
+
+// run obfuscates all the archives provided in the args and returns the exit code
+func run(args []string, stdin io.Reader, stdout io.Writer) int {
+	flags := flag.NewFlagSet("obfuscate-archive", flag.ContinueOnError)
+	verify := flags.Bool("verify", false,
+		"scan the obfuscated archive for the remaining known sensitive values and fail if any is found")
+	policy := flags.String("policy", networkingPolicy, fmt.Sprintf(
+		"comma separated list of the obfuscation policies to apply (%s, %s, %s)",
+		networkingPolicy, workloadNamesPolicy, rulesPolicy))
+	rulesFile := flags.String("rules", "",
+		"path to the JSON or YAML file with the list of the obfuscation rules used by the rules policy")
+	summaryPath := flags.String("summary", "",
+		`path to the JSON summary of the substitutions, "-" writes it to the standard output`)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage: go run ./cmd/obfuscate-archive/main.go [flags] PATH...\n\n"+
+			"Obfuscates the archives located at PATHs. PATH can be an archive or a directory with the archives.\n"+
+			"When PATH is \"-\", the archive is read from the standard input and written to the standard output.\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	if flags.NArg() < 1 {
+		_, _ = fmt.Fprintf(os.Stderr, "Path to the archive was not provided\n\n")
+		flags.Usage()
+		return 2
+	}
+
+	opts, err := newOptions(*policy, *rulesFile, *verify)
+	if err != nil {
+		printlnToStderrf("Invalid options: %v", err)
+		return 2
 	}
 
-	path := os.Args[1]
+	paths, err := expandPaths(flags.Args())
+	if err != nil {
+		printlnToStderrf("Invalid path: %v", err)
+		return 2
+	}
+	if paths[0] == stdio && *summaryPath == stdio {
+		printlnToStderrf("Invalid options: the summary can't be written to the standard output together with the archive")
+		return 2
+	}
 
-	if newPath, err := obfuscateArchive(path); err != nil {
-		printlnToStderrf("Unable to obfuscate archive: %v", err)
-	} else {
-		fmt.Println("Created", newPath)
+	var s summary
+	failed := 0
+	for _, path := range paths {
+		var archive *archiveSummary
+		if path == stdio {
+			archive, err = obfuscateStream(stdin, stdout, opts)
+		} else {
+			archive, err = obfuscateArchive(path, opts)
+		}
+		if err != nil {
+			printlnToStderrf("Unable to obfuscate archive %s: %v", path, err)
+			archive.Error = err.Error()
+			failed++
+		} else if path != stdio {
+			printlnToStderrf("Created %s", archive.Output)
+		}
+		s.Archives = append(s.Archives, archive)
 	}
+
+	if *summaryPath != "" {
+		if err := writeSummary(*summaryPath, &s, stdout); err != nil {
+			printlnToStderrf("Unable to write the summary: %v", err)
+			return 1
+		}
+	}
+
+	if failed > 0 {
+		return 1
+	}
+	return 0
 }
 
 func printlnToStderrf(format string, params ...interface{}) {
 	_, _ = fmt.Fprintln(os.Stderr, fmt.Sprintf(format, params...))
 }
 
-func obfuscateArchive(path string) (string, error) {
-	const suffix = ".tar.gz"
-	if !strings.HasSuffix(path, suffix) {
-		return "", fmt.Errorf(`invalid path to the archive: should end with "%v"`, suffix)
+// newOptions parses the comma separated list of the policies and reads the obfuscation rules
+func newOptions(policy, rulesFile string, verify bool) (*options, error) {
+	opts := &options{verify: verify}
+	withRules := false
+	for _, p := range strings.Split(policy, ",") {
+		switch strings.TrimSpace(p) {
+		case networkingPolicy:
+			opts.networking = true
+		case workloadNamesPolicy:
+			opts.workloadNames = true
+		case rulesPolicy:
+			withRules = true
+		case "":
+			continue
+		default:
+			return nil, fmt.Errorf("unknown policy %q (valid values: %q, %q, %q)",
+				p, networkingPolicy, workloadNamesPolicy, rulesPolicy)
+		}
 	}
 
-	newPath := strings.TrimSuffix(path, suffix) + "-obfuscated" + suffix
+	switch {
+	case withRules && rulesFile == "":
+		return nil, fmt.Errorf("the %s policy requires the --rules file", rulesPolicy)
+	case !withRules && rulesFile != "":
+		return nil, fmt.Errorf("the --rules file requires the %s policy", rulesPolicy)
+	case withRules:
+		rules, err := readObfuscationRules(rulesFile)
+		if err != nil {
+			return nil, err
+		}
+		opts.rules = rules
+	}
+
+	if len(opts.policies()) == 0 {
+		return nil, fmt.Errorf("no policy selected")
+	}
+	if verify && !opts.networking {
+		return nil, fmt.Errorf("the --verify flag requires the %s policy", networkingPolicy)
+	}
+	return opts, nil
+}
 
-	records, err := readArchive(path)
+// readObfuscationRules reads and validates the list of the obfuscation rules
+// in the same format as in the insights-config configmap
+func readObfuscationRules(path string) ([]config.ObfuscationRule, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
+	var rules []config.ObfuscationRule
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("unable to parse the obfuscation rules from %s: %v", path, err)
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("no obfuscation rules found in %s", path)
+	}
+	if _, err := config.ValidateObfuscationRules(rules); err != nil {
+		return nil, fmt.Errorf("invalid obfuscation rules in %s: %v", path, err)
+	}
+	return rules, nil
+}
+
+// expandPaths replaces the directories with the archives they contain. The already obfuscated archives are skipped.
+func expandPaths(args []string) ([]string, error) {
+	if slices.Contains(args, stdio) && len(args) > 1 {
+		return nil, fmt.Errorf("the standard input can't be combined with other paths")
+	}
+
+	var paths []string
+	for _, arg := range args {
+		if arg == stdio {
+			paths = append(paths, arg)
+			continue
+		}
+
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			paths = append(paths, arg)
+			continue
+		}
+
+		entries, err := os.ReadDir(arg)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || !strings.HasSuffix(name, archiveSuffix) || strings.HasSuffix(name, obfuscatedSuffix) {
+				continue
+			}
+			paths = append(paths, filepath.Join(arg, name))
+			found = true
+		}
+		if !found {
+			return nil, fmt.Errorf("no archives found in the directory %s", arg)
+		}
+	}
+	return paths, nil
+}
+
+// writeSummary writes the JSON summary to the file or to the standard output
+func writeSummary(path string, s *summary, stdout io.Writer) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if path == stdio {
+		_, err = stdout.Write(data)
+		return err
+	}
+	// the summary contains the original values, so it's readable only by the owner
+	return os.WriteFile(path, data, 0o600)
+}
+
+// obfuscateArchive obfuscates the archive and saves the result next to it with the "-obfuscated" suffix
+func obfuscateArchive(path string, opts *options) (*archiveSummary, error) {
+	s := newArchiveSummary(path, opts)
+	if !strings.HasSuffix(path, archiveSuffix) {
+		return s, fmt.Errorf(`invalid path to the archive: should end with "%v"`, archiveSuffix)
+	}
+
+	newPath := strings.TrimSuffix(path, archiveSuffix) + obfuscatedSuffix
+
+	records, err := diskrecorder.ReadArchive(path)
+	if err != nil {
+		return s, err
+	}
+
+	anonymizedRecords, detector, err := obfuscateRecords(records, opts, s)
+	if err != nil {
+		return s, err
+	}
+
+	diskRecorder := diskrecorder.New("")
+
+	_, err = diskRecorder.SaveAtPath(anonymizedRecords, newPath)
+	if err != nil {
+		return s, err
+	}
+	s.Output = newPath
+
+	if opts.verify {
+		if err := verifyArchive(newPath, detector); err != nil {
+			return s, err
+		}
+	}
+
+	return s, nil
+}
+
+// obfuscateStream reads the archive from the reader and writes the obfuscated archive to the writer.
+// The verification is done before writing, so that no archive is written when it fails.
+func obfuscateStream(r io.Reader, w io.Writer, opts *options) (*archiveSummary, error) {
+	s := newArchiveSummary(stdio, opts)
+
+	records, err := diskrecorder.ReadRecords(r)
+	if err != nil {
+		return s, err
+	}
+
+	anonymizedRecords, detector, err := obfuscateRecords(records, opts, s)
+	if err != nil {
+		return s, err
+	}
+
+	if opts.verify {
+		anonymizedByName := make(map[string]*record.MemoryRecord, len(anonymizedRecords))
+		for i := range anonymizedRecords {
+			anonymizedByName[anonymizedRecords[i].Name] = &anonymizedRecords[i]
+		}
+		if err := verifyRecords(stdio, anonymizedByName, detector); err != nil {
+			return s, err
+		}
+	}
+
+	if _, err := diskrecorder.WriteArchive(w, anonymizedRecords); err != nil {
+		return s, err
+	}
+	s.Output = stdio
+
+	return s, nil
+}
+
+// obfuscateRecords applies the selected policies to all the records and updates the summary.
+// The leak detector is returned only when the networking policy is selected.
+func obfuscateRecords(
+	records map[string]*record.MemoryRecord, opts *options, s *archiveSummary,
+) (record.MemoryRecords, *anonymization.LeakDetector, error) {
+	var anonymizer *anonymization.NetworkAnonymizer
+	var clusterBaseDomain string
+	if opts.networking {
+		var err error
+		anonymizer, clusterBaseDomain, err = newAnonymizer(records, opts.rules)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	rulePatterns := make([]*regexp.Regexp, 0, len(opts.rules))
+	for i := range opts.rules {
+		re, err := opts.rules[i].Compile()
+		if err != nil {
+			return nil, nil, err
+		}
+		rulePatterns = append(rulePatterns, re)
+	}
+
+	// records are processed in the sorted order, so the obfuscated values don't depend on the map ordering
+	names := make([]string, 0, len(records))
+	for name := range records {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	s.Records = len(names)
+
+	anonymizedRecords := make(record.MemoryRecords, 0, len(names))
+	for _, name := range names {
+		r := records[name]
+		if opts.networking && r.Name == recorder.MetadataRecordName+".json" {
+			if err := markGlobalObfuscationEnabled(r); err != nil {
+				return nil, nil, err
+			}
+		}
+		originalName, originalData := r.Name, r.Data
+
+		if opts.workloadNames && r.Name == anonymization.DVOMetricsRecordName {
+			r.Data = anonymization.RemoveWorkloadNames(r.Data)
+		}
+
+		for i, re := range rulePatterns {
+			placeholder := opts.rules[i].Placeholder
+			if matches := len(re.FindAllIndex(r.Data, -1)) + len(re.FindAllStringIndex(r.Name, -1)); matches > 0 {
+				s.RuleMatches[placeholder] += matches
+			}
+			// with the networking policy the rules are applied by the anonymizer
+			if anonymizer == nil {
+				r.Data = re.ReplaceAll(r.Data, []byte(placeholder))
+				r.Name = re.ReplaceAllString(r.Name, placeholder)
+			}
+		}
+
+		if anonymizer != nil {
+			var err error
+			r, err = anonymizer.AnonymizeData(r)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		if originalName != r.Name {
+			s.RenamedRecords[originalName] = r.Name
+		}
+		if originalName != r.Name || !bytes.Equal(originalData, r.Data) {
+			s.ModifiedRecords++
+		}
+		anonymizedRecords = append(anonymizedRecords, *r)
+	}
+
+	if anonymizer == nil {
+		return anonymizedRecords, nil, nil
+	}
+
+	s.Substitutions = anonymizer.TranslationTable()
+	s.Substitutions[clusterBaseDomain] = anonymization.ClusterBaseDomainPlaceholder
+	return anonymizedRecords, anonymizer.LeakDetector(), nil
+}
+
+// newAnonymizer creates the networking anonymizer from the cluster base domain, networks and hostnames
+// found in the records. It also returns the cluster base domain.
+func newAnonymizer(
+	records map[string]*record.MemoryRecord, rules []config.ObfuscationRule,
+) (*anonymization.NetworkAnonymizer, string, error) {
 	clusterBaseDomain, err := getClusterBaseDomain(records)
 	if err != nil {
-		return "", err
+		return nil, "", err
 	}
 
 	networks, err := anonymization.GetNetworksForAnonymizerFromRecords(records)
 	if err != nil {
-		return "", err
+		return nil, "", err
+	}
+
+	hostnames, providerIDs, err := anonymization.GetHostnamesForAnonymizerFromRecords(records)
+	if err != nil {
+		return nil, "", err
 	}
 
 	anonBuilder := &anonymization.NetworkAnonymizerBuilder{}
 	anonBuilder.
 		WithSensitiveValue(clusterBaseDomain, anonymization.ClusterBaseDomainPlaceholder).
 		WithDataPolicies(insightsv1.DataPolicyOptionObfuscateNetworking).
-		WithNetworks(networks)
+		WithNetworks(networks).
+		WithHostnames(hostnames...).
+		WithProviderIDs(providerIDs...).
+		WithObfuscationRules(rules...)
 	anonymizer, err := anonBuilder.Build()
 	if err != nil {
-		return "", err
+		return nil, "", err
 	}
+	return anonymizer, clusterBaseDomain, nil
+}
 
-	var anonymizedRecords record.MemoryRecords
-
-	for _, r := range records {
-		if r.Name == recorder.MetadataRecordName+".json" {
-			var metadata gather.ArchiveMetadata
+// markGlobalObfuscationEnabled sets the global obfuscation flag in the archive metadata record
+func markGlobalObfuscationEnabled(r *record.MemoryRecord) error {
+	var metadata gather.ArchiveMetadata
 
-			err = json.Unmarshal(r.Data, &metadata)
-			if err != nil {
-				return "", err
-			}
+	err := json.Unmarshal(r.Data, &metadata)
+	if err != nil {
+		return err
+	}
 
-			metadata.IsGlobalObfuscationEnabled = true
+	metadata.IsGlobalObfuscationEnabled = true
 
-			metadataBytes, err := json.Marshal(metadata) //nolint:govet
-			if err != nil {
-				return "", err
-			}
+	metadataBytes, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
 
-			r.Data = metadataBytes
-		}
+	r.Data = metadataBytes
+	return nil
+}
 
-		anonymizedData, err := anonymizer.AnonymizeData(r)
-		if err != nil {
-			return "", err
-		}
+// verifyArchive reads the obfuscated archive back and scans it for the remaining known sensitive values.
+// Every found value is printed with the file and the offset and an error is returned.
+func verifyArchive(path string, detector *anonymization.LeakDetector) error {
+	records, err := diskrecorder.ReadArchive(path)
+	if err != nil {
+		return err
+	}
+	return verifyRecords(path, records, detector)
+}
 
-		anonymizedRecords = append(anonymizedRecords, *anonymizedData)
+// verifyRecords scans the records for the remaining known sensitive values
+func verifyRecords(source string, records map[string]*record.MemoryRecord, detector *anonymization.LeakDetector) error {
+	names := make([]string, 0, len(records))
+	for name := range records {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	diskRecorder := diskrecorder.New("")
+	var leaks []anonymization.Leak
+	for _, name := range names {
+		leaks = append(leaks, detector.Scan(name, records[name].Data)...)
+	}
 
-	_, err = diskRecorder.SaveAtPath(anonymizedRecords, newPath)
-	if err != nil {
-		return "", err
+	if len(leaks) == 0 {
+		printlnToStderrf("Verified %s - no known sensitive values found", source)
+		return nil
 	}
 
-	return newPath, nil
+	for _, leak := range leaks {
+		printlnToStderrf("%s", leak)
+	}
+	return fmt.Errorf("verification failed: %d known sensitive values found in %s", len(leaks), source)
 }
 
 func getClusterBaseDomain(records map[string]*record.MemoryRecord) (string, error) {
@@ -171,45 +596,3 @@ func getClusterBaseDomainFromIngressRecord(records map[string]*record.MemoryReco
 
 	return domain, nil
 }
-
-func readArchive(path string) (map[string]*record.MemoryRecord, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, err
-	}
-
-	defer file.Close()
-
-	gzipReader, err := gzip.NewReader(file)
-	if err != nil {
-		return nil, err
-	}
-
-	defer gzipReader.Close()
-
-	tarReader := tar.NewReader(gzipReader)
-
-	records := make(map[string]*record.MemoryRecord)
-
-	for {
-		header, err := tarReader.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
-
-		content, err := io.ReadAll(tarReader)
-		if err != nil {
-			return nil, err
-		}
-
-		records[header.Name] = &record.MemoryRecord{
-			Name: header.Name,
-			Data: content,
-		}
-	}
-
-	return records, nil
-}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
 	"encoding/json"
 	"os"
@@ -11,7 +12,10 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/insights-operator/pkg/anonymization"
+	"github.com/openshift/insights-operator/pkg/config"
 	"github.com/openshift/insights-operator/pkg/record"
+	"github.com/openshift/insights-operator/pkg/recorder/diskrecorder"
 )
 
 func Test_getClusterBaseDomainFromInfrastructureRecord(t *testing.T) {
@@ -298,7 +302,7 @@ func Test_readArchive(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Test readArchive function
-	records, err := readArchive(archivePath)
+	records, err := diskrecorder.ReadArchive(archivePath)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 3, len(records))
@@ -351,7 +355,7 @@ func Test_readArchive_InvalidFiles(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			filePath := tt.setupFile(t)
 
-			records, err := readArchive(filePath)
+			records, err := diskrecorder.ReadArchive(filePath)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -401,11 +405,11 @@ func createTestArchive(path string, files map[string]string) error {
 }
 
 func Test_obfuscateArchive_InvalidPath(t *testing.T) {
-	newPath, err := obfuscateArchive("archive.tar")
+	s, err := obfuscateArchive("archive.tar", &options{networking: true})
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid path to the archive: should end with")
-	assert.Empty(t, newPath)
+	assert.Empty(t, s.Output)
 }
 
 func Test_obfuscateArchive_MissingRecords(t *testing.T) {
@@ -421,9 +425,256 @@ func Test_obfuscateArchive_MissingRecords(t *testing.T) {
 		err := createTestArchive(archivePath, files)
 		assert.NoError(t, err)
 
-		newPath, err := obfuscateArchive(archivePath)
+		s, err := obfuscateArchive(archivePath, &options{networking: true})
 		assert.Error(t, err)
-		assert.Empty(t, newPath)
+		assert.Empty(t, s.Output)
 		assert.Contains(t, err.Error(), "record needed to fetch cluster base domain wasn't found")
 	})
 }
+
+func Test_obfuscateArchive_Verify(t *testing.T) {
+	tmpDir := t.TempDir()
+	archivePath := filepath.Join(tmpDir, "archive.tar.gz")
+
+	files := map[string]string{
+		"config/infrastructure.json": `{"status":{"etcdDiscoveryDomain":"test.example.com"}}`,
+		"config/network.json":        `{"spec":{"clusterNetwork":[{"cidr":"10.128.0.0/14"}],"serviceNetwork":["172.30.0.0/16"]}}`,
+		"config/node.json":           `{"name":"master-0.test.example.com","ip":"10.128.0.55","svc":"172.30.0.10"}`,
+	}
+	err := createTestArchive(archivePath, files)
+	assert.NoError(t, err)
+
+	s, err := obfuscateArchive(archivePath, &options{networking: true, verify: true})
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(tmpDir, "archive-obfuscated.tar.gz"), s.Output)
+
+	records, err := diskrecorder.ReadArchive(s.Output)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"master-0.<CLUSTER_BASE_DOMAIN>","ip":"10.128.0.1","svc":"172.30.0.1"}`,
+		string(records["config/node.json"].Data))
+}
+
+func Test_verifyArchive(t *testing.T) {
+	anonymizer, err := (&anonymization.NetworkAnonymizerBuilder{}).
+		WithSensitiveValue("test.example.com", anonymization.ClusterBaseDomainPlaceholder).
+		WithNetworks([]string{"10.128.0.0/14"}).
+		Build()
+	assert.NoError(t, err)
+
+	archivePath := filepath.Join(t.TempDir(), "leaking.tar.gz")
+	err = createTestArchive(archivePath, map[string]string{
+		"config/node.json": `{"name":"master-0.test.example.com","ip":"10.128.0.55"}`,
+	})
+	assert.NoError(t, err)
+
+	err = verifyArchive(archivePath, anonymizer.LeakDetector())
+	assert.EqualError(t, err, "verification failed: 2 known sensitive values found in "+archivePath)
+}
+
+func Test_newOptions(t *testing.T) {
+	rulesPath := filepath.Join(t.TempDir(), "rules.yaml")
+	err := os.WriteFile(rulesPath, []byte("- type: regex\n  value: ACC-[0-9]{6}\n  placeholder: <ACCOUNT_ID>\n"), 0600)
+	assert.NoError(t, err)
+	invalidRulesPath := filepath.Join(t.TempDir(), "invalid.json")
+	err = os.WriteFile(invalidRulesPath, []byte(`[{"type": "regex", "value": "(", "placeholder": "<X>"}]`), 0600)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		policy          string
+		rulesFile       string
+		verify          bool
+		expectedOptions *options
+		errorContains   string
+	}{
+		{
+			name:            "default networking policy",
+			policy:          "networking",
+			verify:          true,
+			expectedOptions: &options{networking: true, verify: true},
+		},
+		{
+			name:      "all the policies",
+			policy:    "networking, workload_names,rules",
+			rulesFile: rulesPath,
+			expectedOptions: &options{
+				networking:    true,
+				workloadNames: true,
+				rules: []config.ObfuscationRule{
+					{Type: config.RegexRule, Value: "ACC-[0-9]{6}", Placeholder: "<ACCOUNT_ID>"},
+				},
+			},
+		},
+		{
+			name:          "unknown policy",
+			policy:        "networking,names",
+			errorContains: `unknown policy "names"`,
+		},
+		{
+			name:          "no policy",
+			policy:        "",
+			errorContains: "no policy selected",
+		},
+		{
+			name:          "rules policy without the rules file",
+			policy:        "rules",
+			errorContains: "the rules policy requires the --rules file",
+		},
+		{
+			name:          "rules file without the rules policy",
+			policy:        "networking",
+			rulesFile:     rulesPath,
+			errorContains: "the --rules file requires the rules policy",
+		},
+		{
+			name:          "invalid rules",
+			policy:        "rules",
+			rulesFile:     invalidRulesPath,
+			errorContains: "invalid obfuscation rules",
+		},
+		{
+			name:          "verification without the networking policy",
+			policy:        "workload_names",
+			verify:        true,
+			errorContains: "the --verify flag requires the networking policy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := newOptions(tt.policy, tt.rulesFile, tt.verify)
+			if tt.errorContains != "" {
+				assert.ErrorContains(t, err, tt.errorContains)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedOptions, opts)
+		})
+	}
+}
+
+func Test_expandPaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"b.tar.gz", "a.tar.gz", "a-obfuscated.tar.gz", "notes.txt"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), nil, 0600))
+	}
+	emptyDir := t.TempDir()
+
+	paths, err := expandPaths([]string{tmpDir, filepath.Join(tmpDir, "notes.txt")})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(tmpDir, "a.tar.gz"), filepath.Join(tmpDir, "b.tar.gz"), filepath.Join(tmpDir, "notes.txt"),
+	}, paths)
+
+	paths, err = expandPaths([]string{"-"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"-"}, paths)
+
+	_, err = expandPaths([]string{"-", tmpDir})
+	assert.ErrorContains(t, err, "the standard input can't be combined with other paths")
+
+	_, err = expandPaths([]string{emptyDir})
+	assert.ErrorContains(t, err, "no archives found in the directory")
+}
+
+func Test_obfuscateRecords_WithoutNetworking(t *testing.T) {
+	records := map[string]*record.MemoryRecord{
+		"config/dvo_metrics": {
+			Name: "config/dvo_metrics",
+			Data: []byte(`deployment_validation_operator_run_as_non_root{kind="Deployment",name="frontend",uid="1234"} 1` + "\n"),
+		},
+		"config/ACC-123456.json": {
+			Name: "config/ACC-123456.json",
+			Data: []byte(`{"account": "ACC-123456", "ip": "10.0.0.1"}`),
+		},
+		"config/other.json": {
+			Name: "config/other.json",
+			Data: []byte(`{}`),
+		},
+	}
+	opts := &options{
+		workloadNames: true,
+		rules: []config.ObfuscationRule{
+			{Type: config.RegexRule, Value: "ACC-[0-9]{6}", Placeholder: "<ACCOUNT_ID>"},
+		},
+	}
+	s := newArchiveSummary("archive.tar.gz", opts)
+
+	anonymizedRecords, detector, err := obfuscateRecords(records, opts, s)
+	assert.NoError(t, err)
+	assert.Nil(t, detector)
+	assert.Equal(t, record.MemoryRecords{
+		{Name: "config/<ACCOUNT_ID>.json", Data: []byte(`{"account": "<ACCOUNT_ID>", "ip": "10.0.0.1"}`)},
+		{
+			Name: "config/dvo_metrics",
+			Data: []byte(`deployment_validation_operator_run_as_non_root{kind="Deployment",uid="1234"} 1` + "\n"),
+		},
+		{Name: "config/other.json", Data: []byte(`{}`)},
+	}, anonymizedRecords)
+	assert.Equal(t, &archiveSummary{
+		Input:           "archive.tar.gz",
+		Policies:        []string{"workload_names", "rules"},
+		Records:         3,
+		ModifiedRecords: 2,
+		RenamedRecords:  map[string]string{"config/ACC-123456.json": "config/<ACCOUNT_ID>.json"},
+		RuleMatches:     map[string]int{"<ACCOUNT_ID>": 2},
+	}, s)
+}
+
+func Test_run_Directory(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"config/infrastructure.json": `{"status":{"etcdDiscoveryDomain":"test.example.com"}}`,
+		"config/network.json":        `{"spec":{"clusterNetwork":[{"cidr":"10.128.0.0/14"}]}}`,
+		"config/node/master-0.json":  `{"metadata":{"name":"master-0"},"status":{"addresses":[{"address":"10.128.0.55"}]}}`,
+	}
+	assert.NoError(t, createTestArchive(filepath.Join(tmpDir, "first.tar.gz"), files))
+	assert.NoError(t, createTestArchive(filepath.Join(tmpDir, "second.tar.gz"), map[string]string{
+		"config/other.json": `{}`,
+	}))
+	summaryPath := filepath.Join(t.TempDir(), "summary.json")
+
+	exitCode := run([]string{"--summary", summaryPath, tmpDir}, nil, nil)
+	assert.Equal(t, 1, exitCode)
+
+	data, err := os.ReadFile(summaryPath)
+	assert.NoError(t, err)
+	var s summary
+	assert.NoError(t, json.Unmarshal(data, &s))
+	assert.Len(t, s.Archives, 2)
+
+	first := s.Archives[0]
+	assert.Equal(t, filepath.Join(tmpDir, "first-obfuscated.tar.gz"), first.Output)
+	assert.Equal(t, 3, first.Records)
+	assert.Equal(t, 2, first.ModifiedRecords)
+	assert.Equal(t, map[string]string{"config/node/master-0.json": "config/node/obfuscated-host-1.json"}, first.RenamedRecords)
+	assert.Equal(t, "<CLUSTER_BASE_DOMAIN>", first.Substitutions["test.example.com"])
+	assert.Equal(t, "10.128.0.1", first.Substitutions["10.128.0.55"])
+	assert.Equal(t, "obfuscated-host-1", first.Substitutions["master-0"])
+	assert.Empty(t, first.Error)
+
+	second := s.Archives[1]
+	assert.Empty(t, second.Output)
+	assert.Contains(t, second.Error, "record needed to fetch cluster base domain wasn't found")
+}
+
+func Test_run_Stdio(t *testing.T) {
+	var input bytes.Buffer
+	_, err := diskrecorder.WriteArchive(&input, record.MemoryRecords{
+		{Name: "config/infrastructure.json", Data: []byte(`{"status":{"etcdDiscoveryDomain":"test.example.com"}}`)},
+		{Name: "config/ingress.json", Data: []byte(`{"spec":{"domain":"apps.test.example.com"}}`)},
+		{Name: "config/network.json", Data: []byte(`{"spec":{"clusterNetwork":[{"cidr":"10.128.0.0/14"}]}}`)},
+	})
+	assert.NoError(t, err)
+
+	var output bytes.Buffer
+	exitCode := run([]string{"--verify", "-"}, &input, &output)
+	assert.Equal(t, 0, exitCode)
+
+	records, err := diskrecorder.ReadRecords(&output)
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+	assert.Equal(t, `{"spec":{"domain":"apps.<CLUSTER_BASE_DOMAIN>"}}`, string(records["config/ingress.json"].Data))
+
+	assert.Equal(t, 2, run([]string{"--summary", "-", "-"}, &input, &output))
+}
@@ -0,0 +1,11 @@
+// Package docs embeds the generated machine-readable documentation of the gathered data, so that
+// the archive validation checks the archives against the same catalog as the one published in this directory.
+package docs
+
+import "embed"
+
+// GatheredData contains the catalog of the gathering functions (gathered-data.json) and the JSON schemas
+// of their records (schemas/{gatherer}/{function}.schema.json) generated by cmd/gendoc
+//
+//go:embed gathered-data.json schemas
+var GatheredData embed.FS
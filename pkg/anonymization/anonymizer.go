@@ -19,6 +19,11 @@
 //   - 172.30.0.5 -> 172.30.0.1  // new subnet, so we use a new set of fake IPs
 //   - 127.0.0.1 -> 127.0.0.1  // it was the first IP, so the new IP matched the original in this case
 //   - 10.0.134.130 -> 0.0.0.0  // ip doesn't match any subnet, we replace such IPs with 0.0.0.0
+//   - node, machine and machine set names and provider IDs. They are replaced by stable pseudonyms
+//     in all the records, including the record names:
+//   - master-0.ec2.internal -> obfuscated-host-1
+//   - master-0 -> obfuscated-host-1  // short name gets the same pseudonym as its FQDN
+//   - aws:///us-east-1a/i-0abc -> obfuscated-provider-id-1
 package anonymization
 
 import (
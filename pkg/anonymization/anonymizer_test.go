@@ -188,6 +188,69 @@ func Test_Anonymizer(t *testing.T) {
 	}
 }
 
+func Test_Anonymizer_ObfuscationRules(t *testing.T) {
+	mockConfigMapConfigurator := config.NewMockConfigMapConfigurator(&config.InsightsConfiguration{
+		DataReporting: config.DataReporting{
+			Obfuscation: config.Obfuscation{
+				config.Networking,
+			},
+			ObfuscationRules: []config.ObfuscationRule{
+				{Type: config.LiteralRule, Value: "db01.internal.example.com", Placeholder: "<DB_HOST>"},
+				{Type: config.RegexRule, Value: `ACC-[0-9]{6}`, Placeholder: "<ACCOUNT_ID>"},
+			},
+		},
+	})
+	anonymizer, err := (&NetworkAnonymizerBuilder{}).
+		WithSensitiveValue("example.com", ClusterBaseDomainPlaceholder).
+		WithConfigurator(mockConfigMapConfigurator).
+		WithObfuscationRules(config.ObfuscationRule{Type: config.RegexRule, Value: `PRJ-[0-9]+`, Placeholder: "<PROJECT_CODE>"}).
+		WithNetworks([]string{"127.0.0.0/8"}).
+		Build()
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		before       string
+		after        string
+		beforeRecord string
+		afterRecord  string
+	}{
+		{
+			name:         "builder rules are applied together with the configured rules",
+			before:       "project PRJ-42 of ACC-123456",
+			after:        "project <PROJECT_CODE> of <ACCOUNT_ID>",
+			beforeRecord: "config/PRJ-42.json",
+			afterRecord:  "config/<PROJECT_CODE>.json",
+		},
+		{
+			name:         "literal rule takes precedence over the cluster base domain",
+			before:       "host: db01.internal.example.com",
+			after:        "host: <DB_HOST>",
+			beforeRecord: "config/db01.internal.example.com.json",
+			afterRecord:  "config/<DB_HOST>.json",
+		},
+		{
+			name:         "regex rule replaces all the matches",
+			before:       `{"accounts": ["ACC-123456", "ACC-654321"], "api": "api.example.com"}`,
+			after:        `{"accounts": ["<ACCOUNT_ID>", "<ACCOUNT_ID>"], "api": "api.<CLUSTER_BASE_DOMAIN>"}`,
+			beforeRecord: "config/ACC-123456.json",
+			afterRecord:  "config/<ACCOUNT_ID>.json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obfuscated, err := anonymizer.AnonymizeData(&record.MemoryRecord{
+				Name: tt.beforeRecord,
+				Data: []byte(tt.before),
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.after, string(obfuscated.Data))
+			assert.Equal(t, tt.afterRecord, obfuscated.Name)
+		})
+	}
+}
+
 func Test_Anonymizer_TranslationTableTest(t *testing.T) {
 	anonymizer := getAnonymizer(t)
 
@@ -457,6 +520,7 @@ func TestNewAnonymizerFromConfigClient(t *testing.T) {
 				kubeClient,
 				configClient,
 				networkClient,
+				nil,
 				mockConfigMapConfigurator,
 				[]insightsv1.DataPolicyOption{insightsv1.DataPolicyOptionObfuscateNetworking},
 				make(map[string]string),
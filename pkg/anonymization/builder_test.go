@@ -117,3 +117,40 @@ func Test_NetworkAnonymizationBuilder(t *testing.T) {
 func getBuilderInstance() *NetworkAnonymizerBuilder {
 	return &NetworkAnonymizerBuilder{}
 }
+
+func Test_NetworkAnonymizationBuilder_WithObfuscationRules(t *testing.T) {
+	tests := []struct {
+		name          string
+		rules         []config.ObfuscationRule
+		expectedRules int
+		expectedError bool
+	}{
+		{
+			name: "literal and regex rules are compiled",
+			rules: []config.ObfuscationRule{
+				{Type: config.LiteralRule, Value: "internal.corp", Placeholder: "<INTERNAL_DOMAIN>"},
+				{Type: config.RegexRule, Value: `PRJ-[0-9]+`, Placeholder: "<PROJECT_CODE>"},
+			},
+			expectedRules: 2,
+		},
+		{
+			name: "invalid regex rule fails the build",
+			rules: []config.ObfuscationRule{
+				{Type: config.RegexRule, Value: `PRJ-[0-9+`, Placeholder: "<PROJECT_CODE>"},
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anonymizer, err := getBuilderInstance().WithObfuscationRules(tt.rules...).Build()
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, anonymizer.rulePatterns, tt.expectedRules)
+		})
+	}
+}
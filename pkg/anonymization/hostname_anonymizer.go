@@ -0,0 +1,340 @@
+package anonymization
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openshift/insights-operator/pkg/record"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// HostnamePseudonymFormat is the format of the pseudonyms replacing the node and machine names
+	HostnamePseudonymFormat = "obfuscated-host-%d"
+	// ProviderIDPseudonymFormat is the format of the pseudonyms replacing the node and machine provider IDs
+	ProviderIDPseudonymFormat = "obfuscated-provider-id-%d"
+	nodeRecordPrefix          = "config/node/"
+	machineRecordPrefix       = "config/machines/"
+	machineSetRecordPrefix    = "machinesets/"
+	// hostnameTableSecretKey is the key of the hostnames translation table in the translation table secret
+	hostnameTableSecretKey = "hostnames.json"
+)
+
+var (
+	machinesGVR    = schema.GroupVersionResource{Group: "machine.openshift.io", Version: "v1beta1", Resource: "machines"}
+	machineSetsGVR = schema.GroupVersionResource{Group: "machine.openshift.io", Version: "v1beta1", Resource: "machinesets"}
+)
+
+// hostnames holds the node, machine and machine set names and the provider IDs which are replaced
+// by stable pseudonyms in all the records
+type hostnames struct {
+	names       []string
+	providerIDs []string
+}
+
+func (h *hostnames) add(name string) {
+	if name = strings.TrimSpace(name); name != "" {
+		h.names = append(h.names, name)
+	}
+}
+
+// addProviderID adds the provider ID unless it's empty or already anonymized by the gatherer
+func (h *hostnames) addProviderID(providerID string) {
+	if providerID = strings.TrimSpace(providerID); strings.Trim(providerID, "x") != "" {
+		h.providerIDs = append(h.providerIDs, providerID)
+	}
+}
+
+// hostnameTable maps the hostnames and the provider IDs to their pseudonyms. It's stored as one JSON value
+// in the translation table secret, because the provider IDs (e.g. "aws:///us-east-1a/i-0abc") are not valid secret keys.
+type hostnameTable struct {
+	Pseudonyms map[string]string `json:"pseudonyms"`
+	// LastHostNumber and LastProviderIDNumber are the highest pseudonym numbers ever used. They are kept when
+	// the hostnames are removed from the table, so that a pseudonym never refers to two different machines.
+	LastHostNumber       int `json:"lastHostNumber"`
+	LastProviderIDNumber int `json:"lastProviderIDNumber"`
+}
+
+// translationTable maps all the hostnames and the provider IDs to their pseudonyms. The hostnames and the provider IDs
+// from the previous translation table keep their pseudonyms, so that the same machine has the same pseudonym
+// in the consecutive archives even when the nodes are added or removed. The ones which no longer exist are dropped,
+// so that the table doesn't grow on the autoscaled clusters. The new ones get the next unused numbers
+// in the sorted order. The short hostname gets the same pseudonym as its FQDN.
+func (h *hostnames) translationTable(previous *hostnameTable) *hostnameTable {
+	table := &hostnameTable{Pseudonyms: make(map[string]string)}
+	if previous == nil {
+		previous = &hostnameTable{}
+	}
+	table.LastHostNumber = previous.LastHostNumber
+	table.LastProviderIDNumber = previous.LastProviderIDNumber
+
+	current := make(map[string]bool)
+	for _, name := range h.names {
+		current[name] = true
+		if shortName, _, isFQDN := strings.Cut(name, "."); isFQDN {
+			current[shortName] = true
+		}
+	}
+	for _, providerID := range h.providerIDs {
+		current[providerID] = true
+	}
+	for original, pseudonym := range previous.Pseudonyms {
+		hostNumber := pseudonymNumber(pseudonym, HostnamePseudonymFormat)
+		providerIDNumber := pseudonymNumber(pseudonym, ProviderIDPseudonymFormat)
+		table.LastHostNumber = max(table.LastHostNumber, hostNumber)
+		table.LastProviderIDNumber = max(table.LastProviderIDNumber, providerIDNumber)
+		if current[original] && (hostNumber > 0 || providerIDNumber > 0) {
+			table.Pseudonyms[original] = pseudonym
+		}
+	}
+
+	// FQDNs are processed first, so that their short names get the same pseudonym
+	var fqdns, shortNames []string
+	for _, name := range h.names {
+		if strings.Contains(name, ".") {
+			fqdns = append(fqdns, name)
+		} else {
+			shortNames = append(shortNames, name)
+		}
+	}
+	sort.Strings(fqdns)
+	sort.Strings(shortNames)
+	table.LastHostNumber = addPseudonyms(
+		table.Pseudonyms, append(fqdns, shortNames...), HostnamePseudonymFormat, table.LastHostNumber, true,
+	)
+
+	providerIDs := append([]string(nil), h.providerIDs...)
+	sort.Strings(providerIDs)
+	table.LastProviderIDNumber = addPseudonyms(
+		table.Pseudonyms, providerIDs, ProviderIDPseudonymFormat, table.LastProviderIDNumber, false,
+	)
+	return table
+}
+
+// addPseudonyms adds the values, which are not in the table yet, with the numbered pseudonyms to the table.
+// The numbering continues after the last used number, the new last used number is returned.
+// When withShortNames is true, the first label of the FQDN value is mapped to the same pseudonym.
+func addPseudonyms(table map[string]string, values []string, format string, lastNumber int, withShortNames bool) int {
+	for _, value := range values {
+		if _, exists := table[value]; exists {
+			continue
+		}
+		lastNumber++
+		pseudonym := fmt.Sprintf(format, lastNumber)
+		table[value] = pseudonym
+		if !withShortNames {
+			continue
+		}
+		if shortName, _, isFQDN := strings.Cut(value, "."); isFQDN && shortName != "" && shortName != "localhost" {
+			if _, exists := table[shortName]; !exists {
+				table[shortName] = pseudonym
+			}
+		}
+	}
+	return lastNumber
+}
+
+// pseudonymNumber returns the number of the pseudonym in the format or 0 when the pseudonym is not in the format
+func pseudonymNumber(pseudonym, format string) int {
+	number, found := strings.CutPrefix(pseudonym, strings.TrimSuffix(format, "%d"))
+	if !found {
+		return 0
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// hostnameReplacer replaces all the hostnames from the translation table with their pseudonyms
+type hostnameReplacer struct {
+	table map[string]string
+	regex *regexp.Regexp
+}
+
+func newHostnameReplacer(table map[string]string) *hostnameReplacer {
+	if len(table) == 0 {
+		return &hostnameReplacer{table: table}
+	}
+	values := make([]string, 0, len(table))
+	for value := range table {
+		values = append(values, value)
+	}
+	// the longest values go first, so that the FQDN is preferred over the short hostname
+	sort.Slice(values, func(i, j int) bool {
+		if len(values[i]) != len(values[j]) {
+			return len(values[i]) > len(values[j])
+		}
+		return values[i] < values[j]
+	})
+	for i := range values {
+		values[i] = regexp.QuoteMeta(values[i])
+	}
+	return &hostnameReplacer{
+		table: table,
+		regex: regexp.MustCompile(strings.Join(values, "|")),
+	}
+}
+
+// findAll returns the locations of all the hostnames which are not a part of a longer word
+func (r *hostnameReplacer) findAll(data []byte) [][]int {
+	if r.regex == nil {
+		return nil
+	}
+	var locations [][]int
+	for _, loc := range r.regex.FindAllIndex(data, -1) {
+		if isHostnameBoundary(data, loc[0]-1) && isHostnameBoundary(data, loc[1]) {
+			locations = append(locations, loc)
+		}
+	}
+	return locations
+}
+
+// replace replaces all the hostnames in the data with their pseudonyms
+func (r *hostnameReplacer) replace(data []byte) []byte {
+	locations := r.findAll(data)
+	if len(locations) == 0 {
+		return data
+	}
+	result := make([]byte, 0, len(data))
+	last := 0
+	for _, loc := range locations {
+		result = append(result, data[last:loc[0]]...)
+		result = append(result, r.table[string(data[loc[0]:loc[1]])]...)
+		last = loc[1]
+	}
+	return append(result, data[last:]...)
+}
+
+// isHostnameBoundary checks whether the character at the index can't be a part of the hostname.
+// The dash is considered a boundary, so the hostnames are replaced also in the derived names (e.g. "etcd-master-0").
+func isHostnameBoundary(data []byte, i int) bool {
+	if i < 0 || i >= len(data) {
+		return true
+	}
+	c := data[i]
+	return (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9')
+}
+
+// readStoredHostnames reads the hostnames translation table stored by the previous gathering. The hostnames
+// and the provider IDs keep their pseudonyms from it. The tables stored by the older versions, where the hostnames
+// were the keys of the secret next to the IP addresses, are still read, the IP addresses are ignored by the caller.
+func (na *NetworkAnonymizer) readStoredHostnames(ctx context.Context) *hostnameTable {
+	if na.secretsClient == nil {
+		return nil
+	}
+	secret, err := na.secretsClient.Get(ctx, TranslationTableSecretName, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			klog.Warningf("unable to read the %s secret, the hostnames will get new pseudonyms: %v", TranslationTableSecretName, err)
+		}
+		return nil
+	}
+	data := make(map[string]string, len(secret.Data)+len(secret.StringData))
+	for key, value := range secret.Data {
+		data[key] = string(value)
+	}
+	for key, value := range secret.StringData {
+		data[key] = value
+	}
+
+	if stored, ok := data[hostnameTableSecretKey]; ok {
+		table := &hostnameTable{}
+		if err := json.Unmarshal([]byte(stored), table); err != nil {
+			klog.Warningf("unable to decode the stored hostnames, they will get new pseudonyms: %v", err)
+			return nil
+		}
+		return table
+	}
+	return &hostnameTable{Pseudonyms: data}
+}
+
+// readHostnames reads the node, machine and machine set names and the provider IDs from the cluster
+func (na *NetworkAnonymizer) readHostnames(ctx context.Context) (*hostnames, error) {
+	names := &hostnames{}
+	nodes, err := na.gatherKubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range nodes.Items {
+		names.add(nodes.Items[i].Name)
+		names.addProviderID(nodes.Items[i].Spec.ProviderID)
+	}
+
+	if na.dynamicClient == nil {
+		return names, nil
+	}
+
+	machines, err := na.dynamicClient.Resource(machinesGVR).List(ctx, metav1.ListOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		klog.Warningf("unable to list machines, their names won't be obfuscated: %v", err)
+	}
+	if machines != nil {
+		for i := range machines.Items {
+			addMachineNames(names, &machines.Items[i])
+		}
+	}
+
+	machineSets, err := na.dynamicClient.Resource(machineSetsGVR).List(ctx, metav1.ListOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		klog.Warningf("unable to list machine sets, their names won't be obfuscated: %v", err)
+	}
+	if machineSets != nil {
+		for i := range machineSets.Items {
+			names.add(machineSets.Items[i].GetName())
+		}
+	}
+
+	return names, nil
+}
+
+func addMachineNames(names *hostnames, machine *unstructured.Unstructured) {
+	names.add(machine.GetName())
+	if providerID, found, _ := unstructured.NestedString(machine.Object, "spec", "providerID"); found {
+		names.addProviderID(providerID)
+	}
+	if nodeName, found, _ := unstructured.NestedString(machine.Object, "status", "nodeRef", "name"); found {
+		names.add(nodeName)
+	}
+}
+
+// GetHostnamesForAnonymizerFromRecords reads the node, machine and machine set names and the provider IDs
+// from the records. It returns the hostnames and the provider IDs.
+func GetHostnamesForAnonymizerFromRecords(records map[string]*record.MemoryRecord) (names, providerIDs []string, err error) {
+	hostnamesFromRecords := &hostnames{}
+	for name, rec := range records {
+		switch {
+		case strings.HasPrefix(name, nodeRecordPrefix) && strings.HasSuffix(name, ".json"):
+			var node corev1.Node
+			if err := json.Unmarshal(rec.Data, &node); err != nil {
+				return nil, nil, fmt.Errorf("unable to read the node record %s: %v", name, err)
+			}
+			hostnamesFromRecords.add(node.Name)
+			hostnamesFromRecords.addProviderID(node.Spec.ProviderID)
+		case strings.HasPrefix(name, machineRecordPrefix) && strings.HasSuffix(name, ".json"):
+			machine := &unstructured.Unstructured{}
+			if err := json.Unmarshal(rec.Data, &machine.Object); err != nil {
+				return nil, nil, fmt.Errorf("unable to read the machine record %s: %v", name, err)
+			}
+			addMachineNames(hostnamesFromRecords, machine)
+		case strings.HasPrefix(name, machineSetRecordPrefix) && strings.HasSuffix(name, ".json"):
+			machineSet := &unstructured.Unstructured{}
+			if err := json.Unmarshal(rec.Data, &machineSet.Object); err != nil {
+				return nil, nil, fmt.Errorf("unable to read the machine set record %s: %v", name, err)
+			}
+			hostnamesFromRecords.add(machineSet.GetName())
+		}
+	}
+	return hostnamesFromRecords.names, hostnamesFromRecords.providerIDs, nil
+}
@@ -0,0 +1,370 @@
+package anonymization
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/insights-operator/pkg/record"
+)
+
+func Test_hostnames_translationTable(t *testing.T) {
+	tests := []struct {
+		name          string
+		hostnames     []string
+		providerIDs   []string
+		previousTable *hostnameTable
+		expectedTable *hostnameTable
+	}{
+		{
+			name:          "no hostnames",
+			expectedTable: &hostnameTable{Pseudonyms: map[string]string{}},
+		},
+		{
+			name:        "FQDN and its short name share the pseudonym",
+			hostnames:   []string{"worker-1", "master-0.ec2.internal", "master-0"},
+			providerIDs: []string{"aws:///us-east-1a/i-0def", "aws:///us-east-1a/i-0abc", "xxxxxxxxxxxx"},
+			expectedTable: &hostnameTable{
+				Pseudonyms: map[string]string{
+					"master-0.ec2.internal":    "obfuscated-host-1",
+					"master-0":                 "obfuscated-host-1",
+					"worker-1":                 "obfuscated-host-2",
+					"aws:///us-east-1a/i-0abc": "obfuscated-provider-id-1",
+					"aws:///us-east-1a/i-0def": "obfuscated-provider-id-2",
+				},
+				LastHostNumber:       2,
+				LastProviderIDNumber: 2,
+			},
+		},
+		{
+			name:        "pseudonyms from the previous table are kept and the new names get the next numbers",
+			hostnames:   []string{"master-0", "master-2", "worker-0"},
+			providerIDs: []string{"aws:///us-east-1a/i-0abc", "aws:///us-east-1a/i-0bcd"},
+			previousTable: &hostnameTable{
+				Pseudonyms: map[string]string{
+					"master-0":                 "obfuscated-host-1",
+					"worker-0":                 "obfuscated-host-3",
+					"aws:///us-east-1a/i-0bcd": "obfuscated-provider-id-2",
+				},
+				LastHostNumber:       3,
+				LastProviderIDNumber: 2,
+			},
+			expectedTable: &hostnameTable{
+				Pseudonyms: map[string]string{
+					"master-0":                 "obfuscated-host-1",
+					"worker-0":                 "obfuscated-host-3",
+					"master-2":                 "obfuscated-host-4",
+					"aws:///us-east-1a/i-0bcd": "obfuscated-provider-id-2",
+					"aws:///us-east-1a/i-0abc": "obfuscated-provider-id-3",
+				},
+				LastHostNumber:       4,
+				LastProviderIDNumber: 3,
+			},
+		},
+		{
+			name:      "removed hostnames are dropped and their numbers are not reused",
+			hostnames: []string{"master-0.ec2.internal", "worker-2"},
+			previousTable: &hostnameTable{
+				Pseudonyms: map[string]string{
+					"master-0.ec2.internal":    "obfuscated-host-1",
+					"master-0":                 "obfuscated-host-1",
+					"worker-1":                 "obfuscated-host-5",
+					"aws:///us-east-1a/i-0abc": "obfuscated-provider-id-1",
+				},
+				LastHostNumber:       6,
+				LastProviderIDNumber: 1,
+			},
+			expectedTable: &hostnameTable{
+				Pseudonyms: map[string]string{
+					"master-0.ec2.internal": "obfuscated-host-1",
+					"master-0":              "obfuscated-host-1",
+					"worker-2":              "obfuscated-host-7",
+				},
+				LastHostNumber:       7,
+				LastProviderIDNumber: 1,
+			},
+		},
+		{
+			name:      "numbers are read from the table stored by the older versions",
+			hostnames: []string{"master-0", "master-1"},
+			previousTable: &hostnameTable{
+				Pseudonyms: map[string]string{
+					"master-0": "obfuscated-host-1",
+					"master-2": "obfuscated-host-2",
+					"10.0.0.1": "10.0.0.2",
+				},
+			},
+			expectedTable: &hostnameTable{
+				Pseudonyms: map[string]string{
+					"master-0": "obfuscated-host-1",
+					"master-1": "obfuscated-host-3",
+				},
+				LastHostNumber: 3,
+			},
+		},
+		{
+			name:      "localhost is not used as a short name",
+			hostnames: []string{"localhost.localdomain"},
+			expectedTable: &hostnameTable{
+				Pseudonyms: map[string]string{
+					"localhost.localdomain": "obfuscated-host-1",
+				},
+				LastHostNumber: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &hostnames{}
+			for _, name := range tt.hostnames {
+				h.add(name)
+			}
+			for _, providerID := range tt.providerIDs {
+				h.addProviderID(providerID)
+			}
+			assert.Equal(t, tt.expectedTable, h.translationTable(tt.previousTable))
+		})
+	}
+}
+
+func Test_hostnameReplacer_replace(t *testing.T) {
+	replacer := newHostnameReplacer(map[string]string{
+		"master-0.ec2.internal": "obfuscated-host-1",
+		"master-0":              "obfuscated-host-1",
+		"master-1":              "obfuscated-host-2",
+	})
+
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{
+			name:     "FQDN is replaced as a whole",
+			data:     `{"hostname": "master-0.ec2.internal"}`,
+			expected: `{"hostname": "obfuscated-host-1"}`,
+		},
+		{
+			name:     "derived names are replaced",
+			data:     "etcd-master-1 kube-apiserver-master-0",
+			expected: "etcd-obfuscated-host-2 kube-apiserver-obfuscated-host-1",
+		},
+		{
+			name:     "names being a part of a longer word are not replaced",
+			data:     "master-10 master-0a",
+			expected: "master-10 master-0a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, string(replacer.replace([]byte(tt.data))))
+		})
+	}
+
+	empty := newHostnameReplacer(map[string]string{})
+	assert.Equal(t, "master-0", string(empty.replace([]byte("master-0"))))
+}
+
+func Test_Anonymizer_Hostnames(t *testing.T) {
+	anonymizer, err := (&NetworkAnonymizerBuilder{}).
+		WithSensitiveValue("example.com", ClusterBaseDomainPlaceholder).
+		WithNetworks([]string{"127.0.0.0/8"}).
+		WithHostnames("master-0.example.com", "worker-0").
+		WithProviderIDs("aws:///us-east-1a/i-0abc").
+		Build()
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		before       string
+		after        string
+		beforeRecord string
+		afterRecord  string
+	}{
+		{
+			name:         "node record",
+			before:       `{"name": "master-0.example.com", "providerID": "aws:///us-east-1a/i-0abc"}`,
+			after:        `{"name": "obfuscated-host-1", "providerID": "obfuscated-provider-id-1"}`,
+			beforeRecord: "config/node/master-0.example.com.json",
+			afterRecord:  "config/node/obfuscated-host-1.json",
+		},
+		{
+			name:         "node logs",
+			before:       "worker-0 kubelet[1]: master-0 is ready, api.example.com",
+			after:        "obfuscated-host-2 kubelet[1]: obfuscated-host-1 is ready, api.<CLUSTER_BASE_DOMAIN>",
+			beforeRecord: "config/node/logs/worker-0.log",
+			afterRecord:  "config/node/logs/obfuscated-host-2.log",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obfuscated, err := anonymizer.AnonymizeData(&record.MemoryRecord{
+				Name: tt.beforeRecord,
+				Data: []byte(tt.before),
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.after, string(obfuscated.Data))
+			assert.Equal(t, tt.afterRecord, obfuscated.Name)
+		})
+	}
+
+	anonymizer.secretsClient = kubefake.NewSimpleClientset().CoreV1().Secrets(secretNamespace)
+	secret := anonymizer.StoreTranslationTable()
+	assert.Len(t, secret.StringData, 1)
+	assert.JSONEq(t, `{
+		"pseudonyms": {
+			"master-0.example.com":     "obfuscated-host-1",
+			"master-0":                 "obfuscated-host-1",
+			"worker-0":                 "obfuscated-host-2",
+			"aws:///us-east-1a/i-0abc": "obfuscated-provider-id-1"
+		},
+		"lastHostNumber": 2,
+		"lastProviderIDNumber": 1
+	}`, secret.StringData[hostnameTableSecretKey])
+	assert.Nil(t, anonymizer.hostnameReplacer)
+
+	// the new node sorted before the existing ones doesn't renumber them in the next gathering
+	anonymizer.staticHostnames.add("infra-0")
+	obfuscated, err := anonymizer.AnonymizeData(&record.MemoryRecord{
+		Name: "config/node/logs/worker-0.log",
+		Data: []byte("infra-0 master-0 worker-0"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "obfuscated-host-3 obfuscated-host-1 obfuscated-host-2", string(obfuscated.Data))
+
+	// the secret is accepted by the API server only when all its keys are valid
+	obfuscated, err = anonymizer.AnonymizeData(&record.MemoryRecord{Name: "config/network.json", Data: []byte("127.0.0.5")})
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", string(obfuscated.Data))
+	secret = anonymizer.StoreTranslationTable()
+	assert.Len(t, secret.StringData, 2)
+	for key := range secret.StringData {
+		assert.Empty(t, validation.IsConfigMapKey(key), key)
+	}
+}
+
+func Test_readStoredHostnames(t *testing.T) {
+	tests := []struct {
+		name          string
+		secret        *corev1.Secret
+		expectedTable *hostnameTable
+	}{
+		{
+			name: "no secret",
+		},
+		{
+			name: "hostnames table",
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: TranslationTableSecretName, Namespace: secretNamespace},
+				Data: map[string][]byte{
+					"10.0.0.1":             []byte("10.0.0.2"),
+					hostnameTableSecretKey: []byte(`{"pseudonyms": {"master-0": "obfuscated-host-1"}, "lastHostNumber": 4}`),
+				},
+			},
+			expectedTable: &hostnameTable{Pseudonyms: map[string]string{"master-0": "obfuscated-host-1"}, LastHostNumber: 4},
+		},
+		{
+			name: "table stored by the older versions",
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: TranslationTableSecretName, Namespace: secretNamespace},
+				Data: map[string][]byte{
+					"10.0.0.1": []byte("10.0.0.2"),
+					"master-0": []byte("obfuscated-host-1"),
+				},
+			},
+			expectedTable: &hostnameTable{Pseudonyms: map[string]string{"10.0.0.1": "10.0.0.2", "master-0": "obfuscated-host-1"}},
+		},
+		{
+			name: "invalid hostnames table",
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: TranslationTableSecretName, Namespace: secretNamespace},
+				Data:       map[string][]byte{hostnameTableSecretKey: []byte("{")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeClient := kubefake.NewSimpleClientset()
+			if tt.secret != nil {
+				kubeClient = kubefake.NewSimpleClientset(tt.secret)
+			}
+			anonymizer := &NetworkAnonymizer{secretsClient: kubeClient.CoreV1().Secrets(secretNamespace)}
+			assert.Equal(t, tt.expectedTable, anonymizer.readStoredHostnames(context.Background()))
+		})
+	}
+}
+
+func Test_readHostnames(t *testing.T) {
+	kubeClient := kubefake.NewSimpleClientset(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "master-0.ec2.internal"},
+		Spec:       corev1.NodeSpec{ProviderID: "aws:///us-east-1a/i-0abc"},
+	})
+	machine := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "machine.openshift.io/v1beta1",
+		"kind":       "Machine",
+		"metadata":   map[string]interface{}{"name": "cluster-x7k2-master-0", "namespace": "openshift-machine-api"},
+		"spec":       map[string]interface{}{"providerID": "aws:///us-east-1a/i-0abc"},
+		"status": map[string]interface{}{
+			"nodeRef": map[string]interface{}{"name": "master-0.ec2.internal"},
+		},
+	}}
+	machineSet := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "machine.openshift.io/v1beta1",
+		"kind":       "MachineSet",
+		"metadata":   map[string]interface{}{"name": "cluster-x7k2-worker-us-east-1a", "namespace": "openshift-machine-api"},
+	}}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			machinesGVR:    "MachinesList",
+			machineSetsGVR: "MachineSetsList",
+		}, machine, machineSet)
+
+	anonymizer := &NetworkAnonymizer{gatherKubeClient: kubeClient, dynamicClient: dynamicClient}
+	names, err := anonymizer.readHostnames(context.Background())
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"master-0.ec2.internal", "cluster-x7k2-master-0", "master-0.ec2.internal", "cluster-x7k2-worker-us-east-1a",
+	}, names.names)
+	assert.ElementsMatch(t, []string{"aws:///us-east-1a/i-0abc", "aws:///us-east-1a/i-0abc"}, names.providerIDs)
+}
+
+func Test_GetHostnamesForAnonymizerFromRecords(t *testing.T) {
+	records := map[string]*record.MemoryRecord{
+		"config/node/master-0.json": {
+			Data: []byte(`{"metadata": {"name": "master-0"}, "spec": {"providerID": "gce://project/zone/master-0"}}`),
+		},
+		"config/machines/openshift-machine-api/cluster-master-0.json": {
+			Data: []byte(`{"metadata": {"name": "cluster-master-0"}, "spec": {"providerID": "xxxxxxxxx"},` +
+				` "status": {"nodeRef": {"name": "master-0"}}}`),
+		},
+		"machinesets/openshift-machine-api/cluster-worker-a.json": {
+			Data: []byte(`{"metadata": {"name": "cluster-worker-a"}}`),
+		},
+		"config/node/logs/master-0.log": {
+			Data: []byte("not a JSON"),
+		},
+	}
+
+	names, providerIDs, err := GetHostnamesForAnonymizerFromRecords(records)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"master-0", "cluster-master-0", "master-0", "cluster-worker-a"}, names)
+	assert.Equal(t, []string{"gce://project/zone/master-0"}, providerIDs)
+
+	_, _, err = GetHostnamesForAnonymizerFromRecords(map[string]*record.MemoryRecord{
+		"config/node/master-0.json": {Data: []byte("{")},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,169 @@
+package anonymization
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/openshift/insights-operator/pkg/record"
+)
+
+const (
+	// SensitiveValueLeak is a cluster domain or any other sensitive value which should have been replaced by a placeholder
+	SensitiveValueLeak LeakKind = "sensitive_value"
+	// IPAddressLeak is an IP address from the cluster networks which wasn't obfuscated
+	IPAddressLeak LeakKind = "ip_address"
+	// ObfuscationRuleLeak is a match of the user defined obfuscation rule
+	ObfuscationRuleLeak LeakKind = "obfuscation_rule"
+	// HostnameLeak is a node or machine name or provider ID which wasn't replaced by its pseudonym
+	HostnameLeak LeakKind = "hostname"
+)
+
+type LeakKind string
+
+// Leak is a known sensitive value found in the data after the obfuscation.
+// The Offset is the byte offset in the file content or -1 when the value was found in the file name.
+type Leak struct {
+	File   string
+	Offset int
+	Kind   LeakKind
+	Value  string
+}
+
+// String describes the leak without the leaked value, so that it can be logged. The value is identified only
+// by its short hash and it's replaced by the hash also in the file name.
+func (l Leak) String() string {
+	valueHash := l.valueHash()
+	if l.Offset < 0 {
+		return fmt.Sprintf("%s (file name): %s %s", strings.ReplaceAll(l.File, l.Value, valueHash), l.Kind, valueHash)
+	}
+	return fmt.Sprintf("%s:%d: %s %s", l.File, l.Offset, l.Kind, valueHash)
+}
+
+// valueHash returns the short SHA-256 hash of the leaked value
+func (l Leak) valueHash() string {
+	sum := sha256.Sum256([]byte(l.Value))
+	return "sha256:" + hex.EncodeToString(sum[:4])
+}
+
+// LeakDetector scans the obfuscated data for the remaining known sensitive values:
+// the sensitive values (e.g. cluster base domain), the IP addresses from the cluster networks
+// which are not the result of the obfuscation, the node and machine names and the matches of the user defined
+// obfuscation rules.
+type LeakDetector struct {
+	sensitiveValues []string
+	networks        []net.IPNet
+	obfuscatedIPs   map[string]struct{}
+	rulePatterns    []rulePattern
+	hostnames       *hostnameReplacer
+	ipNetworkRegex  *regexp.Regexp
+}
+
+// LeakDetector creates a new LeakDetector from the current state of the anonymizer. It must be called
+// before the translation table is reset, otherwise all the obfuscated IP addresses are reported.
+func (na *NetworkAnonymizer) LeakDetector() *LeakDetector {
+	detector := &LeakDetector{
+		obfuscatedIPs:  make(map[string]struct{}, len(na.translationTable)),
+		rulePatterns:   na.rulePatterns,
+		hostnames:      na.hostnameReplacer,
+		ipNetworkRegex: regexp.MustCompile(Ipv4AddressOrNetworkRegex),
+	}
+	for value := range na.sensitiveValues {
+		detector.sensitiveValues = append(detector.sensitiveValues, value)
+	}
+	sort.Strings(detector.sensitiveValues)
+	for i := range na.networks {
+		detector.networks = append(detector.networks, na.networks[i].network)
+	}
+	for _, obfuscatedIP := range na.translationTable {
+		detector.obfuscatedIPs[obfuscatedIP] = struct{}{}
+	}
+	return detector
+}
+
+// ScanRecords scans the content and the names of all the records and returns all the found leaks
+func (d *LeakDetector) ScanRecords(records record.MemoryRecords) []Leak {
+	var leaks []Leak
+	for i := range records {
+		leaks = append(leaks, d.Scan(records[i].Name, records[i].Data)...)
+	}
+	return leaks
+}
+
+// Scan scans the file name and its content and returns all the found leaks
+func (d *LeakDetector) Scan(name string, data []byte) []Leak {
+	var leaks []Leak
+	for _, value := range d.sensitiveValues {
+		if strings.Contains(name, value) {
+			leaks = append(leaks, Leak{File: name, Offset: -1, Kind: SensitiveValueLeak, Value: value})
+		}
+		for offset := 0; ; {
+			idx := bytes.Index(data[offset:], []byte(value))
+			if idx < 0 {
+				break
+			}
+			leaks = append(leaks, Leak{File: name, Offset: offset + idx, Kind: SensitiveValueLeak, Value: value})
+			offset += idx + len(value)
+		}
+	}
+
+	for _, rule := range d.rulePatterns {
+		if match := rule.regex.FindString(name); match != "" {
+			leaks = append(leaks, Leak{File: name, Offset: -1, Kind: ObfuscationRuleLeak, Value: match})
+		}
+		for _, loc := range rule.regex.FindAllIndex(data, -1) {
+			leaks = append(leaks, Leak{
+				File: name, Offset: loc[0], Kind: ObfuscationRuleLeak, Value: string(data[loc[0]:loc[1]]),
+			})
+		}
+	}
+
+	if d.hostnames != nil {
+		for _, loc := range d.hostnames.findAll([]byte(name)) {
+			leaks = append(leaks, Leak{File: name, Offset: -1, Kind: HostnameLeak, Value: name[loc[0]:loc[1]]})
+		}
+		for _, loc := range d.hostnames.findAll(data) {
+			leaks = append(leaks, Leak{File: name, Offset: loc[0], Kind: HostnameLeak, Value: string(data[loc[0]:loc[1]])})
+		}
+	}
+
+	for _, loc := range d.ipNetworkRegex.FindAllIndex(data, -1) {
+		ip := string(data[loc[0]:loc[1]])
+		if d.isIPLeaked(ip) {
+			leaks = append(leaks, Leak{File: name, Offset: loc[0], Kind: IPAddressLeak, Value: ip})
+		}
+	}
+
+	return leaks
+}
+
+// isIPLeaked checks whether the IP address belongs to any of the cluster networks
+// and it's not the network address itself or the result of the obfuscation
+func (d *LeakDetector) isIPLeaked(ipStr string) bool {
+	if strings.Contains(ipStr, "/") {
+		// subnets are not obfuscated
+		return false
+	}
+	if _, obfuscated := d.obfuscatedIPs[ipStr]; obfuscated {
+		return false
+	}
+	ip := net.ParseIP(ipStr)
+	if ip == nil {
+		return false
+	}
+	for i := range d.networks {
+		network := &d.networks[i]
+		if network.IP.Equal(ip) {
+			return false
+		}
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package anonymization
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/insights-operator/pkg/config"
+	"github.com/openshift/insights-operator/pkg/record"
+)
+
+func Test_LeakDetector_Scan(t *testing.T) {
+	anonymizer, err := (&NetworkAnonymizerBuilder{}).
+		WithSensitiveValue("example.com", ClusterBaseDomainPlaceholder).
+		WithNetworks([]string{"10.128.0.0/14"}).
+		WithHostnames("master-0").
+		WithObfuscationRules(config.ObfuscationRule{
+			Type: config.RegexRule, Value: `ACC-[0-9]{6}`, Placeholder: "<ACCOUNT_ID>",
+		}).
+		Build()
+	assert.NoError(t, err)
+
+	obfuscated, err := anonymizer.AnonymizeData(&record.MemoryRecord{
+		Name: "config/node.json",
+		Data: []byte(`{"host": "node.example.com", "node": "master-0", "ip": "10.128.0.55", "account": "ACC-123456"}`),
+	})
+	assert.NoError(t, err)
+	detector := anonymizer.LeakDetector()
+
+	tests := []struct {
+		name          string
+		record        record.MemoryRecord
+		expectedLeaks []Leak
+	}{
+		{
+			name:   "obfuscated record has no leaks",
+			record: *obfuscated,
+		},
+		{
+			name: "subnets and the network address are not reported",
+			record: record.MemoryRecord{
+				Name: "config/network.json",
+				Data: []byte(`{"cidr": "10.128.0.0/14", "network": "10.128.0.0", "external": "8.8.8.8"}`),
+			},
+		},
+		{
+			name: "all the remaining sensitive values are reported with their offsets",
+			record: record.MemoryRecord{
+				Name: "config/example.com/ACC-654321.json",
+				Data: []byte(`example.com 10.129.1.1 ACC-654321 example.com`),
+			},
+			expectedLeaks: []Leak{
+				{File: "config/example.com/ACC-654321.json", Offset: -1, Kind: SensitiveValueLeak, Value: "example.com"},
+				{File: "config/example.com/ACC-654321.json", Offset: 0, Kind: SensitiveValueLeak, Value: "example.com"},
+				{File: "config/example.com/ACC-654321.json", Offset: 34, Kind: SensitiveValueLeak, Value: "example.com"},
+				{File: "config/example.com/ACC-654321.json", Offset: -1, Kind: ObfuscationRuleLeak, Value: "ACC-654321"},
+				{File: "config/example.com/ACC-654321.json", Offset: 23, Kind: ObfuscationRuleLeak, Value: "ACC-654321"},
+				{File: "config/example.com/ACC-654321.json", Offset: 12, Kind: IPAddressLeak, Value: "10.129.1.1"},
+			},
+		},
+		{
+			name: "hostnames are reported",
+			record: record.MemoryRecord{
+				Name: "config/node/logs/master-0.log",
+				Data: []byte(`kubelet on master-0 started, master-01 is not a hostname`),
+			},
+			expectedLeaks: []Leak{
+				{File: "config/node/logs/master-0.log", Offset: -1, Kind: HostnameLeak, Value: "master-0"},
+				{File: "config/node/logs/master-0.log", Offset: 11, Kind: HostnameLeak, Value: "master-0"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaks := detector.ScanRecords(record.MemoryRecords{tt.record})
+			assert.Equal(t, tt.expectedLeaks, leaks)
+		})
+	}
+}
+
+func Test_Leak_String(t *testing.T) {
+	leaks := []Leak{
+		{File: "config/node.json", Offset: 12, Kind: IPAddressLeak, Value: "10.128.0.5"},
+		{File: "config/example.com.json", Offset: -1, Kind: SensitiveValueLeak, Value: "example.com"},
+	}
+	for _, leak := range leaks {
+		// the logged description must never contain the leaked value
+		assert.NotContains(t, leak.String(), leak.Value)
+	}
+	assert.Regexp(t, `^config/node\.json:12: ip_address sha256:[0-9a-f]{8}$`, leaks[0].String())
+	assert.Regexp(t, `^config/sha256:[0-9a-f]{8}\.json \(file name\): sensitive_value sha256:[0-9a-f]{8}$`, leaks[1].String())
+}
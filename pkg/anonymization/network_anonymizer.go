@@ -8,6 +8,7 @@ import (
 	"math/big"
 	"net"
 	"net/url"
+	"reflect"
 	"regexp"
 	"strings"
 	"sync"
@@ -23,6 +24,7 @@ import (
 	"github.com/openshift/insights-operator/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
@@ -58,8 +60,19 @@ type subnetInformation struct {
 	lastIP  net.IP
 }
 
+// rulePattern is a compiled user defined obfuscation rule
+type rulePattern struct {
+	regex       *regexp.Regexp
+	placeholder string
+}
+
 type NetworkAnonymizer struct {
-	sensitiveValues  map[string]string
+	sensitiveValues map[string]string
+	// staticRulePatterns are the obfuscation rules provided to the builder, they are always applied
+	staticRulePatterns []rulePattern
+	// configuredRules are the obfuscation rules from the configuration the rulePatterns were compiled from
+	configuredRules  []config.ObfuscationRule
+	rulePatterns     []rulePattern
 	networks         []subnetInformation
 	translationTable map[string]string
 	ipNetworkRegex   *regexp.Regexp
@@ -69,7 +82,13 @@ type NetworkAnonymizer struct {
 	configClient     configv1client.ConfigV1Interface
 	networkClient    networkv1client.NetworkV1Interface
 	gatherKubeClient kubernetes.Interface
+	dynamicClient    dynamic.Interface
 	runningInCluster bool
+	// staticHostnames are the hostnames and provider IDs provided to the builder
+	staticHostnames hostnames
+	// hostnameTable maps the node and machine names and provider IDs to their pseudonyms
+	hostnameTable    *hostnameTable
+	hostnameReplacer *hostnameReplacer
 }
 
 func NewNetworkAnonymizerFromConfig(
@@ -101,6 +120,11 @@ func NewNetworkAnonymizerFromConfig(
 		return nil, err
 	}
 
+	dynamicClient, err := dynamic.NewForConfig(gatherKubeConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	infrastructure, err := configClient.Infrastructures().Get(ctx, "cluster", metav1.GetOptions{})
 	if err != nil {
 		return nil, err
@@ -116,7 +140,7 @@ func NewNetworkAnonymizerFromConfig(
 	}
 
 	return NewNetworkAnonymizerFromConfigClient(ctx,
-		kubeClient, gatherKubeClient, configClient, networkClient,
+		kubeClient, gatherKubeClient, configClient, networkClient, dynamicClient,
 		configurator, dataPolicy, sensitiveVals,
 	)
 }
@@ -127,6 +151,7 @@ func NewNetworkAnonymizerFromConfigClient(
 	gatherKubeClient kubernetes.Interface,
 	configClient configv1client.ConfigV1Interface,
 	networkClient networkv1client.NetworkV1Interface,
+	dynamicClient dynamic.Interface,
 	configurator configobserver.Interface,
 	dataPolicies []insightsv1.DataPolicyOption,
 	sensitiveVals map[string]string,
@@ -138,6 +163,7 @@ func NewNetworkAnonymizerFromConfigClient(
 		WithDataPolicies(dataPolicies...).
 		WithKubeClient(gatherKubeClient).
 		WithNetworkClient(networkClient).
+		WithDynamicClient(dynamicClient).
 		WithRunningInCluster(true).
 		WithSecretsClient(kubeClient.CoreV1().Secrets(secretNamespace))
 
@@ -168,6 +194,21 @@ func (na *NetworkAnonymizer) AnonymizeData(memoryRecord *record.MemoryRecord) (*
 		}
 	})
 
+	na.refreshObfuscationRules()
+	if na.hostnameReplacer == nil {
+		na.loadHostnames()
+	}
+
+	// user defined rules are applied first, because they can be more specific than the cluster domains
+	for _, rule := range na.rulePatterns {
+		memoryRecord.Data = rule.regex.ReplaceAll(memoryRecord.Data, []byte(rule.placeholder))
+		memoryRecord.Name = rule.regex.ReplaceAllString(memoryRecord.Name, rule.placeholder)
+	}
+
+	// hostnames are replaced before the cluster domains, so that the whole FQDN is replaced by the pseudonym
+	memoryRecord.Data = na.hostnameReplacer.replace(memoryRecord.Data)
+	memoryRecord.Name = string(na.hostnameReplacer.replace([]byte(memoryRecord.Name)))
+
 	for value, placeholder := range na.sensitiveValues {
 		memoryRecord.Data = bytes.ReplaceAll(
 			memoryRecord.Data,
@@ -188,6 +229,66 @@ func (na *NetworkAnonymizer) AnonymizeData(memoryRecord *record.MemoryRecord) (*
 	return memoryRecord, nil
 }
 
+// refreshObfuscationRules recompiles the user defined obfuscation rules when they were changed
+// in the configuration. The configured rules are applied after the rules provided to the builder.
+// The rules are validated when the configuration is loaded, so the invalid ones are only logged and skipped here.
+func (na *NetworkAnonymizer) refreshObfuscationRules() {
+	if na.configurator == nil || na.configurator.Config() == nil {
+		return
+	}
+	rules := na.configurator.Config().DataReporting.ObfuscationRules
+	if reflect.DeepEqual(rules, na.configuredRules) {
+		return
+	}
+
+	patterns := append([]rulePattern(nil), na.staticRulePatterns...)
+	for i := range rules {
+		regex, err := rules[i].Compile()
+		if err != nil {
+			klog.Warningf("Skipping invalid obfuscation rule %d: %v", i, err)
+			continue
+		}
+		patterns = append(patterns, rulePattern{regex: regex, placeholder: rules[i].Placeholder})
+	}
+	na.configuredRules = rules
+	na.rulePatterns = patterns
+}
+
+// loadHostnames creates the translation table of the node and machine names and provider IDs.
+// The names are read from the cluster for every gathering, because the nodes and machines can change,
+// and the pseudonyms already stored in the translation table secret are kept.
+func (na *NetworkAnonymizer) loadHostnames() {
+	names := hostnames{
+		names:       append([]string(nil), na.staticHostnames.names...),
+		providerIDs: append([]string(nil), na.staticHostnames.providerIDs...),
+	}
+	if na.runningInCluster {
+		clusterNames, err := na.readHostnames(context.Background())
+		if err != nil {
+			klog.Errorf("failed to read the node and machine names, they won't be obfuscated: %v", err)
+		} else {
+			names.names = append(names.names, clusterNames.names...)
+			names.providerIDs = append(names.providerIDs, clusterNames.providerIDs...)
+		}
+	}
+	na.hostnameTable = names.translationTable(na.readStoredHostnames(context.Background()))
+	na.hostnameReplacer = newHostnameReplacer(na.hostnameTable.Pseudonyms)
+}
+
+// compileObfuscationRules compiles the user defined obfuscation rules
+// and returns an error if any of them is not valid
+func compileObfuscationRules(rules []config.ObfuscationRule) ([]rulePattern, error) {
+	var patterns []rulePattern
+	for i := range rules {
+		regex, err := rules[i].Compile()
+		if err != nil {
+			return nil, fmt.Errorf("invalid obfuscation rule %d: %v", i, err)
+		}
+		patterns = append(patterns, rulePattern{regex: regex, placeholder: rules[i].Placeholder})
+	}
+	return patterns, nil
+}
+
 // ObfuscateIP takes an IP as a string and returns obfuscated version. If it exists in the translation table,
 // we just take it from there, if it doesn't, we create an obfuscated version of this IP
 // and record it to the translation table
@@ -248,16 +349,23 @@ func (na *NetworkAnonymizer) ObfuscateIP(ipStr string) string {
 }
 
 // StoreTranslationTable stores the translation table in a Secret in the openshift-insights namespace.
-// The actual data is stored in the StringData portion of the Secret.
+// The actual data is stored in the StringData portion of the Secret. The IP addresses are the keys of the Secret,
+// the hostnames translation table is stored as one JSON value, because not all the hostnames are valid Secret keys.
 func (na *NetworkAnonymizer) StoreTranslationTable() *corev1.Secret {
-	if len(na.translationTable) == 0 {
+	if len(na.translationTable) == 0 && (na.hostnameTable == nil || len(na.hostnameTable.Pseudonyms) == 0) {
 		return nil
 	}
 	defer na.ResetTranslationTable()
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	err := na.secretsClient.Delete(ctx, TranslationTableSecretName, metav1.DeleteOptions{})
+	data, err := na.translationTableSecretData()
+	if err != nil {
+		klog.Errorf("Failed to encode the translation table. err: %s", err)
+		return nil
+	}
+
+	err = na.secretsClient.Delete(ctx, TranslationTableSecretName, metav1.DeleteOptions{})
 	if err != nil {
 		klog.V(4).Infof("Failed to delete translation table secret. err: %s", err)
 	}
@@ -270,7 +378,7 @@ func (na *NetworkAnonymizer) StoreTranslationTable() *corev1.Secret {
 		ObjectMeta: metav1.ObjectMeta{
 			Name: TranslationTableSecretName,
 		},
-		StringData: na.translationTable,
+		StringData: data,
 	}
 
 	createOptions := metav1.CreateOptions{
@@ -286,9 +394,43 @@ func (na *NetworkAnonymizer) StoreTranslationTable() *corev1.Secret {
 	return result
 }
 
+// TranslationTable returns the copy of the current translation table of the IP addresses and the hostnames
+func (na *NetworkAnonymizer) TranslationTable() map[string]string {
+	data := make(map[string]string, len(na.translationTable))
+	for original, obfuscated := range na.translationTable {
+		data[original] = obfuscated
+	}
+	if na.hostnameTable != nil {
+		for original, pseudonym := range na.hostnameTable.Pseudonyms {
+			data[original] = pseudonym
+		}
+	}
+	return data
+}
+
+// translationTableSecretData returns the IP addresses translation table with the encoded hostnames
+// translation table, all the keys are valid Secret keys
+func (na *NetworkAnonymizer) translationTableSecretData() (map[string]string, error) {
+	data := make(map[string]string, len(na.translationTable)+1)
+	for original, obfuscated := range na.translationTable {
+		data[original] = obfuscated
+	}
+	if na.hostnameTable != nil {
+		hostnames, err := json.Marshal(na.hostnameTable)
+		if err != nil {
+			return nil, err
+		}
+		data[hostnameTableSecretKey] = string(hostnames)
+	}
+	return data, nil
+}
+
 // ResetTranslationTable resets the translation table, so that the translation table of multiple gathers won't mix together.
+// The hostnames are read again with the next gathering.
 func (na *NetworkAnonymizer) ResetTranslationTable() {
 	na.translationTable = make(map[string]string)
+	na.hostnameTable = nil
+	na.hostnameReplacer = nil
 }
 
 // obfuscateNetworking tells whether Networking/IP addresses should be "obfuscated" or not
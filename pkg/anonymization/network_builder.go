@@ -8,7 +8,9 @@ import (
 	insightsv1 "github.com/openshift/api/insights/v1"
 	v1 "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
 	networkv1client "github.com/openshift/client-go/network/clientset/versioned/typed/network/v1"
+	"github.com/openshift/insights-operator/pkg/config"
 	"github.com/openshift/insights-operator/pkg/config/configobserver"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	k8snet "k8s.io/utils/net"
@@ -17,6 +19,7 @@ import (
 type NetworkAnonymizerBuilder struct {
 	anon     NetworkAnonymizer
 	networks []string
+	rules    []config.ObfuscationRule
 }
 
 // WithSensitiveValue adds terms that are obfuscated by the anonymizer in the records.
@@ -31,6 +34,35 @@ func (b *NetworkAnonymizerBuilder) WithSensitiveValue(value, placeholder string)
 	return b
 }
 
+// WithObfuscationRules adds user defined literal and regex rules that are obfuscated by the anonymizer in the records.
+// All the matches of a rule are replaced by its placeholder. The rules are compiled and validated in Build.
+func (b *NetworkAnonymizerBuilder) WithObfuscationRules(rules ...config.ObfuscationRule) *NetworkAnonymizerBuilder {
+	b.rules = append(b.rules, rules...)
+	return b
+}
+
+// WithHostnames adds the node and machine names that are replaced by stable pseudonyms in the records.
+// When running in the cluster, the names are also read from the cluster.
+func (b *NetworkAnonymizerBuilder) WithHostnames(names ...string) *NetworkAnonymizerBuilder {
+	for _, name := range names {
+		b.anon.staticHostnames.add(name)
+	}
+	return b
+}
+
+// WithProviderIDs adds the node and machine provider IDs that are replaced by stable pseudonyms in the records.
+func (b *NetworkAnonymizerBuilder) WithProviderIDs(providerIDs ...string) *NetworkAnonymizerBuilder {
+	for _, providerID := range providerIDs {
+		b.anon.staticHostnames.addProviderID(providerID)
+	}
+	return b
+}
+
+func (b *NetworkAnonymizerBuilder) WithDynamicClient(dynamicClient dynamic.Interface) *NetworkAnonymizerBuilder {
+	b.anon.dynamicClient = dynamicClient
+	return b
+}
+
 func (b *NetworkAnonymizerBuilder) WithConfigClient(configClient v1.ConfigV1Interface) *NetworkAnonymizerBuilder {
 	b.anon.configClient = configClient
 	return b
@@ -91,7 +123,14 @@ func (b *NetworkAnonymizerBuilder) Build() (*NetworkAnonymizer, error) {
 		})
 	}
 
+	rulePatterns, err := compileObfuscationRules(b.rules)
+	if err != nil {
+		return nil, err
+	}
+
 	b.makeMapIfNil()
+	b.anon.staticRulePatterns = rulePatterns
+	b.anon.rulePatterns = rulePatterns
 	b.anon.ipNetworkRegex = regexp.MustCompile(Ipv4AddressOrNetworkRegex)
 	b.anon.networks = networksInformation
 	b.anon.translationTable = make(map[string]string)
@@ -0,0 +1,28 @@
+package anonymization
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+)
+
+// DVOMetricsRecordName is the name of the record with the Deployment Validation Operator metrics
+const DVOMetricsRecordName = "config/dvo_metrics"
+
+// workloadNameLabels match the name and namespace labels of the DVO metrics
+var workloadNameLabels = []*regexp.Regexp{
+	regexp.MustCompile(fmt.Sprintf(`(?m)(,?%s=[^\,\}]*")`, "name")),
+	regexp.MustCompile(fmt.Sprintf(`(?m)(,?%s=[^\,\}]*")`, "namespace")),
+}
+
+// RemoveWorkloadNames removes the workload name and namespace labels from the DVO metric lines,
+// so that the workloads are identified only by their UIDs
+func RemoveWorkloadNames(data []byte) []byte {
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	for i := range lines {
+		for _, re := range workloadNameLabels {
+			lines[i] = re.ReplaceAll(lines[i], nil)
+		}
+	}
+	return bytes.Join(lines, nil)
+}
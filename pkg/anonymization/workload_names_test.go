@@ -0,0 +1,36 @@
+package anonymization
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RemoveWorkloadNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{
+			name:     "no metrics",
+			data:     "",
+			expected: "",
+		},
+		{
+			name: "name and namespace labels are removed",
+			data: "# http://dvo.svc:8383\n" +
+				`deployment_validation_operator_run_as_non_root{kind="Deployment",name="frontend",namespace="shop",uid="1234"} 1` + "\n" +
+				`deployment_validation_operator_liveness_probe{uid="5678",namespace="shop",name="backend"} 1` + "\n",
+			expected: "# http://dvo.svc:8383\n" +
+				`deployment_validation_operator_run_as_non_root{kind="Deployment",uid="1234"} 1` + "\n" +
+				`deployment_validation_operator_liveness_probe{uid="5678"} 1` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, string(RemoveWorkloadNames([]byte(tt.data))))
+		})
+	}
+}
@@ -0,0 +1,278 @@
+// Package archiveschema validates the Insights archives against the generated documentation of the gathered data.
+//
+// The known archive paths are the archive locations of the gathering functions from the docs/gathered-data.json
+// catalog generated by cmd/gendoc. Every path can contain placeholders in curly brackets
+// (e.g. "config/node/{name}.json") matching any part of a single path segment. The files at the locations
+// of the records described by the record schema of their gathering function (docs/schemas) must conform to it,
+// the other JSON files must be at least valid JSON documents. The other files (e.g. logs) are not checked.
+// The files written by the operator itself (e.g. the archive metadata) are described in this package.
+package archiveschema
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/insights-operator/docs"
+	"github.com/openshift/insights-operator/pkg/record"
+)
+
+const (
+	// UnknownPathIssue is a file which doesn't match any of the known archive paths
+	UnknownPathIssue IssueKind = "unknown_path"
+	// MissingFileIssue is a required archive path which has no matching file in the archive
+	MissingFileIssue IssueKind = "missing_file"
+	// SchemaIssue is a file which doesn't conform to the JSON schema of its archive path
+	SchemaIssue IssueKind = "schema"
+
+	catalogFileName = "gathered-data.json"
+	schemasDir      = "schemas"
+	// anyJSONSchema is the name of the schema of the JSON files without the record schema
+	anyJSONSchema = "JSON"
+)
+
+var placeholderRegex = regexp.MustCompile(`\{[^/{}]+\}`)
+
+//go:embed *.schema.json
+var schemaFiles embed.FS
+
+// operatorPaths are the files written to the archive by the operator itself, not by the gathering functions.
+// Their schemas are the *.schema.json files of this package.
+var operatorPaths = []archivePath{
+	{Path: "insights-operator/gathers.json", Schema: "gathers.schema.json", Required: true},
+}
+
+type IssueKind string
+
+// Issue is a problem found in the archive
+type Issue struct {
+	File    string
+	Kind    IssueKind
+	Message string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s: %s", i.File, i.Kind, i.Message)
+}
+
+// archivePath is a known path in the archive with the name of its JSON schema
+type archivePath struct {
+	Path     string
+	Schema   string
+	Required bool
+}
+
+// catalog is the part of the docs/gathered-data.json catalog needed for the validation
+type catalog struct {
+	GatheringFunctions []struct {
+		ArchiveLocations      []string `json:"archive_locations"`
+		RecordSchema          string   `json:"record_schema"`
+		RecordSchemaLocations []string `json:"record_schema_locations"`
+	} `json:"gathering_functions"`
+}
+
+type pathValidator struct {
+	archivePath
+	regex  *regexp.Regexp
+	schema *gojsonschema.Schema
+}
+
+// Validator checks the archive files against the known archive paths and their JSON schemas
+type Validator struct {
+	paths []pathValidator
+}
+
+// NewValidator creates a new Validator from the embedded catalog of the gathered data and the JSON schemas
+func NewValidator() (*Validator, error) {
+	data, err := docs.GatheredData.ReadFile(catalogFileName)
+	if err != nil {
+		return nil, err
+	}
+	var c catalog
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("unable to read the catalog of the gathered data: %v", err)
+	}
+
+	schemas := map[string]*gojsonschema.Schema{}
+	v := &Validator{}
+	addPath := func(p archivePath, loadSchema func(name string) (*gojsonschema.Schema, error)) error {
+		pv := pathValidator{archivePath: p, regex: pathRegex(p.Path)}
+		if p.Schema != "" {
+			schema, ok := schemas[p.Schema]
+			if !ok {
+				schema, err = loadSchema(p.Schema)
+				if err != nil {
+					return err
+				}
+				schemas[p.Schema] = schema
+			}
+			pv.schema = schema
+		}
+		v.paths = append(v.paths, pv)
+		return nil
+	}
+
+	for _, p := range operatorPaths {
+		if err := addPath(p, loadOperatorSchema); err != nil {
+			return nil, err
+		}
+	}
+	for _, function := range c.GatheringFunctions {
+		recordSchemaLocations := sets.New(function.RecordSchemaLocations...)
+		for _, location := range function.ArchiveLocations {
+			if strings.HasSuffix(location, "/") || strings.Contains(location, "//") {
+				return nil, fmt.Errorf("the archive location %q is not a file path", location)
+			}
+			p := archivePath{Path: location}
+			switch {
+			case recordSchemaLocations.Has(location):
+				p.Schema = function.RecordSchema
+			case path.Ext(location) == ".json":
+				p.Schema = anyJSONSchema
+			}
+			if err := addPath(p, loadRecordSchema); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// the most specific path is matched first, e.g. "config/pod/openshift-cluster-version/version.json"
+	// is preferred over "config/pod/{namespace}/{pod}.json"
+	sort.SliceStable(v.paths, func(i, j int) bool {
+		return literalLength(v.paths[i].Path) > literalLength(v.paths[j].Path)
+	})
+	return v, nil
+}
+
+func loadOperatorSchema(name string) (*gojsonschema.Schema, error) {
+	data, err := schemaFiles.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown JSON schema %q: %v", name, err)
+	}
+	return newSchema(name, data)
+}
+
+func loadRecordSchema(name string) (*gojsonschema.Schema, error) {
+	if name == anyJSONSchema {
+		return newSchema(name, []byte("{}"))
+	}
+	data, err := docs.GatheredData.ReadFile(path.Join(schemasDir, name))
+	if err != nil {
+		return nil, fmt.Errorf("unknown JSON schema %q: %v", name, err)
+	}
+	return newSchema(name, data)
+}
+
+func newSchema(name string, data []byte) (*gojsonschema.Schema, error) {
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(data))
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the JSON schema %q: %v", name, err)
+	}
+	return schema, nil
+}
+
+// pathRegex converts the archive path with placeholders to the regular expression
+func pathRegex(path string) *regexp.Regexp {
+	var pattern strings.Builder
+	last := 0
+	for _, loc := range placeholderRegex.FindAllStringIndex(path, -1) {
+		pattern.WriteString(regexp.QuoteMeta(path[last:loc[0]]))
+		pattern.WriteString("[^/]+")
+		last = loc[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(path[last:]))
+	return regexp.MustCompile("^" + pattern.String() + "$")
+}
+
+// literalLength returns the length of the archive path without its placeholders
+func literalLength(path string) int {
+	return len(placeholderRegex.ReplaceAllString(path, ""))
+}
+
+// Validate checks all the archive files and returns the found issues sorted by the file name.
+// The files not matching any of the known paths and the missing required files are reported
+// as well as the files which don't conform to the JSON schema of their path.
+func (v *Validator) Validate(records map[string]*record.MemoryRecord) []Issue {
+	var issues []Issue
+	found := make([]bool, len(v.paths))
+	for name, rec := range records {
+		i := v.match(name)
+		if i < 0 {
+			issues = append(issues, Issue{File: name, Kind: UnknownPathIssue, Message: "the path is not documented"})
+			continue
+		}
+		found[i] = true
+		if v.paths[i].schema != nil {
+			issues = append(issues, validateData(name, v.paths[i], rec.Data)...)
+		}
+	}
+
+	for i := range v.paths {
+		if v.paths[i].Required && !found[i] {
+			issues = append(issues, Issue{File: v.paths[i].Path, Kind: MissingFileIssue, Message: "the required file is missing"})
+		}
+	}
+
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].File != issues[j].File {
+			return issues[i].File < issues[j].File
+		}
+		return issues[i].Message < issues[j].Message
+	})
+	return issues
+}
+
+// match returns the index of the first archive path matching the file name or -1
+func (v *Validator) match(name string) int {
+	for i := range v.paths {
+		if v.paths[i].regex.MatchString(name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func validateData(name string, p pathValidator, data []byte) []Issue {
+	result, err := p.schema.Validate(gojsonschema.NewBytesLoader(data))
+	if err != nil {
+		return []Issue{{File: name, Kind: SchemaIssue, Message: fmt.Sprintf("unable to read the JSON data: %v", err)}}
+	}
+	var issues []Issue
+	for _, resultErr := range result.Errors() {
+		issues = append(issues, Issue{
+			File:    name,
+			Kind:    SchemaIssue,
+			Message: fmt.Sprintf("%s (schema %s)", resultErr.String(), p.Schema),
+		})
+	}
+	return issues
+}
+
+// TestingT is the subset of testing.TB used by AssertValidArchive
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// AssertValidArchive reports all the issues found in the archive records as the test errors.
+// It's meant to be used in the tests of the gatherers and the archive producers.
+func AssertValidArchive(t TestingT, records map[string]*record.MemoryRecord) bool {
+	t.Helper()
+	v, err := NewValidator()
+	if err != nil {
+		t.Errorf("unable to create the archive validator: %v", err)
+		return false
+	}
+	issues := v.Validate(records)
+	for _, issue := range issues {
+		t.Errorf("%s", issue)
+	}
+	return len(issues) == 0
+}
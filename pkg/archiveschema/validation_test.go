@@ -0,0 +1,109 @@
+package archiveschema
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/insights-operator/pkg/record"
+)
+
+const sampleArchiveDir = "../../docs/insights-archive-sample"
+
+func Test_pathRegex(t *testing.T) {
+	tests := []struct {
+		path     string
+		name     string
+		expected bool
+	}{
+		{path: "config/node/{name}.json", name: "config/node/master-0.json", expected: true},
+		{path: "config/node/{name}.json", name: "config/node/logs/master-0.json", expected: false},
+		{path: "config/node/{name}.json", name: "config/node/master-0.log", expected: false},
+		{path: "conditional/logs/last-{n}-lines.log", name: "conditional/logs/last-100-lines.log", expected: true},
+		{path: "config/id", name: "config/identity", expected: false},
+		{path: "config/version.json", name: "config/version-json", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, pathRegex(tt.path).MatchString(tt.name))
+		})
+	}
+}
+
+func Test_NewValidator(t *testing.T) {
+	validator, err := NewValidator()
+	assert.NoError(t, err)
+
+	// the catalog locations with the record schema and the other JSON locations
+	i := validator.match("config/running_containers.json")
+	assert.GreaterOrEqual(t, i, 0)
+	assert.Equal(t, "clusterconfig/container_images.schema.json", validator.paths[i].Schema)
+	i = validator.match("config/version.json")
+	assert.GreaterOrEqual(t, i, 0)
+	assert.Equal(t, anyJSONSchema, validator.paths[i].Schema)
+	i = validator.match("config/node/logs/master-0.log")
+	assert.GreaterOrEqual(t, i, 0)
+	assert.Empty(t, validator.paths[i].Schema)
+}
+
+func Test_Validator_Validate(t *testing.T) {
+	validator, err := NewValidator()
+	assert.NoError(t, err)
+
+	valid := map[string]*record.MemoryRecord{
+		"config/id":                      {Data: []byte("0b8b7e2c-6f3d-4f7b-9a4c-2b4f0a0c9d1e")},
+		"config/version.json":            {Data: []byte(`{"metadata": {"name": "version"}}`)},
+		"config/running_containers.json": {Data: []byte(`{"images": {"0": "sha256:0"}, "containers": {"0": {"0": 1}}}`)},
+		"insights-operator/gathers.json": {Data: []byte(
+			`{"status_reports": [{"name": "clusterconfig/nodes", "duration_in_ms": 10, "records_count": 1}]}`)},
+		"config/node/logs/master-0.log": {Data: []byte("not a JSON")},
+	}
+	assert.Empty(t, validator.Validate(valid))
+
+	invalid := map[string]*record.MemoryRecord{
+		"config/id":                      {Data: []byte("0b8b7e2c-6f3d-4f7b-9a4c-2b4f0a0c9d1e")},
+		"config/running_containers.json": {Data: []byte(`{"images": {}}`)},
+		"config/infrastructure.json":     {Data: []byte(`{`)},
+		"config/unknown.json":            {Data: []byte(`{}`)},
+	}
+	issues := validator.Validate(invalid)
+	var kinds []IssueKind
+	var files []string
+	for _, issue := range issues {
+		kinds = append(kinds, issue.Kind)
+		files = append(files, issue.File)
+	}
+	assert.Equal(t, []IssueKind{SchemaIssue, SchemaIssue, UnknownPathIssue, MissingFileIssue}, kinds)
+	assert.Equal(t, []string{
+		"config/infrastructure.json",
+		"config/running_containers.json",
+		"config/unknown.json",
+		"insights-operator/gathers.json",
+	}, files)
+}
+
+func Test_AssertValidArchive_Sample(t *testing.T) {
+	records := map[string]*record.MemoryRecord{}
+	err := filepath.WalkDir(sampleArchiveDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(sampleArchiveDir, path)
+		if err != nil {
+			return err
+		}
+		records[filepath.ToSlash(name)] = &record.MemoryRecord{Name: filepath.ToSlash(name), Data: data}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, records)
+	AssertValidArchive(t, records)
+}
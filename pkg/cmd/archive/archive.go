@@ -0,0 +1,318 @@
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/insights-operator/pkg/gather"
+	"github.com/openshift/insights-operator/pkg/record"
+	"github.com/openshift/insights-operator/pkg/recorder"
+	"github.com/openshift/insights-operator/pkg/recorder/diskrecorder"
+)
+
+// MetadataFileName is the name of the file with the archive metadata
+const MetadataFileName = recorder.MetadataRecordName + ".json"
+
+// NewArchive creates the command for inspecting the Insights archives
+func NewArchive() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Inspect the Insights archives",
+	}
+
+	cmd.AddCommand(newList())
+	cmd.AddCommand(newCat())
+	cmd.AddCommand(newStats())
+	cmd.AddCommand(newMetadata())
+	cmd.AddCommand(newDiff())
+	cmd.AddCommand(newValidate())
+
+	return cmd
+}
+
+func newList() *cobra.Command {
+	long := false
+	cmd := &cobra.Command{
+		Use:   "ls ARCHIVE",
+		Short: "List the files in the archive",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			records, err := diskrecorder.ReadArchive(args[0])
+			if err != nil {
+				return err
+			}
+			return list(cmd.OutOrStdout(), records, long)
+		},
+	}
+	cmd.Flags().BoolVarP(&long, "long", "l", false, "show the size and the modification time of the files")
+
+	return cmd
+}
+
+func newCat() *cobra.Command {
+	return &cobra.Command{
+		Use:   "cat ARCHIVE FILE...",
+		Short: "Print the content of the files in the archive",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			records, err := diskrecorder.ReadArchive(args[0])
+			if err != nil {
+				return err
+			}
+			return cat(cmd.OutOrStdout(), records, args[1:])
+		},
+	}
+}
+
+func newStats() *cobra.Command {
+	top := 10
+	cmd := &cobra.Command{
+		Use:   "stats ARCHIVE",
+		Short: "Show the size per gatherer and the largest files in the archive",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if top < 0 {
+				return fmt.Errorf("--top must not be negative, got %d", top)
+			}
+			records, err := diskrecorder.ReadArchive(args[0])
+			if err != nil {
+				return err
+			}
+			return stats(cmd.OutOrStdout(), records, top)
+		},
+	}
+	cmd.Flags().IntVar(&top, "top", top, "number of the largest files to show")
+
+	return cmd
+}
+
+func newMetadata() *cobra.Command {
+	asJSON := false
+	cmd := &cobra.Command{
+		Use:   "metadata ARCHIVE",
+		Short: "Print the archive metadata including the errors, warnings and durations of the gathering functions",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			records, err := diskrecorder.ReadArchive(args[0])
+			if err != nil {
+				return err
+			}
+			metadata, err := ReadMetadata(records)
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				return printJSON(cmd.OutOrStdout(), metadata)
+			}
+			return printMetadata(cmd.OutOrStdout(), metadata)
+		},
+	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print the metadata as the indented JSON")
+
+	return cmd
+}
+
+// sortedNames returns the sorted names of all the records
+func sortedNames(records map[string]*record.MemoryRecord) []string {
+	names := make([]string, 0, len(records))
+	for name := range records {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func list(out io.Writer, records map[string]*record.MemoryRecord, long bool) error {
+	if !long {
+		for _, name := range sortedNames(records) {
+			if _, err := fmt.Fprintln(out, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	for _, name := range sortedNames(records) {
+		r := records[name]
+		_, _ = fmt.Fprintf(w, "%d\t%s\t %s\n", len(r.Data), r.At.UTC().Format(time.RFC3339), name)
+	}
+	return w.Flush()
+}
+
+func cat(out io.Writer, records map[string]*record.MemoryRecord, names []string) error {
+	for _, name := range names {
+		r, found := records[strings.TrimPrefix(name, "/")]
+		if !found {
+			return fmt.Errorf("file %s was not found in the archive", name)
+		}
+		if _, err := out.Write(r.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gathererOf returns the gatherer that most likely created the file. The archive doesn't contain
+// the origin of the files, so it's derived from the path. The files of the conditional gatherer
+// are stored in the "conditional" directory and the workloads gatherer writes only the workload and helm chart info.
+// Everything else belongs to the clusterconfig gatherer, except the metadata written by the operator itself.
+func gathererOf(name string) string {
+	switch {
+	case name == MetadataFileName || strings.HasPrefix(name, "insights-operator/"):
+		return "insights-operator"
+	case strings.HasPrefix(name, "conditional/"):
+		return "conditional"
+	case strings.HasPrefix(name, "config/workload_info"), strings.HasPrefix(name, "config/helmchart_info"):
+		return "workloads"
+	default:
+		return "clusterconfig"
+	}
+}
+
+type sizeStat struct {
+	name  string
+	files int
+	bytes int
+}
+
+func stats(out io.Writer, records map[string]*record.MemoryRecord, top int) error {
+	total := sizeStat{name: "total"}
+	byGatherer := make(map[string]*sizeStat)
+	byDirectory := make(map[string]*sizeStat)
+	var files []sizeStat
+	for name, r := range records {
+		size := len(r.Data)
+		total.files++
+		total.bytes += size
+		for key, m := range map[string]map[string]*sizeStat{gathererOf(name): byGatherer, topDirectory(name): byDirectory} {
+			if m[key] == nil {
+				m[key] = &sizeStat{name: key}
+			}
+			m[key].files++
+			m[key].bytes += size
+		}
+		files = append(files, sizeStat{name: name, files: 1, bytes: size})
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "GATHERER\tFILES\tBYTES\n")
+	for _, s := range sortBySize(byGatherer) {
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%d\n", s.name, s.files, s.bytes)
+	}
+	_, _ = fmt.Fprintf(w, "%s\t%d\t%d\n", total.name, total.files, total.bytes)
+
+	_, _ = fmt.Fprintf(w, "\nDIRECTORY\tFILES\tBYTES\n")
+	for _, s := range sortBySize(byDirectory) {
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%d\n", s.name, s.files, s.bytes)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].bytes != files[j].bytes {
+			return files[i].bytes > files[j].bytes
+		}
+		return files[i].name < files[j].name
+	})
+	if top < len(files) {
+		files = files[:top]
+	}
+	_, _ = fmt.Fprintf(w, "\nLARGEST FILES\t\tBYTES\n")
+	for _, f := range files {
+		_, _ = fmt.Fprintf(w, "%s\t\t%d\n", f.name, f.bytes)
+	}
+	return w.Flush()
+}
+
+// topDirectory returns the first two levels of the path, e.g. "config/pod" for "config/pod/ns/name.json"
+func topDirectory(name string) string {
+	parts := strings.SplitN(name, "/", 3)
+	if len(parts) < 3 {
+		return strings.Join(parts[:len(parts)-1], "/") + "/"
+	}
+	return parts[0] + "/" + parts[1] + "/"
+}
+
+func sortBySize(m map[string]*sizeStat) []*sizeStat {
+	result := make([]*sizeStat, 0, len(m))
+	for _, s := range m {
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].bytes != result[j].bytes {
+			return result[i].bytes > result[j].bytes
+		}
+		return result[i].name < result[j].name
+	})
+	return result
+}
+
+// ReadMetadata reads the archive metadata from the records
+func ReadMetadata(records map[string]*record.MemoryRecord) (*gather.ArchiveMetadata, error) {
+	r, found := records[MetadataFileName]
+	if !found {
+		return nil, fmt.Errorf("%s was not found in the archive", MetadataFileName)
+	}
+
+	var metadata gather.ArchiveMetadata
+	if err := json.Unmarshal(r.Data, &metadata); err != nil {
+		return nil, fmt.Errorf("unable to read %s: %v", MetadataFileName, err)
+	}
+	return &metadata, nil
+}
+
+func printJSON(out io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
+
+func printMetadata(out io.Writer, metadata *gather.ArchiveMetadata) error {
+	_, _ = fmt.Fprintf(out, "Uptime:             %s\n", time.Duration(metadata.Uptime*float64(time.Second)).Truncate(time.Millisecond))
+	_, _ = fmt.Fprintf(out, "Memory usage:       %d bytes\n", metadata.MemoryBytesUsage)
+	_, _ = fmt.Fprintf(out, "Global obfuscation: %t\n\n", metadata.IsGlobalObfuscationEnabled)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "FUNCTION\tDURATION\tRECORDS\tERRORS\tWARNINGS\tPANIC\n")
+	for i := range metadata.StatusReports {
+		report := &metadata.StatusReports[i]
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%t\n",
+			report.FuncName,
+			time.Duration(report.Duration)*time.Millisecond,
+			report.RecordsCount,
+			len(report.Errors),
+			len(report.Warnings),
+			report.Panic != nil,
+		)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	for i := range metadata.StatusReports {
+		report := &metadata.StatusReports[i]
+		if len(report.Errors) == 0 && len(report.Warnings) == 0 && report.Panic == nil {
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "\n%s:\n", report.FuncName)
+		for _, e := range report.Errors {
+			_, _ = fmt.Fprintf(out, "  error: %s\n", e)
+		}
+		for _, warning := range report.Warnings {
+			_, _ = fmt.Fprintf(out, "  warning: %s\n", warning)
+		}
+		if report.Panic != nil {
+			_, _ = fmt.Fprintf(out, "  panic: %v\n", report.Panic)
+		}
+	}
+	return nil
+}
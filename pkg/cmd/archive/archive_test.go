@@ -0,0 +1,149 @@
+package archive
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/insights-operator/pkg/record"
+	"github.com/openshift/insights-operator/pkg/recorder/diskrecorder"
+)
+
+const testMetadata = `{
+	"status_reports": [
+		{"name": "clusterconfig/nodes", "duration_in_ms": 1500, "records_count": 2, "errors": null, "warnings": null, "panic": null},
+		{"name": "clusterconfig/pod_logs", "duration_in_ms": 20, "records_count": 0,
+			"errors": ["pods is forbidden"], "warnings": ["no logs"], "panic": null}
+	],
+	"container_memory_bytes_usage": 1024,
+	"uptime_seconds": 61.5,
+	"is_global_obfuscation_enabled": true
+}`
+
+func createArchive(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "archive.tar.gz")
+	file, err := os.Create(path)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	_, err = diskrecorder.WriteArchive(file, record.MemoryRecords{
+		{Name: "config/node/master-0.json", At: at, Data: []byte(`{"name": "master-0"}`)},
+		{Name: "config/node/master-1.json", At: at, Data: []byte(`{"name": "master-1", "ready": true}`)},
+		{Name: "conditional/alerts/KubePodCrashLooping/logs.json", At: at, Data: []byte(`[]`)},
+		{Name: "config/workload_info.json", At: at, Data: []byte(`{}`)},
+		{Name: MetadataFileName, At: at, Data: []byte(testMetadata)},
+	})
+	assert.NoError(t, err)
+	return path
+}
+
+func execute(args ...string) (string, error) {
+	cmd := NewArchive()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func Test_Archive_List(t *testing.T) {
+	path := createArchive(t)
+
+	out, err := execute("ls", path)
+	assert.NoError(t, err)
+	assert.Equal(t, `conditional/alerts/KubePodCrashLooping/logs.json
+config/node/master-0.json
+config/node/master-1.json
+config/workload_info.json
+insights-operator/gathers.json
+`, out)
+
+	out, err = execute("ls", "-l", path)
+	assert.NoError(t, err)
+	assert.Contains(t, out, "   20  2024-05-01T10:00:00Z config/node/master-0.json\n")
+}
+
+func Test_Archive_Cat(t *testing.T) {
+	path := createArchive(t)
+
+	tests := []struct {
+		name          string
+		files         []string
+		expected      string
+		errorContains string
+	}{
+		{
+			name:     "single file",
+			files:    []string{"config/node/master-0.json"},
+			expected: `{"name": "master-0"}`,
+		},
+		{
+			name:     "multiple files",
+			files:    []string{"/config/workload_info.json", "conditional/alerts/KubePodCrashLooping/logs.json"},
+			expected: `{}[]`,
+		},
+		{
+			name:          "missing file",
+			files:         []string{"config/node/master-2.json"},
+			errorContains: "file config/node/master-2.json was not found in the archive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := execute(append([]string{"cat", path}, tt.files...)...)
+			if tt.errorContains != "" {
+				assert.ErrorContains(t, err, tt.errorContains)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, out)
+		})
+	}
+}
+
+func Test_Archive_Stats(t *testing.T) {
+	path := createArchive(t)
+
+	out, err := execute("stats", "--top", "2", path)
+	assert.NoError(t, err)
+	assert.Contains(t, out, "clusterconfig      2      55\n")
+	assert.Contains(t, out, "workloads          1      2\n")
+	assert.Contains(t, out, "conditional        1      2\n")
+	assert.Contains(t, out, "config/node/")
+	assert.Contains(t, out, "insights-operator/gathers.json")
+	assert.Contains(t, out, "config/node/master-1.json")
+	assert.NotContains(t, out, "config/node/master-0.json")
+
+	_, err = execute("stats", "--top=-1", path)
+	assert.EqualError(t, err, "--top must not be negative, got -1")
+}
+
+func Test_Archive_Metadata(t *testing.T) {
+	path := createArchive(t)
+
+	out, err := execute("metadata", path)
+	assert.NoError(t, err)
+	assert.Contains(t, out, "Uptime:             1m1.5s\n")
+	assert.Contains(t, out, "Global obfuscation: true\n")
+	assert.Contains(t, out, "clusterconfig/nodes     1.5s      2        0       0         false\n")
+	assert.Contains(t, out, "clusterconfig/pod_logs:\n  error: pods is forbidden\n  warning: no logs\n")
+
+	out, err = execute("metadata", "--json", path)
+	assert.NoError(t, err)
+	assert.Contains(t, out, `"name": "clusterconfig/pod_logs"`)
+}
+
+func Test_ReadMetadata(t *testing.T) {
+	_, err := ReadMetadata(map[string]*record.MemoryRecord{})
+	assert.EqualError(t, err, "insights-operator/gathers.json was not found in the archive")
+
+	_, err = ReadMetadata(map[string]*record.MemoryRecord{MetadataFileName: {Data: []byte("{")}})
+	assert.ErrorContains(t, err, "unable to read insights-operator/gathers.json")
+}
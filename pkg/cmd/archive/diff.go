@@ -0,0 +1,400 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/insights-operator/pkg/gather"
+	"github.com/openshift/insights-operator/pkg/record"
+	"github.com/openshift/insights-operator/pkg/recorder/diskrecorder"
+)
+
+const (
+	added   changeKind = "+"
+	removed changeKind = "-"
+	changed changeKind = "~"
+
+	// maxValueLength is the maximum length of the printed JSON value
+	maxValueLength = 120
+)
+
+// defaultIgnoredFields are the volatile fields which change with every gathering
+var defaultIgnoredFields = []string{"resourceVersion", "managedFields"}
+
+type changeKind string
+
+// archiveDiff holds the differences between two archives
+type archiveDiff struct {
+	added         []string
+	removed       []string
+	changed       []fileDiff
+	statusReports []statusReportDiff
+}
+
+// fileDiff holds the changes of a single file. The changes are empty when the file is not a JSON file.
+type fileDiff struct {
+	name    string
+	oldSize int
+	newSize int
+	changes []valueChange
+}
+
+// valueChange is the change of the value at the JSON path
+type valueChange struct {
+	kind     changeKind
+	path     string
+	oldValue interface{}
+	newValue interface{}
+}
+
+// statusReportDiff holds the changes of the status report of a single gathering function
+type statusReportDiff struct {
+	kind     changeKind
+	funcName string
+	changes  []string
+}
+
+func newDiff() *cobra.Command {
+	ignoredFields := defaultIgnoredFields
+	cmd := &cobra.Command{
+		Use:   "diff OLD_ARCHIVE NEW_ARCHIVE",
+		Short: "Show the added, removed and changed files and the changes of the gathering functions status",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			oldRecords, err := diskrecorder.ReadArchive(args[0])
+			if err != nil {
+				return err
+			}
+			newRecords, err := diskrecorder.ReadArchive(args[1])
+			if err != nil {
+				return err
+			}
+			d, err := diffArchives(oldRecords, newRecords, ignoredFields)
+			if err != nil {
+				return err
+			}
+			return printDiff(cmd.OutOrStdout(), d)
+		},
+	}
+	cmd.Flags().StringSliceVar(&ignoredFields, "ignore-field", ignoredFields,
+		"name of the JSON field ignored at any level of the JSON files")
+
+	return cmd
+}
+
+// diffArchives compares the files of the archives. The JSON files are compared semantically without
+// the ignored fields, the metadata file is compared per gathering function.
+func diffArchives(oldRecords, newRecords map[string]*record.MemoryRecord, ignoredFields []string) (*archiveDiff, error) {
+	ignored := make(map[string]struct{}, len(ignoredFields))
+	for _, field := range ignoredFields {
+		ignored[field] = struct{}{}
+	}
+
+	d := &archiveDiff{}
+	for _, name := range sortedNames(oldRecords) {
+		if name == MetadataFileName {
+			continue
+		}
+		newRecord, found := newRecords[name]
+		if !found {
+			d.removed = append(d.removed, name)
+			continue
+		}
+		if fd, isChanged := diffFiles(name, oldRecords[name].Data, newRecord.Data, ignored); isChanged {
+			d.changed = append(d.changed, fd)
+		}
+	}
+	for _, name := range sortedNames(newRecords) {
+		if _, found := oldRecords[name]; !found && name != MetadataFileName {
+			d.added = append(d.added, name)
+		}
+	}
+
+	oldMetadata, oldErr := ReadMetadata(oldRecords)
+	newMetadata, newErr := ReadMetadata(newRecords)
+	switch {
+	case oldErr != nil && newErr != nil:
+		return d, nil
+	case oldErr != nil:
+		return nil, oldErr
+	case newErr != nil:
+		return nil, newErr
+	}
+	d.statusReports = diffStatusReports(oldMetadata.StatusReports, newMetadata.StatusReports)
+
+	return d, nil
+}
+
+// diffFiles compares the content of the files and returns false when the files are the same
+func diffFiles(name string, oldData, newData []byte, ignored map[string]struct{}) (fileDiff, bool) {
+	fd := fileDiff{name: name, oldSize: len(oldData), newSize: len(newData)}
+	if bytes.Equal(oldData, newData) {
+		return fd, false
+	}
+
+	oldValue, oldErr := decodeJSON(oldData)
+	newValue, newErr := decodeJSON(newData)
+	if oldErr != nil || newErr != nil {
+		return fd, true
+	}
+
+	oldValue = removeFields(oldValue, ignored)
+	newValue = removeFields(newValue, ignored)
+	fd.changes = diffValues("", oldValue, newValue, nil)
+	return fd, len(fd.changes) > 0
+}
+
+func decodeJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("more than one JSON value")
+	}
+	return v, nil
+}
+
+// removeFields removes the ignored fields at any level of the JSON value
+func removeFields(v interface{}, ignored map[string]struct{}) interface{} {
+	switch typed := v.(type) {
+	case map[string]interface{}:
+		for key, value := range typed {
+			if _, isIgnored := ignored[key]; isIgnored {
+				delete(typed, key)
+				continue
+			}
+			typed[key] = removeFields(value, ignored)
+		}
+	case []interface{}:
+		for i := range typed {
+			typed[i] = removeFields(typed[i], ignored)
+		}
+	}
+	return v
+}
+
+// diffValues appends the changes between the JSON values to the changes
+func diffValues(path string, oldValue, newValue interface{}, changes []valueChange) []valueChange {
+	switch oldTyped := oldValue.(type) {
+	case map[string]interface{}:
+		newTyped, ok := newValue.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(oldTyped)+len(newTyped))
+		for key := range oldTyped {
+			keys = append(keys, key)
+		}
+		for key := range newTyped {
+			if _, found := oldTyped[key]; !found {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			keyPath := joinPath(path, key)
+			oldChild, inOld := oldTyped[key]
+			newChild, inNew := newTyped[key]
+			switch {
+			case !inOld:
+				changes = append(changes, valueChange{kind: added, path: keyPath, newValue: newChild})
+			case !inNew:
+				changes = append(changes, valueChange{kind: removed, path: keyPath, oldValue: oldChild})
+			default:
+				changes = diffValues(keyPath, oldChild, newChild, changes)
+			}
+		}
+		return changes
+	case []interface{}:
+		newTyped, ok := newValue.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(oldTyped) || i < len(newTyped); i++ {
+			indexPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(oldTyped):
+				changes = append(changes, valueChange{kind: added, path: indexPath, newValue: newTyped[i]})
+			case i >= len(newTyped):
+				changes = append(changes, valueChange{kind: removed, path: indexPath, oldValue: oldTyped[i]})
+			default:
+				changes = diffValues(indexPath, oldTyped[i], newTyped[i], changes)
+			}
+		}
+		return changes
+	}
+
+	if !reflect.DeepEqual(oldValue, newValue) {
+		changes = append(changes, valueChange{kind: changed, path: path, oldValue: oldValue, newValue: newValue})
+	}
+	return changes
+}
+
+func joinPath(path, key string) string {
+	if strings.ContainsAny(key, ".[]") {
+		key = fmt.Sprintf("[%q]", key)
+		return path + key
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// diffStatusReports compares the number of records, the errors, the warnings and the panics
+// of the gathering functions. The durations are not compared, because they change with every gathering.
+func diffStatusReports(oldReports, newReports []gather.GathererFunctionReport) []statusReportDiff {
+	oldByName := make(map[string]*gather.GathererFunctionReport, len(oldReports))
+	for i := range oldReports {
+		oldByName[oldReports[i].FuncName] = &oldReports[i]
+	}
+	newByName := make(map[string]*gather.GathererFunctionReport, len(newReports))
+	for i := range newReports {
+		newByName[newReports[i].FuncName] = &newReports[i]
+	}
+
+	names := make([]string, 0, len(oldByName)+len(newByName))
+	for name := range oldByName {
+		names = append(names, name)
+	}
+	for name := range newByName {
+		if _, found := oldByName[name]; !found {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var diffs []statusReportDiff
+	for _, name := range names {
+		oldReport, inOld := oldByName[name]
+		newReport, inNew := newByName[name]
+		switch {
+		case !inOld:
+			diffs = append(diffs, statusReportDiff{kind: added, funcName: name})
+		case !inNew:
+			diffs = append(diffs, statusReportDiff{kind: removed, funcName: name})
+		default:
+			if changes := diffStatusReport(oldReport, newReport); len(changes) > 0 {
+				diffs = append(diffs, statusReportDiff{kind: changed, funcName: name, changes: changes})
+			}
+		}
+	}
+	return diffs
+}
+
+func diffStatusReport(oldReport, newReport *gather.GathererFunctionReport) []string {
+	var changes []string
+	if oldReport.RecordsCount != newReport.RecordsCount {
+		changes = append(changes, fmt.Sprintf("records: %d -> %d", oldReport.RecordsCount, newReport.RecordsCount))
+	}
+	changes = append(changes, diffMessages("error", oldReport.Errors, newReport.Errors)...)
+	changes = append(changes, diffMessages("warning", oldReport.Warnings, newReport.Warnings)...)
+	oldPanic, newPanic := fmt.Sprint(oldReport.Panic), fmt.Sprint(newReport.Panic)
+	switch {
+	case oldReport.Panic == nil && newReport.Panic != nil:
+		changes = append(changes, fmt.Sprintf("+ panic: %s", newPanic))
+	case oldReport.Panic != nil && newReport.Panic == nil:
+		changes = append(changes, fmt.Sprintf("- panic: %s", oldPanic))
+	case oldPanic != newPanic:
+		changes = append(changes, fmt.Sprintf("~ panic: %s -> %s", oldPanic, newPanic))
+	}
+	return changes
+}
+
+// diffMessages returns the removed and the added messages
+func diffMessages(kind string, oldMessages, newMessages []string) []string {
+	var changes []string
+	for _, message := range oldMessages {
+		if !slices.Contains(newMessages, message) {
+			changes = append(changes, fmt.Sprintf("- %s: %s", kind, message))
+		}
+	}
+	for _, message := range newMessages {
+		if !slices.Contains(oldMessages, message) {
+			changes = append(changes, fmt.Sprintf("+ %s: %s", kind, message))
+		}
+	}
+	return changes
+}
+
+func printDiff(out io.Writer, d *archiveDiff) error {
+	if len(d.added)+len(d.removed)+len(d.changed)+len(d.statusReports) == 0 {
+		_, err := fmt.Fprintln(out, "No differences found")
+		return err
+	}
+
+	if len(d.added) > 0 {
+		_, _ = fmt.Fprintf(out, "Added files (%d):\n", len(d.added))
+		for _, name := range d.added {
+			_, _ = fmt.Fprintf(out, "  %s %s\n", added, name)
+		}
+	}
+	if len(d.removed) > 0 {
+		_, _ = fmt.Fprintf(out, "Removed files (%d):\n", len(d.removed))
+		for _, name := range d.removed {
+			_, _ = fmt.Fprintf(out, "  %s %s\n", removed, name)
+		}
+	}
+	if len(d.changed) > 0 {
+		_, _ = fmt.Fprintf(out, "Changed files (%d):\n", len(d.changed))
+		for _, fd := range d.changed {
+			if len(fd.changes) == 0 {
+				_, _ = fmt.Fprintf(out, "  %s %s (%d -> %d bytes)\n", changed, fd.name, fd.oldSize, fd.newSize)
+				continue
+			}
+			_, _ = fmt.Fprintf(out, "  %s %s\n", changed, fd.name)
+			for _, c := range fd.changes {
+				_, _ = fmt.Fprintf(out, "      %s\n", c)
+			}
+		}
+	}
+	if len(d.statusReports) > 0 {
+		_, _ = fmt.Fprintf(out, "Gathering functions (%d):\n", len(d.statusReports))
+		for _, sd := range d.statusReports {
+			_, _ = fmt.Fprintf(out, "  %s %s\n", sd.kind, sd.funcName)
+			for _, c := range sd.changes {
+				_, _ = fmt.Fprintf(out, "      %s\n", c)
+			}
+		}
+	}
+	return nil
+}
+
+func (c valueChange) String() string {
+	path := c.path
+	if path == "" {
+		// the whole document has changed, e.g. from an object to an array
+		path = "."
+	}
+	switch c.kind {
+	case added:
+		return fmt.Sprintf("%s %s: %s", c.kind, path, formatValue(c.newValue))
+	case removed:
+		return fmt.Sprintf("%s %s: %s", c.kind, path, formatValue(c.oldValue))
+	default:
+		return fmt.Sprintf("%s %s: %s -> %s", c.kind, path, formatValue(c.oldValue), formatValue(c.newValue))
+	}
+}
+
+// formatValue returns the compact JSON of the value shortened to the maxValueLength
+func formatValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	if len(data) > maxValueLength {
+		return string(data[:maxValueLength]) + "..."
+	}
+	return string(data)
+}
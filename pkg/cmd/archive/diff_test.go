@@ -0,0 +1,144 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/insights-operator/pkg/record"
+	"github.com/openshift/insights-operator/pkg/recorder/diskrecorder"
+)
+
+func Test_diffFiles(t *testing.T) {
+	ignored := map[string]struct{}{"resourceVersion": {}, "managedFields": {}}
+
+	tests := []struct {
+		name            string
+		oldData         string
+		newData         string
+		expectedChanged bool
+		expectedChanges []string
+	}{
+		{
+			name:    "same content",
+			oldData: `{"a": 1}`,
+			newData: `{"a": 1}`,
+		},
+		{
+			name:    "only the ignored fields and the formatting changed",
+			oldData: `{"metadata": {"name": "a", "resourceVersion": "1", "managedFields": [{"manager": "x"}]}}`,
+			newData: `{
+				"metadata": {"resourceVersion": "2", "name": "a"}
+			}`,
+		},
+		{
+			name:            "changed, added and removed values",
+			oldData:         `{"metadata": {"labels": {"app": "a", "old": "x"}}, "status": {"conditions": [{"status": "True"}]}}`,
+			newData:         `{"metadata": {"labels": {"app": "b", "new": 1}}, "status": {"conditions": [{"status": "False"}, {}]}}`,
+			expectedChanged: true,
+			expectedChanges: []string{
+				`~ metadata.labels.app: "a" -> "b"`,
+				`+ metadata.labels.new: 1`,
+				`- metadata.labels.old: "x"`,
+				`~ status.conditions[0].status: "True" -> "False"`,
+				`+ status.conditions[1]: {}`,
+			},
+		},
+		{
+			name:            "keys with dots",
+			oldData:         `{"labels": {"kubernetes.io/os": "linux"}}`,
+			newData:         `{"labels": {"kubernetes.io/os": "windows"}}`,
+			expectedChanged: true,
+			expectedChanges: []string{`~ labels["kubernetes.io/os"]: "linux" -> "windows"`},
+		},
+		{
+			name:            "different types",
+			oldData:         `{"a": [1]}`,
+			newData:         `[1]`,
+			expectedChanged: true,
+			expectedChanges: []string{`~ .: {"a":[1]} -> [1]`},
+		},
+		{
+			name:            "not a JSON",
+			oldData:         "line 1\n",
+			newData:         "line 2\n",
+			expectedChanged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fd, isChanged := diffFiles("file", []byte(tt.oldData), []byte(tt.newData), ignored)
+			assert.Equal(t, tt.expectedChanged, isChanged)
+			var changes []string
+			for _, c := range fd.changes {
+				changes = append(changes, c.String())
+			}
+			assert.Equal(t, tt.expectedChanges, changes)
+		})
+	}
+}
+
+func Test_Archive_Diff(t *testing.T) {
+	writeArchive := func(name string, records record.MemoryRecords) string {
+		path := filepath.Join(t.TempDir(), name)
+		file, err := os.Create(path)
+		assert.NoError(t, err)
+		defer file.Close()
+		_, err = diskrecorder.WriteArchive(file, records)
+		assert.NoError(t, err)
+		return path
+	}
+
+	oldPath := writeArchive("old.tar.gz", record.MemoryRecords{
+		{Name: "config/node/master-0.json", Data: []byte(`{"metadata": {"resourceVersion": "1"}, "ready": true}`)},
+		{Name: "config/node/master-1.json", Data: []byte(`{"metadata": {"resourceVersion": "1"}, "ready": true}`)},
+		{Name: "config/removed.json", Data: []byte(`{}`)},
+		{Name: "config/pod/logs.log", Data: []byte("a")},
+		{Name: MetadataFileName, Data: []byte(`{"status_reports": [
+			{"name": "clusterconfig/nodes", "duration_in_ms": 10, "records_count": 2, "errors": null},
+			{"name": "clusterconfig/pod_logs", "duration_in_ms": 10, "records_count": 1, "errors": ["forbidden"]},
+			{"name": "clusterconfig/removed", "duration_in_ms": 10, "records_count": 1}
+		]}`)},
+	})
+	newPath := writeArchive("new.tar.gz", record.MemoryRecords{
+		{Name: "config/node/master-0.json", Data: []byte(`{"metadata": {"resourceVersion": "2"}, "ready": false}`)},
+		{Name: "config/node/master-1.json", Data: []byte(`{"metadata": {"resourceVersion": "2"}, "ready": true}`)},
+		{Name: "config/added.json", Data: []byte(`{}`)},
+		{Name: "config/pod/logs.log", Data: []byte("ab")},
+		{Name: MetadataFileName, Data: []byte(`{"status_reports": [
+			{"name": "clusterconfig/nodes", "duration_in_ms": 20, "records_count": 2, "errors": null},
+			{"name": "clusterconfig/pod_logs", "duration_in_ms": 10, "records_count": 0, "errors": ["timeout"],
+				"panic": "nil pointer"}
+		]}`)},
+	})
+
+	out, err := execute("diff", oldPath, newPath)
+	assert.NoError(t, err)
+	assert.Equal(t, `Added files (1):
+  + config/added.json
+Removed files (1):
+  - config/removed.json
+Changed files (2):
+  ~ config/node/master-0.json
+      ~ ready: true -> false
+  ~ config/pod/logs.log (1 -> 2 bytes)
+Gathering functions (2):
+  ~ clusterconfig/pod_logs
+      records: 1 -> 0
+      - error: forbidden
+      + error: timeout
+      + panic: nil pointer
+  - clusterconfig/removed
+`, out)
+
+	out, err = execute("diff", oldPath, oldPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "No differences found\n", out)
+
+	out, err = execute("diff", "--ignore-field", "ready,resourceVersion", oldPath, newPath)
+	assert.NoError(t, err)
+	assert.NotContains(t, out, "config/node/master-0.json")
+}
@@ -0,0 +1,44 @@
+package archive
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/insights-operator/pkg/archiveschema"
+	"github.com/openshift/insights-operator/pkg/record"
+	"github.com/openshift/insights-operator/pkg/recorder/diskrecorder"
+)
+
+func newValidate() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate ARCHIVE",
+		Short: "Check the files in the archive against the documented archive paths and their JSON schemas",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			records, err := diskrecorder.ReadArchive(args[0])
+			if err != nil {
+				return err
+			}
+			return validate(cmd.OutOrStdout(), records)
+		},
+	}
+}
+
+// validate prints all the issues found in the archive and returns an error when there is any
+func validate(out io.Writer, records map[string]*record.MemoryRecord) error {
+	validator, err := archiveschema.NewValidator()
+	if err != nil {
+		return err
+	}
+	issues := validator.Validate(records)
+	for _, issue := range issues {
+		fmt.Fprintln(out, issue)
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("the archive is not valid, %d issue(s) found", len(issues))
+	}
+	fmt.Fprintf(out, "The archive is valid, %d file(s) checked\n", len(records))
+	return nil
+}
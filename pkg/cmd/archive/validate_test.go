@@ -0,0 +1,18 @@
+package archive
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Archive_Validate(t *testing.T) {
+	path := createArchive(t)
+
+	out, err := execute("validate", path)
+	assert.EqualError(t, err, "the archive is not valid, 4 issue(s) found")
+	assert.Contains(t, out, "conditional/alerts/KubePodCrashLooping/logs.json: unknown_path: the path is not documented\n")
+	assert.Contains(t, out,
+		"config/workload_info.json: schema: (root): pods is required (schema workloads/workload_info.schema.json)\n")
+	assert.NotContains(t, out, "config/node/master-0.json")
+}
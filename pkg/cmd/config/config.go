@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	insightsconfig "github.com/openshift/insights-operator/pkg/config"
+)
+
+// configMapDataKey is the key of the "insights-config" config map holding the configuration
+const configMapDataKey = "config.yaml"
+
+// NewConfig creates the command for working with the "insights-config" config map
+func NewConfig() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Work with the insights-config configmap",
+	}
+
+	cmd.AddCommand(newValidate())
+
+	return cmd
+}
+
+func newValidate() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate FILE",
+		Short: "Check the insights-config configmap for unknown fields and invalid values before applying it",
+		Long: `Check the insights-config configmap for unknown fields and invalid values before applying it.
+
+The FILE is either the ConfigMap manifest or the content of its "config.yaml" key.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			return validate(cmd.OutOrStdout(), data)
+		},
+	}
+}
+
+// validate prints all the field errors found in the configuration and returns an error when there is any
+func validate(out io.Writer, data []byte) error {
+	configData, err := configFromManifest(data)
+	if err != nil {
+		return err
+	}
+	fieldErrs, err := insightsconfig.ValidateConfig(configData)
+	if err != nil {
+		return fmt.Errorf("cannot decode the configuration: %v", err)
+	}
+	for _, fieldErr := range fieldErrs {
+		fmt.Fprintln(out, fieldErr)
+	}
+	if len(fieldErrs) > 0 {
+		return fmt.Errorf("the configuration is not valid, %d issue(s) found", len(fieldErrs))
+	}
+	fmt.Fprintln(out, "The configuration is valid")
+	return nil
+}
+
+// configFromManifest returns the content of the "config.yaml" key when the data is a ConfigMap manifest,
+// otherwise the data is returned as it is
+func configFromManifest(data []byte) ([]byte, error) {
+	manifest := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("cannot decode the configuration: %v", err)
+	}
+	if manifest["kind"] != "ConfigMap" {
+		return data, nil
+	}
+	cmData, _ := manifest["data"].(map[string]interface{})
+	configData, ok := cmData[configMapDataKey].(string)
+	if !ok {
+		return nil, fmt.Errorf("the ConfigMap has no %q key", configMapDataKey)
+	}
+	return []byte(configData), nil
+}
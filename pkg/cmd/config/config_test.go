@@ -0,0 +1,68 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func execute(args ...string) (string, error) {
+	cmd := NewConfig()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func Test_Config_Validate(t *testing.T) {
+	path := writeFile(t, `
+dataReporting:
+  interval: 1h
+  obfuscation: networking
+sca:
+  disabled: true`)
+
+	out, err := execute("validate", path)
+	assert.NoError(t, err)
+	assert.Equal(t, "The configuration is valid\n", out)
+}
+
+func Test_Config_Validate_ConfigMap(t *testing.T) {
+	path := writeFile(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: insights-config
+  namespace: openshift-insights
+data:
+  config.yaml: |
+    dataReporting:
+      intervall: 1h
+      obfuscation: network
+    sca:
+      interval: 5`)
+
+	out, err := execute("validate", path)
+	assert.EqualError(t, err, "the configuration is not valid, 3 issue(s) found")
+	assert.Contains(t, out, "dataReporting.intervall: Unsupported value: \"intervall\"")
+	assert.Contains(t, out, "dataReporting.obfuscation: Unsupported value: \"network\": supported values: \"networking\", \"workload_names\"\n")
+	assert.Contains(t, out, "sca.interval: Invalid value: \"5\": time: missing unit in duration \"5\"\n")
+
+	path = writeFile(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: insights-config
+data: {}`)
+	_, err = execute("validate", path)
+	assert.EqualError(t, err, `the ConfigMap has no "config.yaml" key`)
+}
@@ -0,0 +1,172 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/insights-operator/pkg/gatherers/conditional"
+	"github.com/openshift/insights-operator/pkg/recorder/diskrecorder"
+)
+
+// NewRules creates the command for working with the conditional gathering rules
+func NewRules() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rules",
+		Short: "Work with the conditional gathering rules",
+	}
+
+	cmd.AddCommand(newSimulate())
+
+	return cmd
+}
+
+func newSimulate() *cobra.Command {
+	statePath := ""
+	archivePath := ""
+	asJSON := false
+	cmd := &cobra.Command{
+		Use:   "simulate CONFIG",
+		Short: "Evaluate the remote configuration against a snapshot of the cluster state without a live cluster",
+		Long: `Evaluate the remote configuration against a snapshot of the cluster state without a live cluster.
+
+The cluster state is read from the Insights archive (the cluster version, platform, feature gates,
+firing alerts and degraded cluster operators) and/or from the JSON file with the following structure.
+The values from the file override the values read from the archive.
+
+  {
+    "version": "4.17.0",
+    "platform": "AWS",
+    "firing_alerts": [{"alertname": "KubePodCrashLooping", "namespace": "openshift-monitoring", "pod": "pod"}],
+    "enabled_feature_gates": ["GatewayAPI"],
+    "degraded_operators": ["authentication"],
+    "metric_values": {"sum(up)": [3]},
+    "existing_resources": {"loki.grafana.com/v1/lokistacks/openshift-logging/": true}
+  }`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			remoteConfigData, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+
+			state := &conditional.ClusterState{}
+			if archivePath != "" {
+				records, err := diskrecorder.ReadArchive(archivePath)
+				if err != nil {
+					return err
+				}
+				state, err = clusterStateFromArchive(records)
+				if err != nil {
+					return err
+				}
+			}
+			if statePath != "" {
+				data, err := os.ReadFile(statePath)
+				if err != nil {
+					return err
+				}
+				if err := mergeClusterState(state, data); err != nil {
+					return fmt.Errorf("unable to read the cluster state: %v", err)
+				}
+			}
+
+			result, err := conditional.Simulate(remoteConfigData, state)
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				return printJSON(cmd.OutOrStdout(), result)
+			}
+			return printSimulationResult(cmd.OutOrStdout(), result)
+		},
+	}
+	cmd.Flags().StringVar(&statePath, "state", "", "JSON file with the snapshot of the cluster state")
+	cmd.Flags().StringVar(&archivePath, "archive", "", "Insights archive the cluster state is read from")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print the result as the indented JSON")
+
+	return cmd
+}
+
+// mergeClusterState overrides the cluster state with the values set in the JSON data
+func mergeClusterState(state *conditional.ClusterState, data []byte) error {
+	var override conditional.ClusterState
+	if err := json.Unmarshal(data, &override); err != nil {
+		return err
+	}
+
+	if override.Version != "" {
+		state.Version = override.Version
+	}
+	if override.Platform != "" {
+		state.Platform = override.Platform
+	}
+	if override.FiringAlerts != nil {
+		state.FiringAlerts = override.FiringAlerts
+	}
+	if override.EnabledFeatureGates != nil {
+		state.EnabledFeatureGates = override.EnabledFeatureGates
+	}
+	if override.DegradedOperators != nil {
+		state.DegradedOperators = override.DegradedOperators
+	}
+	if override.MetricValues != nil {
+		state.MetricValues = override.MetricValues
+	}
+	if override.ExistingResources != nil {
+		state.ExistingResources = override.ExistingResources
+	}
+	return nil
+}
+
+func printJSON(out io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
+
+func printSimulationResult(out io.Writer, result *conditional.SimulationResult) error {
+	_, _ = fmt.Fprintf(out, "Remote configuration version: %s\n", result.Version)
+
+	triggered := 0
+	for i := range result.Rules {
+		rule := &result.Rules[i]
+		state := "not triggered"
+		if rule.WasTriggered {
+			state = "triggered"
+			triggered++
+		}
+		_, _ = fmt.Fprintf(out, "\nRule %d: %s\n", i+1, state)
+		conditionTypes := make([]string, 0, len(rule.Rule.Conditions))
+		for j := range rule.Rule.Conditions {
+			conditionTypes = append(conditionTypes, string(rule.Rule.Conditions[j].Type))
+		}
+		_, _ = fmt.Fprintf(out, "  conditions: %s\n", strings.Join(conditionTypes, ", "))
+		for _, name := range rule.GatheringFunctions {
+			_, _ = fmt.Fprintf(out, "  function: %s\n", name)
+		}
+		for _, err := range rule.Errors {
+			_, _ = fmt.Fprintf(out, "  error: %s\n", err)
+		}
+	}
+
+	_, _ = fmt.Fprintf(out, "\nContainer log requests: %d\n", len(result.ContainerLogRequests))
+	for _, request := range result.ContainerLogRequests {
+		previous := ""
+		if request.Previous {
+			previous = " (previous)"
+		}
+		_, _ = fmt.Fprintf(out, "  %s/%s%s: %s\n",
+			request.Namespace, request.PodNameRegex, previous, strings.Join(request.Messages, ", "))
+	}
+
+	_, err := fmt.Fprintf(out, "\n%d of %d rule(s) triggered\n", triggered, len(result.Rules))
+	return err
+}
@@ -0,0 +1,115 @@
+package rules
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/insights-operator/pkg/gatherers/conditional"
+	"github.com/openshift/insights-operator/pkg/record"
+	"github.com/openshift/insights-operator/pkg/recorder/diskrecorder"
+)
+
+const testRemoteConfig = `{
+	"version": "1.1.0",
+	"conditional_gathering_rules": [
+		{
+			"conditions": [{"type": "alert_is_firing", "alert": {"name": "SamplesImagestreamImportFailing"}}],
+			"gathering_functions": {"logs_of_namespace": {"namespace": "openshift-cluster-samples-operator", "tail_lines": 100}}
+		},
+		{
+			"conditions": [{"type": "cluster_operator_degraded", "cluster_operator": {"name": "authentication"}}],
+			"gathering_functions": {"events_of_namespace": {"namespace": "openshift-authentication", "max_count": 10}}
+		}
+	],
+	"container_logs": [{"namespace": "openshift-etcd", "pod_name_regex": "etcd-.*", "messages": ["leader changed"]}]
+}`
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func createArchive(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "archive.tar.gz")
+	file, err := os.Create(path)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	_, err = diskrecorder.WriteArchive(file, record.MemoryRecords{
+		{Name: clusterVersionFile, At: at, Data: []byte(`{"status": {"desired": {"version": "4.17.3"}}}`)},
+		{Name: infrastructureFile, At: at, Data: []byte(`{"status": {"platformStatus": {"type": "AWS"}}}`)},
+		{Name: alertsFile, At: at, Data: []byte(`[
+			{"labels": {"alertname": "SamplesImagestreamImportFailing"}, "status": {"state": "active"}},
+			{"labels": {"alertname": "Watchdog"}, "status": {"state": "suppressed"}}
+		]`)},
+		{Name: "config/clusteroperator/authentication.json", At: at, Data: []byte(`{"metadata": {"name": "authentication"},
+			"status": {"conditions": [{"type": "Degraded", "status": "True"}]}}`)},
+		{Name: "config/clusteroperator/dns.json", At: at, Data: []byte(`{"metadata": {"name": "dns"},
+			"status": {"conditions": [{"type": "Degraded", "status": "False"}]}}`)},
+		{Name: "config/clusteroperator/operator.openshift.io/dns/default.json", At: at, Data: []byte(`{}`)},
+	})
+	assert.NoError(t, err)
+	return path
+}
+
+func execute(args ...string) (string, error) {
+	cmd := NewRules()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func Test_clusterStateFromArchive(t *testing.T) {
+	records, err := diskrecorder.ReadArchive(createArchive(t))
+	assert.NoError(t, err)
+
+	state, err := clusterStateFromArchive(records)
+	assert.NoError(t, err)
+	assert.Equal(t, &conditional.ClusterState{
+		Version:           "4.17.3",
+		Platform:          "AWS",
+		FiringAlerts:      []conditional.AlertLabels{{"alertname": "SamplesImagestreamImportFailing"}},
+		DegradedOperators: []string{"authentication"},
+	}, state)
+}
+
+func Test_Rules_Simulate(t *testing.T) {
+	configPath := writeFile(t, "config.json", testRemoteConfig)
+
+	out, err := execute("simulate", configPath, "--archive", createArchive(t))
+	assert.NoError(t, err)
+	assert.Equal(t, `Remote configuration version: 1.1.0
+
+Rule 1: triggered
+  conditions: alert_is_firing
+  function: conditional/logs_of_namespace/namespace=openshift-cluster-samples-operator,tail_lines=100
+
+Rule 2: triggered
+  conditions: cluster_operator_degraded
+  function: conditional/events_of_namespace/max_count=10,namespace=openshift-authentication
+
+Container log requests: 1
+  openshift-etcd/etcd-.*: leader changed
+
+2 of 2 rule(s) triggered
+`, out)
+
+	// the state file overrides the state from the archive
+	statePath := writeFile(t, "state.json", `{"firing_alerts": [], "degraded_operators": ["dns"]}`)
+	out, err = execute("simulate", configPath, "--archive", createArchive(t), "--state", statePath)
+	assert.NoError(t, err)
+	assert.Contains(t, out, "0 of 2 rule(s) triggered")
+
+	_, err = execute("simulate", writeFile(t, "config.json", `{"conditional_gathering_rules": [{"conditions": []}]}`))
+	assert.ErrorContains(t, err, "the remote configuration is not valid")
+}
@@ -0,0 +1,126 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	"github.com/openshift/insights-operator/pkg/gatherers/conditional"
+	"github.com/openshift/insights-operator/pkg/record"
+)
+
+const (
+	clusterVersionFile = "config/version.json"
+	infrastructureFile = "config/infrastructure.json"
+	featureGateFile    = "config/featuregate.json"
+	alertsFile         = "config/alerts.json"
+	clusterOperatorDir = "config/clusteroperator"
+)
+
+// clusterStateFromArchive reads the cluster state from the files of the Insights archive.
+// The missing files are skipped, so the corresponding parts of the state stay empty.
+func clusterStateFromArchive(records map[string]*record.MemoryRecord) (*conditional.ClusterState, error) {
+	state := &conditional.ClusterState{}
+
+	var clusterVersion configv1.ClusterVersion
+	found, err := unmarshalRecord(records, clusterVersionFile, &clusterVersion)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		state.Version = clusterVersion.Status.Desired.Version
+	}
+
+	var infrastructure configv1.Infrastructure
+	found, err = unmarshalRecord(records, infrastructureFile, &infrastructure)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		state.Platform = string(infrastructure.Status.Platform) //nolint:staticcheck
+		if infrastructure.Status.PlatformStatus != nil {
+			state.Platform = string(infrastructure.Status.PlatformStatus.Type)
+		}
+	}
+
+	var featureGate configv1.FeatureGate
+	found, err = unmarshalRecord(records, featureGateFile, &featureGate)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		for i := range featureGate.Status.FeatureGates {
+			details := &featureGate.Status.FeatureGates[i]
+			if details.Version != state.Version {
+				continue
+			}
+			for _, enabled := range details.Enabled {
+				state.EnabledFeatureGates = append(state.EnabledFeatureGates, string(enabled.Name))
+			}
+		}
+	}
+
+	// the alerts are gathered from the Alertmanager API
+	var alerts []struct {
+		Labels conditional.AlertLabels `json:"labels"`
+		Status struct {
+			State string `json:"state"`
+		} `json:"status"`
+	}
+	found, err = unmarshalRecord(records, alertsFile, &alerts)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		for _, alert := range alerts {
+			if alert.Status.State == "active" {
+				state.FiringAlerts = append(state.FiringAlerts, alert.Labels)
+			}
+		}
+	}
+
+	state.DegradedOperators, err = degradedOperatorsFromArchive(records)
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// degradedOperatorsFromArchive returns the sorted names of the degraded cluster operators
+func degradedOperatorsFromArchive(records map[string]*record.MemoryRecord) ([]string, error) {
+	var degraded []string
+	for name := range records {
+		// the related objects of the cluster operators are stored in the subdirectories
+		if path.Dir(name) != clusterOperatorDir || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		var clusterOperator configv1.ClusterOperator
+		if _, err := unmarshalRecord(records, name, &clusterOperator); err != nil {
+			return nil, err
+		}
+		for _, condition := range clusterOperator.Status.Conditions {
+			if condition.Type == configv1.OperatorDegraded && condition.Status == configv1.ConditionTrue {
+				degraded = append(degraded, clusterOperator.Name)
+			}
+		}
+	}
+	sort.Strings(degraded)
+	return degraded, nil
+}
+
+// unmarshalRecord unmarshals the data of the archive file and returns false when the file is not in the archive
+func unmarshalRecord(records map[string]*record.MemoryRecord, name string, v interface{}) (bool, error) {
+	r, ok := records[name]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(r.Data, v); err != nil {
+		return false, fmt.Errorf("unable to read %s: %v", name, err)
+	}
+	return true, nil
+}
@@ -3,9 +3,11 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/klog/v2"
 )
 
@@ -21,6 +23,10 @@ func (i *InsightsConfigurationSerialized) ToConfig() *InsightsConfiguration {
 			ConditionalGathererEndpoint: i.DataReporting.ConditionalGathererEndpoint,
 			ProcessingStatusEndpoint:    i.DataReporting.ProcessingStatusEndpoint,
 			Obfuscation:                 i.DataReporting.Obfuscation,
+			ObfuscationRules:            i.DataReporting.ObfuscationRules,
+			RemoteConfigPublicKeys:      i.DataReporting.RemoteConfigPublicKeys,
+			RemoteConfigVersion:         i.DataReporting.RemoteConfigVersion,
+			RemoteConfigMinVersion:      i.DataReporting.RemoteConfigMinVersion,
 		},
 		SCA: SCA{
 			Endpoint: i.SCA.Endpoint,
@@ -43,6 +49,26 @@ func (i *InsightsConfigurationSerialized) ToConfig() *InsightsConfiguration {
 		ic.DataReporting.DisableRuntimeExtractor = strings.EqualFold(i.DataReporting.DisableRuntimeExtractor, "true")
 	}
 
+	if i.DataReporting.NodeLogs.ContextBefore != "" {
+		ic.DataReporting.NodeLogs.ContextBefore = parseContextLines(i.DataReporting.NodeLogs.ContextBefore)
+	}
+
+	if i.DataReporting.NodeLogs.ContextAfter != "" {
+		ic.DataReporting.NodeLogs.ContextAfter = parseContextLines(i.DataReporting.NodeLogs.ContextAfter)
+	}
+
+	if i.DataReporting.NodeLogs.ContinuationLines != "" {
+		ic.DataReporting.NodeLogs.ContinuationLines = strings.EqualFold(i.DataReporting.NodeLogs.ContinuationLines, "true")
+	}
+
+	if len(i.DataReporting.GathererIntervals) > 0 {
+		ic.DataReporting.GathererIntervals = parseGathererIntervals(i.DataReporting.GathererIntervals)
+	}
+
+	if i.DataReporting.ConditionalLogsMaxBytes != "" {
+		ic.DataReporting.ConditionalLogsMaxBytes = parseLogsMaxBytes(i.DataReporting.ConditionalLogsMaxBytes)
+	}
+
 	if i.SCA.Interval != "" {
 		ic.SCA.Interval = parseInterval(i.SCA.Interval, defaultSCAFfrequency, 0)
 	}
@@ -86,6 +112,72 @@ func parseInterval(interval string, defaultValue, minIntervalValue time.Duration
 	return durationInt
 }
 
+// parseContextLines parses the number of the node log context lines.
+// If parsing fails or the value is negative, it returns 0.
+// If the value is greater than the maximum, it returns the maximum.
+func parseContextLines(lines string) int {
+	n, err := strconv.Atoi(lines)
+	if err != nil {
+		klog.Errorf("Cannot parse the number of the context lines: %v. Using default value 0", err)
+		return 0
+	}
+
+	if n < 0 {
+		klog.Warningf("Number of the context lines %d is below zero. Using default value 0.", n)
+		return 0
+	}
+
+	if n > maxNodeLogsContextLines {
+		klog.Warningf("Number of the context lines %d is above maximum %d. Using maximum.", n, maxNodeLogsContextLines)
+		return maxNodeLogsContextLines
+	}
+
+	return n
+}
+
+// parseLogsMaxBytes parses the log byte budget quantity (e.g. "8Mi").
+// If parsing fails, it returns 0, so the default budget is used.
+// If the value is out of the allowed range, it returns the closest allowed value.
+func parseLogsMaxBytes(value string) int64 {
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		klog.Errorf("Cannot parse the log byte budget: %v. Using default value.", err)
+		return 0
+	}
+
+	n := quantity.Value()
+	if n < minConditionalLogsMaxBytes {
+		klog.Warningf("Log byte budget %d is below minimum %d. Using minimum.", n, minConditionalLogsMaxBytes)
+		return minConditionalLogsMaxBytes
+	}
+
+	if n > maxConditionalLogsMaxBytes {
+		klog.Warningf("Log byte budget %d is above maximum %d. Using maximum.", n, maxConditionalLogsMaxBytes)
+		return maxConditionalLogsMaxBytes
+	}
+
+	return n
+}
+
+// parseGathererIntervals parses the intervals of the gatherers or the gathering functions.
+// The intervals which can't be parsed or are <= 0 are logged and ignored.
+func parseGathererIntervals(intervals map[string]string) map[string]time.Duration {
+	parsed := make(map[string]time.Duration, len(intervals))
+	for name, interval := range intervals {
+		duration, err := time.ParseDuration(interval)
+		if err != nil {
+			klog.Errorf("Cannot parse the interval of %s: %v. It will be gathered every time.", name, err)
+			continue
+		}
+		if duration <= 0 {
+			klog.Warningf("Interval %s of %s is below or equal to zero. It will be gathered every time.", duration, name)
+			continue
+		}
+		parsed[name] = duration
+	}
+	return parsed
+}
+
 // filterValidObfuscation filters obfuscation values and returns only
 // valid ones, invalid values are logged and ignored
 func filterValidObfuscation(vals []ObfuscationValue) []ObfuscationValue {
@@ -140,14 +232,20 @@ func (d *DataReporting) String() string {
 		downloadEndpoint: %s, 
 		conditionalGathererEndpoint: %s,
 		obfuscation: %s,
-		disableRuntimeExtractor: %t`,
+		obfuscationRules: %d,
+		disableRuntimeExtractor: %t,
+		nodeLogs: %+v,
+		gathererIntervals: %v`,
 		d.Interval,
 		d.UploadEndpoint,
 		d.StoragePath,
 		d.DownloadEndpoint,
 		d.ConditionalGathererEndpoint,
 		d.Obfuscation,
+		len(d.ObfuscationRules),
 		d.DisableRuntimeExtractor,
+		d.NodeLogs,
+		d.GathererIntervals,
 	)
 	return s
 }
@@ -58,6 +58,48 @@ func TestToConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "node logs context lines",
+			serializedConfig: InsightsConfigurationSerialized{
+				DataReporting: DataReportingSerialized{
+					NodeLogs: NodeLogsSerialized{
+						ContextBefore:     "3",
+						ContextAfter:      "100",
+						ContinuationLines: "true",
+					},
+				},
+			},
+			config: &InsightsConfiguration{
+				DataReporting: DataReporting{
+					NodeLogs: NodeLogs{
+						ContextBefore:     3,
+						ContextAfter:      maxNodeLogsContextLines,
+						ContinuationLines: true,
+					},
+				},
+			},
+		},
+		{
+			name: "gatherer intervals",
+			serializedConfig: InsightsConfigurationSerialized{
+				DataReporting: DataReportingSerialized{
+					GathererIntervals: map[string]string{
+						"workloads":               "24h",
+						"clusterconfig/node_logs": "6h",
+						"clusterconfig/nodes":     "often",
+						"clusterconfig/version":   "-1h",
+					},
+				},
+			},
+			config: &InsightsConfiguration{
+				DataReporting: DataReporting{
+					GathererIntervals: map[string]time.Duration{
+						"workloads":               24 * time.Hour,
+						"clusterconfig/node_logs": 6 * time.Hour,
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -68,6 +110,44 @@ func TestToConfig(t *testing.T) {
 	}
 }
 
+func TestParseContextLines(t *testing.T) {
+	tests := []struct {
+		name          string
+		linesString   string
+		expectedLines int
+	}{
+		{name: "meaningful value", linesString: "5", expectedLines: 5},
+		{name: "value cannot be parsed", linesString: "five", expectedLines: 0},
+		{name: "value is negative", linesString: "-5", expectedLines: 0},
+		{name: "value is above maximum", linesString: "50", expectedLines: maxNodeLogsContextLines},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedLines, parseContextLines(tt.linesString))
+		})
+	}
+}
+
+func TestParseLogsMaxBytes(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         string
+		expectedBytes int64
+	}{
+		{name: "meaningful value", value: "16Mi", expectedBytes: 16 * 1024 * 1024},
+		{name: "value cannot be parsed", value: "a lot", expectedBytes: 0},
+		{name: "value is below minimum", value: "1Ki", expectedBytes: minConditionalLogsMaxBytes},
+		{name: "value is above maximum", value: "1Gi", expectedBytes: maxConditionalLogsMaxBytes},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedBytes, parseLogsMaxBytes(tt.value))
+		})
+	}
+}
+
 func TestParseInterval(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -26,6 +26,7 @@ func LoadConfigFromSecret(secret *v1.Secret) (config.Controller, error) {
 
 	cfg.loadEndpoint(secret.Data)
 	cfg.loadConditionalGathererEndpoint(secret.Data)
+	cfg.loadRemoteConfigVersion(secret.Data)
 	cfg.loadHTTP(secret.Data)
 	cfg.loadReport(secret.Data)
 	cfg.loadOCM(secret.Data)
@@ -64,6 +65,15 @@ func (c *Config) loadConditionalGathererEndpoint(data map[string][]byte) {
 	}
 }
 
+func (c *Config) loadRemoteConfigVersion(data map[string][]byte) {
+	if version, ok := data["remoteConfigurationVersion"]; ok {
+		c.RemoteConfigVersion = string(version)
+	}
+	if minVersion, ok := data["remoteConfigurationMinVersion"]; ok {
+		c.RemoteConfigMinVersion = string(minVersion)
+	}
+}
+
 func (c *Config) loadHTTP(data map[string][]byte) {
 	if httpProxy, ok := data["httpProxy"]; ok {
 		c.HTTPConfig.HTTPProxy = string(httpProxy)
@@ -98,6 +98,7 @@ func (c *ConfigAggregator) mergeStatically() {
 		return
 	}
 
+	removeInvalidObfuscationRules(cmConf)
 	c.merge(conf, cmConf)
 }
 
@@ -147,6 +148,30 @@ func (c *ConfigAggregator) mergeDataReporting(defaultCfg, newCfg *config.Insight
 		defaultCfg.DataReporting.Obfuscation = append(defaultCfg.DataReporting.Obfuscation, newCfg.DataReporting.Obfuscation...)
 	}
 
+	if len(newCfg.DataReporting.ObfuscationRules) > 0 {
+		defaultCfg.DataReporting.ObfuscationRules = newCfg.DataReporting.ObfuscationRules
+	}
+
+	if newCfg.DataReporting.RemoteConfigPublicKeys != "" {
+		defaultCfg.DataReporting.RemoteConfigPublicKeys = newCfg.DataReporting.RemoteConfigPublicKeys
+	}
+
+	if newCfg.DataReporting.RemoteConfigVersion != "" {
+		defaultCfg.DataReporting.RemoteConfigVersion = newCfg.DataReporting.RemoteConfigVersion
+	}
+
+	if newCfg.DataReporting.RemoteConfigMinVersion != "" {
+		defaultCfg.DataReporting.RemoteConfigMinVersion = newCfg.DataReporting.RemoteConfigMinVersion
+	}
+
+	if newCfg.DataReporting.NodeLogs != (config.NodeLogs{}) {
+		defaultCfg.DataReporting.NodeLogs = newCfg.DataReporting.NodeLogs
+	}
+
+	if len(newCfg.DataReporting.GathererIntervals) > 0 {
+		defaultCfg.DataReporting.GathererIntervals = newCfg.DataReporting.GathererIntervals
+	}
+
 	if newCfg.DataReporting.DisableRuntimeExtractor != defaultCfg.DataReporting.DisableRuntimeExtractor {
 		defaultCfg.DataReporting.DisableRuntimeExtractor = newCfg.DataReporting.DisableRuntimeExtractor
 	}
@@ -275,6 +300,10 @@ func (c *ConfigAggregator) legacyConfigToInsightsConfiguration() *config.Insight
 			StoragePath:        legacyConfig.StoragePath,
 			ReportPullingDelay: legacyConfig.ReportPullingDelay,
 			Obfuscation:        obfuscation,
+			// the remote configuration version can be pinned in the support secret, because
+			// the InsightsDataGather API does not provide any field for it
+			RemoteConfigVersion:    legacyConfig.RemoteConfigVersion,
+			RemoteConfigMinVersion: legacyConfig.RemoteConfigMinVersion,
 		},
 		Alerting: config.Alerting{
 			Disabled: legacyConfig.DisableInsightsAlerts,
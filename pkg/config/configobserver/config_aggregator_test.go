@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/openshift/insights-operator/pkg/config"
+	"github.com/openshift/insights-operator/pkg/controllerstatus"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
@@ -76,8 +77,11 @@ dataReporting:
   processingStatusEndpoint: https://overriden.status/endpoint
   downloadEndpointTechPreview: https://overriden.downloadtechpreview/endpoint
   disableRuntimeExtractor: true
+  remoteConfigurationVersion: 1.2.0
   obfuscation:
   - workload_names
+  gathererIntervals:
+    clusterconfig/node_logs: 24h
 alerting:
   disabled: true
 sca:
@@ -99,6 +103,8 @@ clusterTransfer:
 				ReportEndpointTechPreview:   "http://downloadtpendpoint.here",
 				EnableGlobalObfuscation:     true,
 				DisableInsightsAlerts:       false,
+				RemoteConfigVersion:         "1.1.0",
+				RemoteConfigMinVersion:      "1.0.0",
 				OCMConfig: config.OCMConfig{
 					SCAInterval:             5 * time.Hour,
 					SCAEndpoint:             "test.sca.endpoint",
@@ -119,6 +125,9 @@ clusterTransfer:
 					DownloadEndpointTechPreview: "https://overriden.downloadtechpreview/endpoint",
 					Obfuscation:                 config.Obfuscation{config.Networking, config.WorkloadNames},
 					DisableRuntimeExtractor:     true,
+					RemoteConfigVersion:         "1.2.0",
+					RemoteConfigMinVersion:      "1.0.0",
+					GathererIntervals:           map[string]time.Duration{"clusterconfig/node_logs": 24 * time.Hour},
 				},
 				Alerting: config.Alerting{
 					Disabled: true,
@@ -493,3 +502,7 @@ func (m *MockConfigMapInformer) Config() *config.InsightsConfiguration {
 func (m *MockConfigMapInformer) ConfigChanged() (configCh <-chan struct{}, closeFn func()) {
 	return nil, nil
 }
+
+func (m *MockConfigMapInformer) Sources() []controllerstatus.StatusController {
+	return nil
+}
@@ -37,6 +37,38 @@ func TestConfig_loadEndpoint(t *testing.T) {
 	}
 }
 
+func TestConfig_loadRemoteConfigVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		data map[string][]byte
+		want *Config
+	}{
+		{
+			name: "Load pinned and minimum remote configuration version",
+			data: map[string][]byte{
+				"remoteConfigurationVersion":    []byte("1.2.0"),
+				"remoteConfigurationMinVersion": []byte("1.1.0"),
+			},
+			want: &Config{Controller: config.Controller{
+				RemoteConfigVersion:    "1.2.0",
+				RemoteConfigMinVersion: "1.1.0",
+			}},
+		},
+		{
+			name: "Remote configuration version is not set",
+			data: map[string][]byte{"endpoint": []byte("http://endpoint")},
+			want: &Config{Controller: config.Controller{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &Config{Controller: config.Controller{}}
+			got.loadRemoteConfigVersion(tt.data)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestConfig_loadHTTP(t *testing.T) {
 	tests := []struct {
 		name string
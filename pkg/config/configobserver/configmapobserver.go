@@ -2,16 +2,20 @@ package configobserver
 
 import (
 	"context"
+	"fmt"
 	"reflect"
+	"strings"
 	"sync"
 
 	"github.com/openshift/insights-operator/pkg/config"
+	"github.com/openshift/insights-operator/pkg/controllerstatus"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -20,6 +24,12 @@ import (
 
 const (
 	insightsConfigMapName = "insights-config"
+	// ConfigValidatorName is the name of the status source reporting the result of the strict configuration validation
+	ConfigValidatorName = "configValidator"
+	// ConfigurationValidReason is the reason reported when the configuration passes the strict validation
+	ConfigurationValidReason = "AsExpected"
+	// ConfigurationInvalidReason is the reason reported when the configuration doesn't pass the strict validation
+	ConfigurationInvalidReason = "InvalidConfiguration"
 )
 
 type ConfigMapInformer interface {
@@ -28,6 +38,8 @@ type ConfigMapInformer interface {
 	Config() *config.InsightsConfiguration
 	// ConfigChanged notifies all the listeners that the content of the "insights-config" configmap has changed
 	ConfigChanged() (<-chan struct{}, func())
+	// Sources provides the status sources reporting the validity of the "insights-config" configmap
+	Sources() []controllerstatus.StatusController
 }
 
 // ConfigMapObserver is a controller for "insights-config" config map
@@ -38,6 +50,7 @@ type ConfigMapObserver struct {
 	kubeCli        *kubernetes.Clientset
 	insightsConfig *config.InsightsConfiguration
 	listeners      map[chan struct{}]struct{}
+	validatorCtrl  controllerstatus.StatusController
 }
 
 func NewConfigMapObserver(ctx context.Context, kubeConfig *rest.Config,
@@ -54,22 +67,26 @@ func NewConfigMapObserver(ctx context.Context, kubeConfig *rest.Config,
 		kubeCli:        kubeClient,
 		insightsConfig: nil,
 		listeners:      make(map[chan struct{}]struct{}),
+		validatorCtrl:  controllerstatus.New(ConfigValidatorName),
 	}
 	factoryCtrl := factory.New().WithInformers(cmInformer).
 		WithSync(ctrl.sync).
 		ToController("ConfigController", eventRecorder)
 
 	ctrl.Controller = factoryCtrl
+	ctrl.updateConfigurationStatus(nil, nil)
 	cm, err := getConfigMap(ctx, kubeClient)
 	if err != nil {
 		klog.Warningf("Cannot get the configuration config map: %v. Default configuration is used.", err)
 		return ctrl, nil
 	}
+	ctrl.updateConfigurationStatus(config.ValidateConfig([]byte(cm.Data["config.yaml"])))
 	insightsConfig, err := readConfigAndDecode(cm)
 	if err != nil {
 		klog.Warningf("Failed to read the configuration during start: %v. Default configuration is used.", err)
 		return ctrl, nil
 	}
+	removeInvalidObfuscationRules(insightsConfig)
 	ctrl.insightsConfig = insightsConfig
 	return ctrl, nil
 }
@@ -88,13 +105,16 @@ func (c *ConfigMapObserver) sync(ctx context.Context, _ factory.SyncContext) err
 			c.insightsConfig = nil
 			c.notifyListeners()
 		}
+		c.updateConfigurationStatus(nil, nil)
 		return nil
 	}
 
+	c.updateConfigurationStatus(config.ValidateConfig([]byte(cm.Data["config.yaml"])))
 	insightsConfig, err := readConfigAndDecode(cm)
 	if err != nil {
 		return err
 	}
+	removeInvalidObfuscationRules(insightsConfig)
 
 	// config hasn't change - do nothing
 	if reflect.DeepEqual(c.insightsConfig, insightsConfig) {
@@ -120,6 +140,38 @@ func (c *ConfigMapObserver) Config() *config.InsightsConfiguration {
 	return c.insightsConfig
 }
 
+// Sources provides the status controllers reporting the validity of the configuration
+func (c *ConfigMapObserver) Sources() []controllerstatus.StatusController {
+	return []controllerstatus.StatusController{c.validatorCtrl}
+}
+
+// updateConfigurationStatus updates the status of the observer based on the result of the strict
+// configuration validation. The decodingErr is set when the configuration can't be decoded at all.
+func (c *ConfigMapObserver) updateConfigurationStatus(fieldErrs field.ErrorList, decodingErr error) {
+	if decodingErr == nil && len(fieldErrs) == 0 {
+		c.validatorCtrl.UpdateStatus(controllerstatus.Summary{
+			Healthy: true,
+			Reason:  ConfigurationValidReason,
+		})
+		return
+	}
+
+	details := make([]string, 0, len(fieldErrs)+1)
+	if decodingErr != nil {
+		details = append(details, fmt.Sprintf("cannot decode the configuration: %v", decodingErr))
+	}
+	for _, fieldErr := range fieldErrs {
+		details = append(details, fieldErr.Error())
+	}
+	message := fmt.Sprintf("The %s configmap is not valid: %s", insightsConfigMapName, strings.Join(details, "; "))
+	klog.Warning(message)
+	c.validatorCtrl.UpdateStatus(controllerstatus.Summary{
+		Healthy: false,
+		Reason:  ConfigurationInvalidReason,
+		Message: message,
+	})
+}
+
 func (c *ConfigMapObserver) ConfigChanged() (configCh <-chan struct{}, closeFn func()) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
@@ -145,6 +197,17 @@ func readConfigAndDecode(cm *v1.ConfigMap) (*config.InsightsConfiguration, error
 	return insightsConfig.ToConfig(), nil
 }
 
+// removeInvalidObfuscationRules removes the invalid obfuscation rules from the decoded configuration,
+// so that the valid ones are still applied. The invalid rules are reported by the strict validation.
+func removeInvalidObfuscationRules(insightsConfig *config.InsightsConfiguration) {
+	validRules, err := config.ValidateObfuscationRules(insightsConfig.DataReporting.ObfuscationRules)
+	if err != nil {
+		klog.Warningf("The %s configmap contains invalid obfuscation rules, they will be ignored: %v",
+			insightsConfigMapName, err)
+	}
+	insightsConfig.DataReporting.ObfuscationRules = validRules
+}
+
 func getConfigMap(ctx context.Context, kubeCli *kubernetes.Clientset) (*v1.ConfigMap, error) {
 	return kubeCli.CoreV1().ConfigMaps(insightsNamespaceName).Get(ctx, insightsConfigMapName, metav1.GetOptions{})
 }
@@ -0,0 +1,61 @@
+package configobserver
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/openshift/insights-operator/pkg/controllerstatus"
+)
+
+func Test_ConfigMapObserver_updateConfigurationStatus(t *testing.T) {
+	tests := []struct {
+		name            string
+		fieldErrs       field.ErrorList
+		decodingErr     error
+		expectedSummary controllerstatus.Summary
+	}{
+		{
+			name:            "valid configuration",
+			expectedSummary: controllerstatus.Summary{Healthy: true, Reason: ConfigurationValidReason},
+		},
+		{
+			name: "field errors",
+			fieldErrs: field.ErrorList{
+				field.NotSupported(field.NewPath("dataReporting", "intervall"), "intervall", []string{"interval"}),
+				field.Invalid(field.NewPath("sca", "interval"), "5", "must be at least 10m0s"),
+			},
+			expectedSummary: controllerstatus.Summary{
+				Healthy: false,
+				Reason:  ConfigurationInvalidReason,
+				Message: `The insights-config configmap is not valid: ` +
+					`dataReporting.intervall: Unsupported value: "intervall": supported values: "interval"; ` +
+					`sca.interval: Invalid value: "5": must be at least 10m0s`,
+			},
+		},
+		{
+			name:        "decoding error",
+			decodingErr: fmt.Errorf("yaml: line 1: did not find expected node content"),
+			expectedSummary: controllerstatus.Summary{
+				Healthy: false,
+				Reason:  ConfigurationInvalidReason,
+				Message: `The insights-config configmap is not valid: ` +
+					`cannot decode the configuration: yaml: line 1: did not find expected node content`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observer := &ConfigMapObserver{validatorCtrl: controllerstatus.New(ConfigValidatorName)}
+			observer.updateConfigurationStatus(tt.fieldErrs, tt.decodingErr)
+
+			summary, ok := observer.validatorCtrl.CurrentStatus()
+			assert.True(t, ok)
+			assert.Equal(t, tt.expectedSummary.Healthy, summary.Healthy)
+			assert.Equal(t, tt.expectedSummary.Reason, summary.Reason)
+			assert.Equal(t, tt.expectedSummary.Message, summary.Message)
+		})
+	}
+}
@@ -63,6 +63,11 @@ type Controller struct {
 	// DisableInsightsAlerts disabled exposing of Insights recommendations as Prometheus info alerts
 	DisableInsightsAlerts    bool
 	ProcessingStatusEndpoint string
+
+	// RemoteConfigVersion is the only version of the remote configuration accepted by the conditional gatherer
+	RemoteConfigVersion string
+	// RemoteConfigMinVersion is the minimum version of the remote configuration accepted by the conditional gatherer
+	RemoteConfigMinVersion string
 }
 
 // HTTPConfig configures http proxy and exception settings if they come from config
@@ -116,6 +121,7 @@ func (c *Controller) MergeWith(cfg *Controller) {
 	c.mergeHTTP(cfg)
 	c.mergeProcessingStatusEndpoint(cfg)
 	c.mergeReportEndpointTechPreview(cfg)
+	c.mergeRemoteConfigVersion(cfg)
 }
 
 func (c *Controller) mergeEndpoint(cfg *Controller) {
@@ -136,6 +142,15 @@ func (c *Controller) mergeReportEndpointTechPreview(cfg *Controller) {
 	}
 }
 
+func (c *Controller) mergeRemoteConfigVersion(cfg *Controller) {
+	if len(cfg.RemoteConfigVersion) > 0 {
+		c.RemoteConfigVersion = cfg.RemoteConfigVersion
+	}
+	if len(cfg.RemoteConfigMinVersion) > 0 {
+		c.RemoteConfigMinVersion = cfg.RemoteConfigMinVersion
+	}
+}
+
 func (c *Controller) mergeConditionalGathererEndpoint(cfg *Controller) {
 	if len(cfg.ConditionalGathererEndpoint) > 0 {
 		c.ConditionalGathererEndpoint = cfg.ConditionalGathererEndpoint
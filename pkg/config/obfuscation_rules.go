@@ -0,0 +1,79 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// LiteralRule replaces all the occurrences of the exact value with the placeholder
+	LiteralRule ObfuscationRuleType = "literal"
+	// RegexRule replaces all the matches of the regular expression with the placeholder
+	RegexRule ObfuscationRuleType = "regex"
+)
+
+type ObfuscationRuleType string
+
+// ObfuscationRule is a user defined obfuscation rule. All the values
+// matching the rule are replaced by the placeholder in the gathered data.
+type ObfuscationRule struct {
+	Type        ObfuscationRuleType `json:"type"`
+	Value       string              `json:"value"`
+	Placeholder string              `json:"placeholder"`
+}
+
+// Validate checks that the rule has a known type, non-empty value and placeholder
+// and in case of the regex rule that the expression compiles and doesn't match an empty string
+func (r *ObfuscationRule) Validate() error {
+	if strings.TrimSpace(r.Value) == "" {
+		return fmt.Errorf("value must not be empty")
+	}
+	if strings.TrimSpace(r.Placeholder) == "" {
+		return fmt.Errorf("placeholder must not be empty")
+	}
+
+	switch r.Type {
+	case LiteralRule:
+		return nil
+	case RegexRule:
+		re, err := regexp.Compile(r.Value)
+		if err != nil {
+			return fmt.Errorf("value %q is not a valid regular expression: %v", r.Value, err)
+		}
+		if re.MatchString("") {
+			return fmt.Errorf("regular expression %q must not match an empty string", r.Value)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown type %q (valid values: %q, %q)", r.Type, LiteralRule, RegexRule)
+	}
+}
+
+// Compile returns the regular expression matching all the values
+// that should be replaced by the rule placeholder
+func (r *ObfuscationRule) Compile() (*regexp.Regexp, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	if r.Type == LiteralRule {
+		return regexp.MustCompile(regexp.QuoteMeta(strings.TrimSpace(r.Value))), nil
+	}
+	return regexp.MustCompile(r.Value), nil
+}
+
+// ValidateObfuscationRules validates all the provided rules and returns only the valid ones.
+// The returned error aggregates the validation errors of all the invalid rules.
+func ValidateObfuscationRules(rules []ObfuscationRule) ([]ObfuscationRule, error) {
+	var validRules []ObfuscationRule
+	var errs []error
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("obfuscationRules[%d]: %v", i, err))
+			continue
+		}
+		validRules = append(validRules, rules[i])
+	}
+	return validRules, errors.Join(errs...)
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+func Test_ValidateObfuscationRules(t *testing.T) {
+	tests := []struct {
+		name          string
+		rules         []ObfuscationRule
+		expectedRules []ObfuscationRule
+		expectedError string
+	}{
+		{
+			name: "valid literal and regex rules",
+			rules: []ObfuscationRule{
+				{Type: LiteralRule, Value: "internal.corp", Placeholder: "<INTERNAL>"},
+				{Type: RegexRule, Value: `PRJ-[0-9]+`, Placeholder: "<PROJECT>"},
+			},
+			expectedRules: []ObfuscationRule{
+				{Type: LiteralRule, Value: "internal.corp", Placeholder: "<INTERNAL>"},
+				{Type: RegexRule, Value: `PRJ-[0-9]+`, Placeholder: "<PROJECT>"},
+			},
+		},
+		{
+			name: "invalid rules are filtered out and all the errors are reported",
+			rules: []ObfuscationRule{
+				{Type: "glob", Value: "*.corp", Placeholder: "<INTERNAL>"},
+				{Type: LiteralRule, Value: "internal.corp", Placeholder: "<INTERNAL>"},
+				{Type: RegexRule, Value: `PRJ-[0-9+`, Placeholder: "<PROJECT>"},
+				{Type: RegexRule, Value: `.*`, Placeholder: "<ALL>"},
+				{Type: LiteralRule, Value: " ", Placeholder: "<EMPTY>"},
+				{Type: LiteralRule, Value: "secret"},
+			},
+			expectedRules: []ObfuscationRule{
+				{Type: LiteralRule, Value: "internal.corp", Placeholder: "<INTERNAL>"},
+			},
+			expectedError: `obfuscationRules[0]: unknown type "glob" (valid values: "literal", "regex")
+obfuscationRules[2]: value "PRJ-[0-9+" is not a valid regular expression: error parsing regexp: missing closing ]: ` +
+				"`[0-9+`" + `
+obfuscationRules[3]: regular expression ".*" must not match an empty string
+obfuscationRules[4]: value must not be empty
+obfuscationRules[5]: placeholder must not be empty`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := ValidateObfuscationRules(tt.rules)
+			assert.Equal(t, tt.expectedRules, rules)
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func Test_ObfuscationRulesUnmarshalYAML(t *testing.T) {
+	input := `
+obfuscationRules:
+  - type: literal
+    value: internal.corp
+    placeholder: <INTERNAL>
+  - type: regex
+    value: ACC-[0-9]{6}
+    placeholder: <ACCOUNT_ID>
+`
+	var dataReporting DataReportingSerialized
+	err := yaml.Unmarshal([]byte(input), &dataReporting)
+	assert.NoError(t, err)
+	assert.Equal(t, []ObfuscationRule{
+		{Type: LiteralRule, Value: "internal.corp", Placeholder: "<INTERNAL>"},
+		{Type: RegexRule, Value: "ACC-[0-9]{6}", Placeholder: "<ACCOUNT_ID>"},
+	}, dataReporting.ObfuscationRules)
+}
@@ -13,6 +13,12 @@ const (
 	defaultSCAFfrequency = 8 * time.Hour
 	// defines default frequency of the Cluster Transfer download
 	defaultClusterTransferFrequency = 12 * time.Hour
+	// defines maximum number of the node log lines kept before or after a matching line,
+	// it must match the maximum of the context lines in the conditional container_log.schema.json
+	maxNodeLogsContextLines = 20
+	// defines minimum and maximum log byte budget shared by the conditional log gathering functions
+	minConditionalLogsMaxBytes = 1024 * 1024
+	maxConditionalLogsMaxBytes = 64 * 1024 * 1024
 )
 
 // InsightsConfigurationSerialized is a type representing Insights
@@ -27,15 +33,28 @@ type InsightsConfigurationSerialized struct {
 }
 
 type DataReportingSerialized struct {
-	Interval                    string      `json:"interval,omitempty"`
-	UploadEndpoint              string      `json:"uploadEndpoint,omitempty"`
-	DownloadEndpoint            string      `json:"downloadEndpoint,omitempty"`
-	DownloadEndpointTechPreview string      `json:"downloadEndpointTechPreview,omitempty"`
-	StoragePath                 string      `json:"storagePath,omitempty"`
-	ConditionalGathererEndpoint string      `json:"conditionalGathererEndpoint,omitempty"`
-	ProcessingStatusEndpoint    string      `json:"processingStatusEndpoint,omitempty"`
-	Obfuscation                 Obfuscation `json:"obfuscation,omitempty"`
-	DisableRuntimeExtractor     string      `json:"disableRuntimeExtractor,omitempty"`
+	Interval                    string             `json:"interval,omitempty"`
+	UploadEndpoint              string             `json:"uploadEndpoint,omitempty"`
+	DownloadEndpoint            string             `json:"downloadEndpoint,omitempty"`
+	DownloadEndpointTechPreview string             `json:"downloadEndpointTechPreview,omitempty"`
+	StoragePath                 string             `json:"storagePath,omitempty"`
+	ConditionalGathererEndpoint string             `json:"conditionalGathererEndpoint,omitempty"`
+	ProcessingStatusEndpoint    string             `json:"processingStatusEndpoint,omitempty"`
+	Obfuscation                 Obfuscation        `json:"obfuscation,omitempty"`
+	ObfuscationRules            []ObfuscationRule  `json:"obfuscationRules,omitempty"`
+	DisableRuntimeExtractor     string             `json:"disableRuntimeExtractor,omitempty"`
+	RemoteConfigPublicKeys      string             `json:"remoteConfigurationPublicKeys,omitempty"`
+	RemoteConfigVersion         string             `json:"remoteConfigurationVersion,omitempty"`
+	RemoteConfigMinVersion      string             `json:"remoteConfigurationMinVersion,omitempty"`
+	NodeLogs                    NodeLogsSerialized `json:"nodeLogs,omitempty"`
+	GathererIntervals           map[string]string  `json:"gathererIntervals,omitempty"`
+	ConditionalLogsMaxBytes     string             `json:"conditionalLogsMaxBytes,omitempty"`
+}
+
+type NodeLogsSerialized struct {
+	ContextBefore     string `json:"contextBefore,omitempty"`
+	ContextAfter      string `json:"contextAfter,omitempty"`
+	ContinuationLines string `json:"continuationLines,omitempty"`
 }
 
 type AlertingSerialized struct {
@@ -84,7 +103,27 @@ type DataReporting struct {
 	ReportPullingDelay          time.Duration
 	ProcessingStatusEndpoint    string
 	Obfuscation                 Obfuscation
+	ObfuscationRules            []ObfuscationRule
 	DisableRuntimeExtractor     bool
+	RemoteConfigPublicKeys      string
+	RemoteConfigVersion         string
+	RemoteConfigMinVersion      string
+	NodeLogs                    NodeLogs
+	// GathererIntervals are the minimum intervals between the runs of the gatherers or the gathering functions
+	// keyed by the gatherer name (e.g. "workloads") or by the full function name (e.g. "clusterconfig/node_logs"),
+	// they apply only to the periodic gathering in the operator process, not to the gathering jobs
+	GathererIntervals map[string]time.Duration
+	// ConditionalLogsMaxBytes is the log byte budget shared by all the conditional log gathering functions
+	// of one gathering, the default budget is used when it is zero
+	ConditionalLogsMaxBytes int64
+}
+
+// NodeLogs is a helper type for configuring the lines
+// kept around the matching lines of the control plane node logs
+type NodeLogs struct {
+	ContextBefore     int
+	ContextAfter      int
+	ContinuationLines bool
 }
 
 // Alerting is a helper type for configuring Insights alerting
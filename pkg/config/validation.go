@@ -0,0 +1,300 @@
+package config
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// endpointPlaceholder is the placeholder the endpoints may contain, e.g. for the cluster ID
+const endpointPlaceholder = "%s"
+
+// gathererNameRegex matches the gatherer name or the full gathering function name
+var gathererNameRegex = regexp.MustCompile(`^[a-z0-9_-]+(/[a-z0-9_-]+)?$`)
+
+// ValidateConfig strictly validates the content of the "config.yaml" key of the "insights-config" config map.
+// Unlike the decoding, which ignores the unknown fields and replaces the invalid values with the defaults,
+// it collects all the field errors. The returned error is set only when the content can't be decoded at all.
+func ValidateConfig(data []byte) (field.ErrorList, error) {
+	raw := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	serialized := &InsightsConfigurationSerialized{}
+	if err := yaml.Unmarshal(data, serialized); err != nil {
+		return nil, err
+	}
+
+	allErrs := validateKnownFields(nil, raw, reflect.TypeOf(*serialized))
+	allErrs = append(allErrs, validateDataReporting(field.NewPath("dataReporting"), &serialized.DataReporting, raw)...)
+
+	alertingPath := field.NewPath("alerting")
+	allErrs = append(allErrs, validateBool(alertingPath.Child("disabled"), serialized.Alerting.Disabled)...)
+
+	scaPath := field.NewPath("sca")
+	allErrs = append(allErrs, validateBool(scaPath.Child("disabled"), serialized.SCA.Disabled)...)
+	allErrs = append(allErrs, validateInterval(scaPath.Child("interval"), serialized.SCA.Interval, 0)...)
+	allErrs = append(allErrs, validateURL(scaPath.Child("endpoint"), serialized.SCA.Endpoint)...)
+
+	clusterTransferPath := field.NewPath("clusterTransfer")
+	allErrs = append(allErrs, validateInterval(clusterTransferPath.Child("interval"), serialized.ClusterTransfer.Interval, 0)...)
+	allErrs = append(allErrs, validateURL(clusterTransferPath.Child("endpoint"), serialized.ClusterTransfer.Endpoint)...)
+
+	proxyPath := field.NewPath("proxy")
+	allErrs = append(allErrs, validateURL(proxyPath.Child("httpProxy"), serialized.Proxy.HTTPProxy)...)
+	allErrs = append(allErrs, validateURL(proxyPath.Child("httpsProxy"), serialized.Proxy.HTTPSProxy)...)
+
+	return allErrs, nil
+}
+
+func validateDataReporting(fldPath *field.Path, d *DataReportingSerialized, raw map[string]interface{}) field.ErrorList {
+	allErrs := validateInterval(fldPath.Child("interval"), d.Interval, minimumGatherFrequency)
+	allErrs = append(allErrs, validateURL(fldPath.Child("uploadEndpoint"), d.UploadEndpoint)...)
+	allErrs = append(allErrs, validateURL(fldPath.Child("downloadEndpoint"), d.DownloadEndpoint)...)
+	allErrs = append(allErrs, validateURL(fldPath.Child("downloadEndpointTechPreview"), d.DownloadEndpointTechPreview)...)
+	allErrs = append(allErrs, validateURL(fldPath.Child("conditionalGathererEndpoint"), d.ConditionalGathererEndpoint)...)
+	allErrs = append(allErrs, validateURL(fldPath.Child("processingStatusEndpoint"), d.ProcessingStatusEndpoint)...)
+	if d.StoragePath != "" && !filepath.IsAbs(d.StoragePath) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("storagePath"), d.StoragePath, "must be an absolute path"))
+	}
+
+	// the invalid obfuscation values are already filtered out by the decoding, so the raw values are checked
+	if rawDataReporting, ok := raw["dataReporting"].(map[string]interface{}); ok {
+		allErrs = append(allErrs, validateObfuscation(fldPath.Child("obfuscation"), rawDataReporting["obfuscation"])...)
+	}
+	for i := range d.ObfuscationRules {
+		if err := d.ObfuscationRules[i].Validate(); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("obfuscationRules").Index(i), d.ObfuscationRules[i].Value, err.Error()))
+		}
+	}
+
+	allErrs = append(allErrs, validateBool(fldPath.Child("disableRuntimeExtractor"), d.DisableRuntimeExtractor)...)
+	allErrs = append(allErrs, validatePublicKeys(fldPath.Child("remoteConfigurationPublicKeys"), d.RemoteConfigPublicKeys)...)
+	allErrs = append(allErrs, validateVersion(fldPath.Child("remoteConfigurationVersion"), d.RemoteConfigVersion)...)
+	allErrs = append(allErrs, validateVersion(fldPath.Child("remoteConfigurationMinVersion"), d.RemoteConfigMinVersion)...)
+
+	nodeLogsPath := fldPath.Child("nodeLogs")
+	allErrs = append(allErrs, validateContextLines(nodeLogsPath.Child("contextBefore"), d.NodeLogs.ContextBefore)...)
+	allErrs = append(allErrs, validateContextLines(nodeLogsPath.Child("contextAfter"), d.NodeLogs.ContextAfter)...)
+	allErrs = append(allErrs, validateBool(nodeLogsPath.Child("continuationLines"), d.NodeLogs.ContinuationLines)...)
+
+	gathererIntervalsPath := fldPath.Child("gathererIntervals")
+	names := make([]string, 0, len(d.GathererIntervals))
+	for name := range d.GathererIntervals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		interval := d.GathererIntervals[name]
+		if !gathererNameRegex.MatchString(name) {
+			allErrs = append(allErrs, field.Invalid(gathererIntervalsPath, name,
+				`must be a gatherer name (e.g. "workloads") or a gathering function name (e.g. "clusterconfig/node_logs")`))
+			continue
+		}
+		if interval == "" {
+			allErrs = append(allErrs, field.Required(gathererIntervalsPath.Key(name), "the interval must be set"))
+			continue
+		}
+		allErrs = append(allErrs, validateInterval(gathererIntervalsPath.Key(name), interval, 0)...)
+	}
+
+	allErrs = append(allErrs, validateLogsMaxBytes(fldPath.Child("conditionalLogsMaxBytes"), d.ConditionalLogsMaxBytes)...)
+	return allErrs
+}
+
+// validateKnownFields reports all the fields of the raw value not known by the type (typically typos),
+// the nested objects and the lists of the objects are checked recursively
+func validateKnownFields(fldPath *field.Path, raw interface{}, t reflect.Type) field.ErrorList {
+	var allErrs field.ErrorList
+	switch t.Kind() {
+	case reflect.Struct:
+		rawMap, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		knownFields := make(map[string]reflect.Type)
+		var knownNames []string
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+			knownFields[name] = t.Field(i).Type
+			knownNames = append(knownNames, name)
+		}
+
+		keys := make([]string, 0, len(rawMap))
+		for key := range rawMap {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fieldType, ok := knownFields[key]
+			if !ok {
+				allErrs = append(allErrs, field.NotSupported(fldPath.Child(key), key, knownNames))
+				continue
+			}
+			allErrs = append(allErrs, validateKnownFields(fldPath.Child(key), rawMap[key], fieldType)...)
+		}
+	case reflect.Slice:
+		rawList, ok := raw.([]interface{})
+		if !ok {
+			return nil
+		}
+		for i := range rawList {
+			allErrs = append(allErrs, validateKnownFields(fldPath.Index(i), rawList[i], t.Elem())...)
+		}
+	}
+	return allErrs
+}
+
+// validateObfuscation checks that the obfuscation is a single value or a list of the known values
+func validateObfuscation(fldPath *field.Path, raw interface{}) field.ErrorList {
+	validValues := []ObfuscationValue{Networking, WorkloadNames}
+	isValid := func(value interface{}) bool {
+		return value == string(Networking) || value == string(WorkloadNames)
+	}
+
+	switch v := raw.(type) {
+	case nil:
+		return nil
+	case string:
+		if v == "" || isValid(v) {
+			return nil
+		}
+		return field.ErrorList{field.NotSupported(fldPath, v, validValues)}
+	case []interface{}:
+		var allErrs field.ErrorList
+		for i, value := range v {
+			if !isValid(value) {
+				allErrs = append(allErrs, field.NotSupported(fldPath.Index(i), value, validValues))
+			}
+		}
+		return allErrs
+	default:
+		return field.ErrorList{field.Invalid(fldPath, raw, "must be a string or a list of strings")}
+	}
+}
+
+// validateInterval checks that the interval is a positive duration not below the minimum (when set)
+func validateInterval(fldPath *field.Path, interval string, minimum time.Duration) field.ErrorList {
+	if interval == "" {
+		return nil
+	}
+	duration, err := time.ParseDuration(interval)
+	if err != nil {
+		return field.ErrorList{field.Invalid(fldPath, interval, err.Error())}
+	}
+	if duration <= 0 {
+		return field.ErrorList{field.Invalid(fldPath, interval, "must be greater than zero")}
+	}
+	if minimum > 0 && duration < minimum {
+		return field.ErrorList{field.Invalid(fldPath, interval, fmt.Sprintf("must be at least %s", minimum))}
+	}
+	return nil
+}
+
+// validateURL checks that the value is an absolute HTTP(S) URL. The endpoints may contain
+// the placeholders, so they are replaced before the parsing.
+func validateURL(fldPath *field.Path, value string) field.ErrorList {
+	if value == "" {
+		return nil
+	}
+	u, err := url.Parse(strings.ReplaceAll(value, endpointPlaceholder, "placeholder"))
+	if err != nil {
+		return field.ErrorList{field.Invalid(fldPath, value, err.Error())}
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return field.ErrorList{field.Invalid(fldPath, value, "the URL scheme must be http or https")}
+	}
+	if u.Host == "" {
+		return field.ErrorList{field.Invalid(fldPath, value, "the URL host must not be empty")}
+	}
+	return nil
+}
+
+// validateBool checks that the value is "true" or "false", other values are decoded as false
+func validateBool(fldPath *field.Path, value string) field.ErrorList {
+	if value == "" || strings.EqualFold(value, "true") || strings.EqualFold(value, "false") {
+		return nil
+	}
+	return field.ErrorList{field.NotSupported(fldPath, value, []string{"true", "false"})}
+}
+
+// validateContextLines checks that the number of the node log context lines is within the allowed range
+func validateContextLines(fldPath *field.Path, value string) field.ErrorList {
+	if value == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return field.ErrorList{field.Invalid(fldPath, value, "must be an integer")}
+	}
+	if n < 0 || n > maxNodeLogsContextLines {
+		return field.ErrorList{field.Invalid(fldPath, value, fmt.Sprintf("must be between 0 and %d", maxNodeLogsContextLines))}
+	}
+	return nil
+}
+
+// validateLogsMaxBytes checks that the log byte budget is a quantity within the allowed range
+func validateLogsMaxBytes(fldPath *field.Path, value string) field.ErrorList {
+	if value == "" {
+		return nil
+	}
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return field.ErrorList{field.Invalid(fldPath, value, "must be a quantity (e.g. 8Mi)")}
+	}
+	if n := quantity.Value(); n < minConditionalLogsMaxBytes || n > maxConditionalLogsMaxBytes {
+		return field.ErrorList{field.Invalid(fldPath, value, "must be between 1Mi and 64Mi")}
+	}
+	return nil
+}
+
+// validateVersion checks that the value is a semantic version
+func validateVersion(fldPath *field.Path, value string) field.ErrorList {
+	if value == "" {
+		return nil
+	}
+	if _, err := semver.Parse(value); err != nil {
+		return field.ErrorList{field.Invalid(fldPath, value, err.Error())}
+	}
+	return nil
+}
+
+// validatePublicKeys checks that the value contains only the PEM encoded public keys
+func validatePublicKeys(fldPath *field.Path, value string) field.ErrorList {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	var allErrs field.ErrorList
+	keys := 0
+	rest := []byte(value)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(keys), block.Type, "unexpected PEM block type"))
+		} else if _, err := x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(keys), block.Type, err.Error()))
+		}
+		keys++
+	}
+	if keys == 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath, field.OmitValueType{}, "no PEM encoded public key found"))
+	}
+	return allErrs
+}
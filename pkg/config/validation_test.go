@@ -0,0 +1,151 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ValidateConfig(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         string
+		expectedErrors []string
+		expectedError  string
+	}{
+		{
+			name: "valid configuration",
+			config: `
+dataReporting:
+  interval: 2h
+  uploadEndpoint: https://console.redhat.com/api/ingress/v1/upload
+  downloadEndpoint: https://console.redhat.com/api/insights-results-aggregator/v2/cluster/%s/reports
+  storagePath: /var/lib/insights-operator
+  obfuscation:
+  - networking
+  - workload_names
+  obfuscationRules:
+  - type: literal
+    value: internal.corp
+    placeholder: <INTERNAL>
+  disableRuntimeExtractor: true
+  remoteConfigurationVersion: 1.1.0
+  remoteConfigurationPublicKeys: |
+    -----BEGIN PUBLIC KEY-----
+    MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEKIMIqTWyVphusLfxYwA/xL1yZ6qO
+    etOmBDkBr+wuAanIitUehi+6cS+GfdPp1vLNMUJftw8G7UlEOndsIchF4A==
+    -----END PUBLIC KEY-----
+  nodeLogs:
+    contextBefore: 3
+    continuationLines: "True"
+  gathererIntervals:
+    workloads: 24h
+    clusterconfig/node_logs: 6h
+  conditionalLogsMaxBytes: 16Mi
+sca:
+  disabled: false
+  interval: 8h
+proxy:
+  httpsProxy: http://proxy.corp:3128
+  noProxy: .corp`,
+		},
+		{
+			name:   "empty configuration",
+			config: "",
+		},
+		{
+			name: "unknown fields",
+			config: `
+dataReporting:
+  intervall: 2h
+  obfuscationRules:
+  - type: literal
+    value: internal.corp
+    placeholder: <INTERNAL>
+    replacement: <X>
+alert:
+  disabled: true`,
+			expectedErrors: []string{
+				`alert: Unsupported value: "alert": supported values: "dataReporting", "alerting", "sca", "clusterTransfer", "proxy"`,
+				`dataReporting.intervall: Unsupported value: "intervall"`,
+				`dataReporting.obfuscationRules[0].replacement: Unsupported value: "replacement": supported values: "type", "value", "placeholder"`,
+			},
+		},
+		{
+			name: "invalid values",
+			config: `
+dataReporting:
+  interval: 5m
+  uploadEndpoint: console.redhat.com/api/ingress/v1/upload
+  storagePath: tmp/insights
+  obfuscation:
+  - networking
+  - workload-names
+  obfuscationRules:
+  - type: regex
+    value: ".*"
+    placeholder: <ALL>
+  disableRuntimeExtractor: enabled
+  remoteConfigurationMinVersion: "1.1"
+  remoteConfigurationPublicKeys: not a key
+  nodeLogs:
+    contextAfter: 100
+  gathererIntervals:
+    clusterconfig/node_logs: daily
+    clusterconfig/nodes/extra: 1h
+  conditionalLogsMaxBytes: 1Gi
+alerting:
+  disabled: "no"
+sca:
+  interval: 8
+clusterTransfer:
+  interval: -1h
+proxy:
+  httpProxy: ftp://proxy.corp`,
+			expectedErrors: []string{
+				`dataReporting.interval: Invalid value: "5m": must be at least 10m0s`,
+				`dataReporting.uploadEndpoint: Invalid value: "console.redhat.com/api/ingress/v1/upload": the URL scheme must be http or https`,
+				`dataReporting.storagePath: Invalid value: "tmp/insights": must be an absolute path`,
+				`dataReporting.obfuscation[1]: Unsupported value: "workload-names": supported values: "networking", "workload_names"`,
+				`dataReporting.obfuscationRules[0]: Invalid value: ".*": regular expression ".*" must not match an empty string`,
+				`dataReporting.disableRuntimeExtractor: Unsupported value: "enabled": supported values: "true", "false"`,
+				`dataReporting.remoteConfigurationPublicKeys: Invalid value: no PEM encoded public key found`,
+				`dataReporting.remoteConfigurationMinVersion: Invalid value: "1.1": No Major.Minor.Patch elements found`,
+				`dataReporting.nodeLogs.contextAfter: Invalid value: "100": must be between 0 and 20`,
+				`dataReporting.gathererIntervals[clusterconfig/node_logs]: Invalid value: "daily": time: invalid duration "daily"`,
+				`dataReporting.gathererIntervals: Invalid value: "clusterconfig/nodes/extra": must be a gatherer name`,
+				`dataReporting.conditionalLogsMaxBytes: Invalid value: "1Gi": must be between 1Mi and 64Mi`,
+				`alerting.disabled: Unsupported value: "no": supported values: "true", "false"`,
+				`sca.interval: Invalid value: "8": time: missing unit in duration "8"`,
+				`clusterTransfer.interval: Invalid value: "-1h": must be greater than zero`,
+				`proxy.httpProxy: Invalid value: "ftp://proxy.corp": the URL scheme must be http or https`,
+			},
+		},
+		{
+			name:          "not decodable configuration",
+			config:        "dataReporting: [",
+			expectedError: "error converting YAML to JSON: yaml: line 1: did not find expected node content",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fieldErrs, err := ValidateConfig([]byte(tt.config))
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+
+			var errs []string
+			for _, fieldErr := range fieldErrs {
+				errs = append(errs, fieldErr.Error())
+			}
+			assert.Len(t, errs, len(tt.expectedErrors))
+			for i := range tt.expectedErrors {
+				if i < len(errs) {
+					assert.Contains(t, errs[i], tt.expectedErrors[i])
+				}
+			}
+		})
+	}
+}
@@ -123,7 +123,7 @@ func (g *GatherJob) Gather(ctx context.Context, kubeConfig, protoKubeConfig *res
 
 	allFunctionReports := make(map[string]gather.GathererFunctionReport)
 	for _, gatherer := range createdGatherers {
-		functionReports, err := gather.CollectAndRecordGatherer(ctx, gatherer, rec, nil)
+		functionReports, err := gather.CollectAndRecordGatherer(ctx, gatherer, rec, nil, nil)
 		if err != nil {
 			klog.Errorf("unable to process gatherer %v, error: %v", gatherer.GetName(), err)
 		}
@@ -133,7 +133,7 @@ func (g *GatherJob) Gather(ctx context.Context, kubeConfig, protoKubeConfig *res
 		}
 	}
 
-	return gather.RecordArchiveMetadata(gather.FunctionReportsMapToArray(allFunctionReports), rec, anonymizer)
+	return gather.RecordArchiveMetadata(gather.FunctionReportsMapToArray(allFunctionReports), nil, rec, anonymizer)
 }
 
 // GatherAndUpload runs a single gather and stores the generated archive, uploads it.
@@ -228,6 +228,10 @@ func (g *GatherJob) GatherAndUpload(kubeConfig, protoKubeConfig *rest.Config) er
 		return err
 	}
 
+	// the gathering schedule is kept only by the periodic gathering in the operator process
+	if len(configAggregator.Config().DataReporting.GathererIntervals) > 0 {
+		klog.Info("The gatherer intervals are configured, but they don't apply to the gathering job, all the enabled functions are gathered")
+	}
 	allFunctionReports, remoteConfStatus, err := gatherAndReportFunctions(ctx, createdGatherers, dataGatherCR, rec)
 	if err != nil {
 		klog.Errorf("failed to gatherAndReportFunctions: %v", err)
@@ -350,7 +354,7 @@ func gatherAndReportFunctions(
 	}
 
 	for _, gatherer := range gatherersToRun {
-		functionReports, err := gather.CollectAndRecordGatherer(ctx, gatherer, rec, gatheringConfig) // nolint: govet
+		functionReports, err := gather.CollectAndRecordGatherer(ctx, gatherer, rec, gatheringConfig, nil) // nolint: govet
 		if err != nil {
 			klog.Errorf("unable to process gatherer %v, error: %v", gatherer.GetName(), err)
 		}
@@ -422,7 +426,7 @@ func updateDataGatherStatus(
 func recordAllData(functionReports []gather.GathererFunctionReport,
 	rec *recorder.Recorder, recdriver *diskrecorder.DiskRecorder, anonymizer *anonymization.Anonymizer,
 ) (*insightsclient.Source, error) {
-	err := gather.RecordArchiveMetadata(functionReports, rec, anonymizer)
+	err := gather.RecordArchiveMetadata(functionReports, nil, rec, anonymizer)
 	if err != nil {
 		return nil, err
 	}
@@ -579,15 +583,16 @@ func createRemoteConfigConditions(
 	remoteConfigAvailableCondition.Status = boolToConditionStatus(remoteConfStatus.ConfigAvailable)
 	remoteConfigAvailableCondition.Reason = remoteConfStatus.AvailableReason
 	if !remoteConfStatus.ConfigAvailable {
-		remoteConfigAvailableCondition.Message = remoteConfStatus.Err.Error()
+		remoteConfigAvailableCondition.Message = remoteConfStatus.ErrorMessage()
 	}
 
 	// set the remoteConfigValidCondition only if the remoteConfig is available
 	if remoteConfStatus.ConfigAvailable {
 		remoteConfigValidCondition.Status = boolToConditionStatus(remoteConfStatus.ConfigValid)
 		remoteConfigValidCondition.Reason = remoteConfStatus.ValidReason
-		if !remoteConfStatus.ConfigValid {
-			remoteConfigValidCondition.Message = remoteConfStatus.Err.Error()
+		// the valid configuration can still carry a warning, e.g. its signature was not verified
+		if !remoteConfStatus.ConfigValid || remoteConfStatus.Err != nil {
+			remoteConfigValidCondition.Message = remoteConfStatus.ErrorMessage()
 		}
 	}
 	return
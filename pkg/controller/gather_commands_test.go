@@ -392,6 +392,50 @@ func TestCreateRemoteConfigConditions(t *testing.T) {
 				Message: "cannot parse",
 			},
 		},
+		{
+			name: "Remote Config status is available but invalid and the last known good config is used",
+			remoteConfigStatus: &gatherers.RemoteConfigStatus{
+				AvailableReason: status.AsExpectedReason,
+				ValidReason:     "InvalidSignature",
+				ConfigAvailable: true,
+				ConfigValid:     false,
+				Err:             fmt.Errorf("the remote configuration is not signed"),
+				Source:          gatherers.RemoteConfigSourceLastKnownGood,
+			},
+			expectedRemoteConfigAvailableCon: metav1.Condition{
+				Type:   string(status.RemoteConfigurationAvailable),
+				Status: metav1.ConditionTrue,
+				Reason: status.AsExpectedReason,
+			},
+			expectedRemoteConfigValidCon: metav1.Condition{
+				Type:    string(status.RemoteConfigurationValid),
+				Status:  metav1.ConditionFalse,
+				Reason:  "InvalidSignature",
+				Message: "the remote configuration is not signed (configuration source: LastKnownGood)",
+			},
+		},
+		{
+			name: "Remote Config status is valid but its signature is not verified",
+			remoteConfigStatus: &gatherers.RemoteConfigStatus{
+				AvailableReason: status.AsExpectedReason,
+				ValidReason:     "NoPublicKey",
+				ConfigAvailable: true,
+				ConfigValid:     true,
+				Err:             fmt.Errorf("the signature of the remote configuration is not verified"),
+				Source:          gatherers.RemoteConfigSourceRemote,
+			},
+			expectedRemoteConfigAvailableCon: metav1.Condition{
+				Type:   string(status.RemoteConfigurationAvailable),
+				Status: metav1.ConditionTrue,
+				Reason: status.AsExpectedReason,
+			},
+			expectedRemoteConfigValidCon: metav1.Condition{
+				Type:    string(status.RemoteConfigurationValid),
+				Status:  metav1.ConditionTrue,
+				Reason:  "NoPublicKey",
+				Message: "the signature of the remote configuration is not verified (configuration source: Remote)",
+			},
+		},
 	}
 
 	for _, tt := range tests {
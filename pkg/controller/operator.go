@@ -261,6 +261,7 @@ func (s *Operator) Run(ctx context.Context, controller *controllercmd.Controller
 		controller.EventRecorder,
 		updateCh,
 	)
+	statusReporter.AddSources(configMapObserver.Sources()...)
 
 	var anonymizer *anonymization.Anonymizer
 	var recdriver *diskrecorder.DiskRecorder
@@ -192,19 +192,27 @@ func (c *Controller) Gather() {
 		}
 	}()
 
+	insightsConfig := c.configAggregator.Config()
+	schedule := gather.LoadGatheringSchedule(insightsConfig.DataReporting.StoragePath, insightsConfig.DataReporting.GathererIntervals)
+	defer func() {
+		if err := schedule.Store(); err != nil {
+			klog.Errorf("Unable to store the gathering schedule: %v", err)
+		}
+	}()
+
 	var gatherersToProcess []gatherers.Interface
 
 	for _, gatherer := range c.gatherers {
 		if g, ok := gatherer.(gatherers.CustomPeriodGatherer); ok {
-			if g.ShouldBeProcessedNow() {
+			if g.ShouldBeProcessedNow() && schedule.IsGathererDue(g.GetName()) {
 				gatherersToProcess = append(gatherersToProcess, g)
 				g.UpdateLastProcessingTime()
 			}
-		} else {
+		} else if schedule.IsGathererDue(gatherer.GetName()) {
 			gatherersToProcess = append(gatherersToProcess, gatherer)
 		}
 	}
-	interval := c.configAggregator.Config().DataReporting.Interval
+	interval := insightsConfig.DataReporting.Interval
 	ctx, cancel := context.WithTimeout(context.Background(), interval)
 	defer cancel()
 
@@ -216,7 +224,7 @@ func (c *Controller) Gather() {
 			start := time.Now()
 
 			klog.Infof("Running %s gatherer", gatherer.GetName())
-			functionReports, err := gather.CollectAndRecordGatherer(ctx, gatherer, c.recorder, nil)
+			functionReports, err := gather.CollectAndRecordGatherer(ctx, gatherer, c.recorder, nil, schedule)
 			for i := range functionReports {
 				allFunctionReports[functionReports[i].FuncName] = functionReports[i]
 			}
@@ -233,13 +241,13 @@ func (c *Controller) Gather() {
 				if !remoteConfigStatus.ConfigValid {
 					newStatus.Healthy = false
 					newStatus.Reason = remoteConfigStatus.ValidReason
-					newStatus.Message = remoteConfigStatus.Err.Error()
+					newStatus.Message = remoteConfigStatus.ErrorMessage()
 				}
 
 				if !remoteConfigStatus.ConfigAvailable {
 					newStatus.Healthy = false
 					newStatus.Reason = remoteConfigStatus.AvailableReason
-					newStatus.Message = remoteConfigStatus.Err.Error()
+					newStatus.Message = remoteConfigStatus.ErrorMessage()
 				}
 
 				c.statuses[name].UpdateStatus(newStatus)
@@ -270,7 +278,9 @@ func (c *Controller) Gather() {
 	if err != nil {
 		klog.Errorf("failed to update the Insights Operator CR status: %v", err)
 	}
-	err = gather.RecordArchiveMetadata(gather.FunctionReportsMapToArray(allFunctionReports), c.recorder, c.anonymizer)
+	err = gather.RecordArchiveMetadata(
+		gather.FunctionReportsMapToArray(allFunctionReports), schedule.NotDueFunctions(), c.recorder, c.anonymizer,
+	)
 	if err != nil {
 		klog.Errorf("unable to record archive metadata because of error: %v", err)
 	}
@@ -25,6 +25,7 @@ import (
 	"github.com/openshift/insights-operator/pkg/gather"
 	"github.com/openshift/insights-operator/pkg/gatherers"
 	"github.com/openshift/insights-operator/pkg/insights/types"
+	"github.com/openshift/insights-operator/pkg/record"
 	"github.com/openshift/insights-operator/pkg/recorder"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
@@ -49,6 +50,39 @@ func Test_Controller_CustomPeriodGatherer(t *testing.T) {
 	mockRecorder.Reset()
 }
 
+func Test_Controller_GathererIntervals(t *testing.T) {
+	storagePath := t.TempDir()
+	insightsConfig := &config.InsightsConfiguration{
+		DataReporting: config.DataReporting{
+			Enabled:           true,
+			Interval:          1 * time.Hour,
+			StoragePath:       storagePath,
+			GathererIntervals: map[string]time.Duration{"mock_gatherer/3_records": 24 * time.Hour},
+		},
+	}
+	c, mockRecorder, err := getMocksForPeriodicTest([]gatherers.Interface{&gather.MockGatherer{}}, 1*time.Hour)
+	assert.NoError(t, err)
+	c.configAggregator = config.NewMockConfigMapConfigurator(insightsConfig)
+	c.Gather()
+	// 5 records + metadata
+	assert.Len(t, mockRecorder.Records, 6)
+	mockRecorder.Reset()
+
+	// the last run time is stored, so the function is not due after the restart either
+	c, mockRecorder, err = getMocksForPeriodicTest([]gatherers.Interface{&gather.MockGatherer{}}, 1*time.Hour)
+	assert.NoError(t, err)
+	c.configAggregator = config.NewMockConfigMapConfigurator(insightsConfig)
+	c.Gather()
+	// 2 records + metadata (the 3 records are not due for 24 hours)
+	assert.Len(t, mockRecorder.Records, 3)
+
+	metadata := mockRecorder.Records[len(mockRecorder.Records)-1]
+	assert.Equal(t, recorder.MetadataRecordName, metadata.Name)
+	archiveMetadata := metadata.Item.(record.JSONMarshaller).Object.(gather.ArchiveMetadata)
+	assert.Len(t, archiveMetadata.NotDueFunctions, 1)
+	assert.Equal(t, "mock_gatherer/3_records", archiveMetadata.NotDueFunctions[0].FuncName)
+}
+
 func Test_Controller_Run(t *testing.T) {
 	tests := []struct {
 		name                 string
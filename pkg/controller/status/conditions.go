@@ -25,6 +25,9 @@ const (
 	RemoteConfigurationValid configv1.ClusterStatusConditionType = "RemoteConfigurationValid"
 	// GatheringDisabled is a condition providing information about the disabling of gathering with the API.
 	GatheringDisabled configv1.ClusterStatusConditionType = "GatheringDisabled"
+	// ConfigurationInvalid is a condition type providing info about the field errors found by the strict validation
+	// of the "insights-config" configmap
+	ConfigurationInvalid configv1.ClusterStatusConditionType = "ConfigurationInvalid"
 )
 
 type conditionsMap map[configv1.ClusterStatusConditionType]configv1.ClusterOperatorStatusCondition
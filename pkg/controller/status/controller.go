@@ -474,6 +474,11 @@ func (c *Controller) updateControllerConditions(cs *conditions, isInitializing b
 		clustertransfer.ControllerName,
 		clustertransfer.AvailableReason,
 		isInitializing)
+	c.updateControllerConditionByReason(cs,
+		ConfigurationInvalid,
+		configobserver.ConfigValidatorName,
+		configobserver.ConfigurationInvalidReason,
+		isInitializing)
 
 	if c.isTechPreview {
 		return
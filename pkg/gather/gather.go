@@ -53,6 +53,8 @@ type ArchiveMetadata struct {
 	Uptime float64 `json:"uptime_seconds"`
 	// IsGlobalObfuscationEnabled shows if obfuscation(hiding IPs and cluster domain) is enabled
 	IsGlobalObfuscationEnabled bool `json:"is_global_obfuscation_enabled"`
+	// NotDueFunctions are the gatherers and gathering functions skipped, because their interval hasn't elapsed yet
+	NotDueFunctions []NotDueFunctionReport `json:"not_due_functions,omitempty"`
 }
 
 // CreateAllGatherers creates all the gatherers
@@ -75,21 +77,28 @@ func CreateAllGatherers(
 
 // CollectAndRecordGatherer gathers enabled functions of the provided gatherer and records the results to the recorder
 // and returns info about the recorded data. Panics are just logged and written
-// to the resulting array (to the archive metadata)
+// to the resulting array (to the archive metadata). When the schedule is provided, only the due functions are gathered
+// and the run times of the ones without errors are recorded. The run of the gatherer itself is recorded only
+// when at least one of its functions ran.
 func CollectAndRecordGatherer(
 	ctx context.Context,
 	gatherer gatherers.Interface,
 	rec recorder.Interface,
 	gatherConfigs []insightsv1.GathererConfig,
+	schedule *GatheringSchedule,
 ) ([]GathererFunctionReport, error) {
 	startTime := time.Now()
-	reports, totalNumberOfRecords, errs := collectAndRecordGatherer(ctx, gatherer, rec, gatherConfigs)
+	reports, totalNumberOfRecords, errs := collectAndRecordGatherer(ctx, gatherer, rec, gatherConfigs, schedule)
+	functionsRan := len(reports) > 0
 	reports = append(reports, GathererFunctionReport{
 		FuncName:     gatherer.GetName(),
 		Duration:     time.Since(startTime).Milliseconds(),
 		RecordsCount: totalNumberOfRecords,
 		Errors:       utils.ErrorsToStrings(errs),
 	})
+	if schedule != nil && functionsRan {
+		schedule.recordRuns(reports, startTime)
+	}
 
 	return reports, utils.UniqueErrors(errs)
 }
@@ -99,8 +108,9 @@ func collectAndRecordGatherer(
 	gatherer gatherers.Interface,
 	rec recorder.Interface,
 	gatherConfigs []insightsv1.GathererConfig,
+	schedule *GatheringSchedule,
 ) (reports []GathererFunctionReport, totalNumberOfRecords int, allErrors []error) {
-	resultsChan, err := startGatheringConcurrently(ctx, gatherer, gatherConfigs)
+	resultsChan, err := startGatheringConcurrently(ctx, gatherer, gatherConfigs, schedule)
 	if err != nil {
 		allErrors = append(allErrors, err)
 		return reports, totalNumberOfRecords, allErrors
@@ -189,9 +199,10 @@ func recordGatheringFunctionResult(
 	}, allErrors
 }
 
-// RecordArchiveMetadata records info about archive and gatherers' reports
+// RecordArchiveMetadata records info about archive, gatherers' reports and the functions which were not due
 func RecordArchiveMetadata(
 	functionReports []GathererFunctionReport,
+	notDueFunctions []NotDueFunctionReport,
 	rec recorder.Interface,
 	anonymizer *anonymization.Anonymizer,
 ) error {
@@ -202,6 +213,7 @@ func RecordArchiveMetadata(
 			StatusReports:              functionReports,
 			Uptime:                     time.Since(programStartTime).Truncate(time.Millisecond).Seconds(),
 			IsGlobalObfuscationEnabled: anonymizer.IsAnonymizerTypeEnabled(anonymization.NetworkAnonymizerType),
+			NotDueFunctions:            notDueFunctions,
 		}},
 	}
 	if errs := rec.Record(archiveMetadata); len(errs) > 0 {
@@ -211,10 +223,10 @@ func RecordArchiveMetadata(
 	return nil
 }
 
-// startGatheringConcurrently starts gathering of enabled functions of the provided gatherer and returns a channel
-// with results which will be closed when processing is done
+// startGatheringConcurrently starts gathering of enabled (and due) functions of the provided gatherer and returns
+// a channel with results which will be closed when processing is done
 func startGatheringConcurrently(
-	ctx context.Context, gatherer gatherers.Interface, gatherConfigs []insightsv1.GathererConfig,
+	ctx context.Context, gatherer gatherers.Interface, gatherConfigs []insightsv1.GathererConfig, schedule *GatheringSchedule,
 ) (chan GatheringFunctionResult, error) {
 	var tasks []Task
 	var gatheringFunctions map[string]gatherers.GatheringClosure
@@ -232,6 +244,10 @@ func startGatheringConcurrently(
 		return nil, fmt.Errorf("no gather functions are specified to run")
 	}
 
+	if schedule != nil {
+		gatheringFunctions = schedule.dueGatheringFunctions(gatherer.GetName(), gatheringFunctions, time.Now())
+	}
+
 	for functionName, gatheringClosure := range gatheringFunctions {
 		tasks = append(tasks, Task{
 			Name: functionName,
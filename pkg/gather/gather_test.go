@@ -148,7 +148,7 @@ func TestGetEnabledGatheringFunctions(t *testing.T) {
 func TestStartGatheringConcurrently(t *testing.T) {
 	gatherer := &MockGatherer{SomeField: "some_value"}
 
-	resultsChan, err := startGatheringConcurrently(context.Background(), gatherer, nil)
+	resultsChan, err := startGatheringConcurrently(context.Background(), gatherer, nil, nil)
 	assert.NoError(t, err)
 
 	results := gatherResultsFromChannel(resultsChan)
@@ -225,7 +225,7 @@ func TestStartGatheringConcurrently(t *testing.T) {
 			Name:  "mock_gatherer/name",
 			State: insightsv1.GathererStateDisabled,
 		},
-	})
+	}, nil)
 	assert.NoError(t, err)
 
 	results = gatherResultsFromChannel(resultsChan)
@@ -257,7 +257,7 @@ func TestStartGatheringConcurrently(t *testing.T) {
 			Name:  "mock_gatherer/panic",
 			State: insightsv1.GathererStateDisabled,
 		},
-	},
+	}, nil,
 	)
 	assert.NoError(t, err)
 	results = gatherResultsFromChannel(resultsChan)
@@ -293,7 +293,7 @@ func TestStartGatheringConcurrently(t *testing.T) {
 				},
 			},
 		},
-	})
+	}, nil)
 }
 
 func TestStartGatheringConcurrentlyError(t *testing.T) {
@@ -320,7 +320,7 @@ func TestStartGatheringConcurrentlyError(t *testing.T) {
 			Name:  "mock_gatherer/3_records",
 			State: insightsv1.GathererStateDisabled,
 		},
-	})
+	}, nil)
 	assert.EqualError(t, err, "no gather functions are specified to run")
 	assert.Nil(t, resultsChan)
 
@@ -329,7 +329,7 @@ func TestStartGatheringConcurrentlyError(t *testing.T) {
 			Name:  "mock_gatherer",
 			State: insightsv1.GathererStateDisabled,
 		},
-	})
+	}, nil)
 	assert.EqualError(t, err, "no gather functions are specified to run")
 	assert.Nil(t, resultsChan)
 }
@@ -353,10 +353,10 @@ func TestCollectAndRecordGatherer(t *testing.T) {
 	anonymizer, err := anonymization.NewAnonymizer(networkAnonymizer)
 	assert.NoError(t, err)
 
-	functionReports, err := CollectAndRecordGatherer(context.Background(), gatherer, mockRecorder, nil)
+	functionReports, err := CollectAndRecordGatherer(context.Background(), gatherer, mockRecorder, nil, nil)
 	assert.Error(t, err)
 
-	err = RecordArchiveMetadata(functionReports, mockRecorder, anonymizer)
+	err = RecordArchiveMetadata(functionReports, nil, mockRecorder, anonymizer)
 	assert.NoError(t, err)
 
 	assert.Len(t, mockRecorder.Records, 6)
@@ -443,7 +443,7 @@ func TestCollectAndRecordGathererError(t *testing.T) {
 		},
 	}
 
-	functionReports, err := CollectAndRecordGatherer(context.Background(), gatherer, mockRecorder, gatherersConfig)
+	functionReports, err := CollectAndRecordGatherer(context.Background(), gatherer, mockRecorder, gatherersConfig, nil)
 	assert.EqualError(
 		t,
 		err,
@@ -457,7 +457,7 @@ func TestCollectAndRecordGathererError(t *testing.T) {
 	anonymizer, err := anonymization.NewAnonymizer(networkAnonymizer)
 	assert.NoError(t, err)
 
-	err = RecordArchiveMetadata(functionReports, mockRecorder, anonymizer)
+	err = RecordArchiveMetadata(functionReports, nil, mockRecorder, anonymizer)
 	assert.NoError(t, err)
 
 	assert.Len(t, mockRecorder.Records, 1)
@@ -505,7 +505,7 @@ func TestCollectAndRecordGathererPanic(t *testing.T) {
 		},
 	}
 
-	functionReports, err := CollectAndRecordGatherer(context.Background(), gatherer, mockRecorder, gatherersConfig)
+	functionReports, err := CollectAndRecordGatherer(context.Background(), gatherer, mockRecorder, gatherersConfig, nil)
 	assert.EqualError(t, err, `function "panic" panicked`)
 	assert.Len(t, functionReports, 2)
 	functionReports[0].Duration = 0
@@ -563,7 +563,7 @@ func TestCollectAndRecordGathererDuplicateRecords(t *testing.T) {
 
 	rec := recorder.New(mockDriver, time.Second, anonymizer)
 
-	functionReports, err := CollectAndRecordGatherer(context.Background(), gatherer, rec, nil)
+	functionReports, err := CollectAndRecordGatherer(context.Background(), gatherer, rec, nil, nil)
 	assert.Error(t, err)
 	assert.NotEmpty(t, functionReports)
 	assert.Len(t, functionReports, 4)
@@ -610,7 +610,7 @@ func TestCollectAndRecordGathererWarning(t *testing.T) {
 	mockDriver := &MockDriver{}
 	rec := recorder.New(mockDriver, time.Second, nil)
 
-	functionReports, err := CollectAndRecordGatherer(context.Background(), gatherer, rec, nil)
+	functionReports, err := CollectAndRecordGatherer(context.Background(), gatherer, rec, nil, nil)
 	assert.NoError(t, err)
 	assert.Len(t, functionReports, 2)
 	assert.Equal(t, "mock_gatherer_with_provided_functions/function_1", functionReports[0].FuncName)
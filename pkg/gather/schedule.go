@@ -0,0 +1,153 @@
+package gather
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/insights-operator/pkg/gatherers"
+)
+
+// gatheringScheduleFileName is the name of the file on the storage path with the last run times
+// of the gatherers and the gathering functions
+const gatheringScheduleFileName = "gathering-schedule.json"
+
+// NotDueFunctionReport contains the information about a gatherer or a gathering function
+// which was skipped, because its interval hasn't elapsed since its last run
+type NotDueFunctionReport struct {
+	FuncName    string    `json:"name"`
+	LastRunTime time.Time `json:"last_run_time"`
+	NextRunTime time.Time `json:"next_run_time"`
+}
+
+// GatheringSchedule decides which gatherers and gathering functions are due according to their configured
+// intervals. The intervals are keyed by the gatherer name (e.g. "workloads") or by the full function name
+// (e.g. "clusterconfig/node_logs"), the ones without the interval are due in every gathering.
+// The last run times are stored on the storage path, so that they survive the operator restarts.
+// The schedule is used only by the in-process periodic gathering, the gathering jobs (the techPreview
+// DataGather path) and the on-demand gathering run all the enabled functions regardless of the intervals.
+type GatheringSchedule struct {
+	path      string
+	intervals map[string]time.Duration
+	lastRuns  map[string]time.Time
+	notDue    []NotDueFunctionReport
+}
+
+// LoadGatheringSchedule creates the schedule with the provided intervals and reads the last run times
+// from the storage path. When the storage path is not set, the last run times are kept only in memory.
+func LoadGatheringSchedule(storagePath string, intervals map[string]time.Duration) *GatheringSchedule {
+	s := &GatheringSchedule{
+		intervals: intervals,
+		lastRuns:  make(map[string]time.Time),
+	}
+	if storagePath == "" {
+		return s
+	}
+	s.path = filepath.Join(storagePath, gatheringScheduleFileName)
+
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			klog.Warningf("Unable to read the gathering schedule: %v. All the gathering functions are due.", err)
+		}
+		return s
+	}
+	if err := json.Unmarshal(content, &s.lastRuns); err != nil {
+		klog.Warningf("Unable to decode the gathering schedule: %v. All the gathering functions are due.", err)
+		s.lastRuns = make(map[string]time.Time)
+	}
+	return s
+}
+
+// isDue returns true when the gatherer or the gathering function with the name has no interval or its interval
+// has elapsed since its last run, otherwise it's added to the not due functions. The run itself is recorded
+// only after it succeeds, see recordRuns.
+func (s *GatheringSchedule) isDue(name string, now time.Time) bool {
+	lastRun, ok := s.lastRuns[name]
+	interval := s.intervalOf(name)
+	nextRun := lastRun.Add(interval)
+	if !ok || interval <= 0 || !now.Before(nextRun) {
+		return true
+	}
+
+	klog.Infof("%s is not due until %s, skipping it", name, nextRun.Format(time.RFC3339))
+	s.notDue = append(s.notDue, NotDueFunctionReport{
+		FuncName:    name,
+		LastRunTime: lastRun,
+		NextRunTime: nextRun,
+	})
+	return false
+}
+
+// intervalOf returns the interval of the gatherer or the gathering function. The names of the conditional
+// gathering functions contain their parameters (e.g. "conditional/logs_of_namespace/namespace=ns,"),
+// so the interval of the function without the parameters applies to them as well.
+func (s *GatheringSchedule) intervalOf(name string) time.Duration {
+	if interval, ok := s.intervals[name]; ok {
+		return interval
+	}
+	if parts := strings.SplitN(name, "/", 3); len(parts) == 3 {
+		return s.intervals[parts[0]+"/"+parts[1]]
+	}
+	return 0
+}
+
+// IsGathererDue returns true when the whole gatherer is due, the not due gatherer shouldn't be processed at all
+func (s *GatheringSchedule) IsGathererDue(gathererName string) bool {
+	return s.isDue(gathererName, time.Now())
+}
+
+// dueGatheringFunctions returns only the due functions of the gatherer
+func (s *GatheringSchedule) dueGatheringFunctions(
+	gathererName string, functions map[string]gatherers.GatheringClosure, now time.Time,
+) map[string]gatherers.GatheringClosure {
+	due := make(map[string]gatherers.GatheringClosure)
+	for fName, gatheringClosure := range functions {
+		if s.isDue(fmt.Sprintf("%s/%s", gathererName, fName), now) {
+			due[fName] = gatheringClosure
+		}
+	}
+	return due
+}
+
+// recordRuns records the run time of the gatherers and the gathering functions whose reports have no errors,
+// the failed ones keep their previous last run time so that they are retried in the next gathering
+func (s *GatheringSchedule) recordRuns(reports []GathererFunctionReport, runTime time.Time) {
+	for i := range reports {
+		if len(reports[i].Errors) == 0 && reports[i].Panic == nil {
+			s.lastRuns[reports[i].FuncName] = runTime
+		}
+	}
+}
+
+// NotDueFunctions returns the gatherers and the gathering functions skipped because they were not due
+func (s *GatheringSchedule) NotDueFunctions() []NotDueFunctionReport {
+	sort.Slice(s.notDue, func(i, j int) bool {
+		return s.notDue[i].FuncName < s.notDue[j].FuncName
+	})
+	return s.notDue
+}
+
+// Store writes the last run times to the storage path
+func (s *GatheringSchedule) Store() error {
+	if s.path == "" {
+		return nil
+	}
+	content, err := json.Marshal(s.lastRuns)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first so that the previous schedule is never left half-written
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, content, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, s.path)
+}
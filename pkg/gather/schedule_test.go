@@ -0,0 +1,148 @@
+package gather
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/insights-operator/pkg/gatherers"
+	"github.com/openshift/insights-operator/pkg/recorder"
+)
+
+func TestGatheringSchedule_dueGatheringFunctions(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	schedule := LoadGatheringSchedule("", map[string]time.Duration{
+		"clusterconfig/node_logs":       24 * time.Hour,
+		"clusterconfig/nodes":           time.Hour,
+		"conditional/logs_of_namespace": 6 * time.Hour,
+	})
+	schedule.lastRuns = map[string]time.Time{
+		"clusterconfig/node_logs":                                 now.Add(-2 * time.Hour),
+		"clusterconfig/nodes":                                     now.Add(-time.Hour),
+		"clusterconfig/version":                                   now.Add(-2 * time.Hour),
+		"conditional/logs_of_namespace/namespace=openshift-etcd,": now.Add(-time.Hour),
+	}
+
+	due := schedule.dueGatheringFunctions("clusterconfig", map[string]gatherers.GatheringClosure{
+		"node_logs":      {},
+		"nodes":          {},
+		"version":        {},
+		"authentication": {},
+	}, now)
+	assert.ElementsMatch(t, []string{"nodes", "version", "authentication"}, mapKeys(due))
+
+	due = schedule.dueGatheringFunctions("conditional", map[string]gatherers.GatheringClosure{
+		"logs_of_namespace/namespace=openshift-etcd,":      {},
+		"logs_of_namespace/namespace=openshift-apiserver,": {},
+		"conditional_gatherer_rules":                       {},
+	}, now)
+	assert.ElementsMatch(t, []string{"logs_of_namespace/namespace=openshift-apiserver,", "conditional_gatherer_rules"}, mapKeys(due))
+
+	assert.Equal(t, []NotDueFunctionReport{
+		{FuncName: "clusterconfig/node_logs", LastRunTime: now.Add(-2 * time.Hour), NextRunTime: now.Add(22 * time.Hour)},
+		{
+			FuncName:    "conditional/logs_of_namespace/namespace=openshift-etcd,",
+			LastRunTime: now.Add(-time.Hour),
+			NextRunTime: now.Add(5 * time.Hour),
+		},
+	}, schedule.NotDueFunctions())
+	// the runs are recorded only after the gathering
+	assert.Equal(t, now.Add(-time.Hour), schedule.lastRuns["clusterconfig/nodes"])
+	assert.NotContains(t, schedule.lastRuns, "clusterconfig/authentication")
+	assert.Equal(t, now.Add(-2*time.Hour), schedule.lastRuns["clusterconfig/node_logs"])
+}
+
+func TestGatheringSchedule_recordRuns(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	schedule := LoadGatheringSchedule("", nil)
+	schedule.lastRuns = map[string]time.Time{
+		"clusterconfig/node_logs": now.Add(-2 * time.Hour),
+		"clusterconfig/nodes":     now.Add(-2 * time.Hour),
+	}
+
+	schedule.recordRuns([]GathererFunctionReport{
+		{FuncName: "clusterconfig/nodes"},
+		{FuncName: "clusterconfig/node_logs", Errors: []string{"error"}},
+		{FuncName: "clusterconfig/version", Panic: "panic"},
+		{FuncName: "clusterconfig/authentication", Warnings: []string{"warning"}},
+	}, now)
+	assert.Equal(t, map[string]time.Time{
+		"clusterconfig/node_logs":      now.Add(-2 * time.Hour),
+		"clusterconfig/nodes":          now,
+		"clusterconfig/authentication": now,
+	}, schedule.lastRuns)
+}
+
+func TestGatheringSchedule_StoreAndLoad(t *testing.T) {
+	storagePath := t.TempDir()
+	intervals := map[string]time.Duration{"workloads": 12 * time.Hour}
+
+	schedule := LoadGatheringSchedule(storagePath, intervals)
+	assert.True(t, schedule.IsGathererDue("workloads"))
+	schedule.recordRuns([]GathererFunctionReport{{FuncName: "workloads"}}, time.Now())
+	assert.NoError(t, schedule.Store())
+
+	// the last run time survives the restart
+	schedule = LoadGatheringSchedule(storagePath, intervals)
+	assert.False(t, schedule.IsGathererDue("workloads"))
+	assert.True(t, schedule.IsGathererDue("clusterconfig"))
+	assert.Len(t, schedule.NotDueFunctions(), 1)
+
+	// the corrupted schedule makes everything due
+	assert.NoError(t, os.WriteFile(filepath.Join(storagePath, gatheringScheduleFileName), []byte("{"), 0o600))
+	schedule = LoadGatheringSchedule(storagePath, intervals)
+	assert.True(t, schedule.IsGathererDue("workloads"))
+}
+
+func TestCollectAndRecordGatherer_Schedule(t *testing.T) {
+	gatherer := &MockGatherer{SomeField: "some_value"}
+	mockRecorder := &recorder.MockRecorder{}
+	schedule := LoadGatheringSchedule("", map[string]time.Duration{
+		"mock_gatherer/3_records": time.Hour,
+		"mock_gatherer/errors":    time.Hour,
+	})
+
+	functionReports, err := CollectAndRecordGatherer(context.Background(), gatherer, mockRecorder, nil, schedule)
+	assert.Error(t, err)
+	assert.Len(t, functionReports, 6)
+	assert.Empty(t, schedule.NotDueFunctions())
+
+	mockRecorder = &recorder.MockRecorder{}
+	functionReports, err = CollectAndRecordGatherer(context.Background(), gatherer, mockRecorder, nil, schedule)
+	assert.Error(t, err)
+	assert.Len(t, functionReports, 5)
+	for i := range functionReports {
+		assert.NotEqual(t, "mock_gatherer/3_records", functionReports[i].FuncName)
+	}
+	// the failed function is retried
+	assert.Len(t, schedule.NotDueFunctions(), 1)
+	assert.Equal(t, "mock_gatherer/3_records", schedule.NotDueFunctions()[0].FuncName)
+	assert.NotContains(t, schedule.lastRuns, "mock_gatherer/errors")
+
+	// the gatherer run is not recorded when none of its functions was due
+	functions, err := gatherer.GetGatheringFunctions(context.Background())
+	assert.NoError(t, err)
+	intervals := map[string]time.Duration{}
+	schedule = LoadGatheringSchedule("", intervals)
+	for _, name := range mapKeys(functions) {
+		intervals["mock_gatherer/"+name] = time.Hour
+		schedule.lastRuns["mock_gatherer/"+name] = time.Now()
+	}
+	functionReports, err = CollectAndRecordGatherer(context.Background(), gatherer, &recorder.MockRecorder{}, nil, schedule)
+	assert.NoError(t, err)
+	assert.Len(t, functionReports, 1)
+	assert.Len(t, schedule.NotDueFunctions(), len(functions))
+	assert.NotContains(t, schedule.lastRuns, "mock_gatherer")
+}
+
+func mapKeys(m map[string]gatherers.GatheringClosure) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -7,8 +7,8 @@ import (
 	v1 "k8s.io/api/core/v1"
 )
 
-// getEventsForInterval() returns events that occoured since last interval
-func getEventsForInterval(interval time.Duration, events *v1.EventList) v1.EventList {
+// GetEventsForInterval() returns events that occoured since last interval
+func GetEventsForInterval(interval time.Duration, events *v1.EventList) v1.EventList {
 	oldestEventTime := time.Now().Add(-interval)
 	var filteredEvents v1.EventList
 	for i := range events.Items {
@@ -34,8 +34,8 @@ func isEventNew(event *v1.Event, oldestEventTime time.Time) bool {
 	return false
 }
 
-// filterAbnormalEvents returns events that have Type different from "Normal"
-func filterAbnormalEvents(events *v1.EventList) v1.EventList {
+// FilterAbnormalEvents returns events that have Type different from "Normal"
+func FilterAbnormalEvents(events *v1.EventList) v1.EventList {
 	var filteredEvents v1.EventList
 	for i := range events.Items {
 		if isEventAbnormal(&events.Items[i]) {
@@ -49,8 +49,33 @@ func isEventAbnormal(event *v1.Event) bool {
 	return event.Type != "Normal"
 }
 
-// eventListToCompactedEventList() converts EventList into CompactedEventList
-func eventListToCompactedEventList(events *v1.EventList) CompactedEventList {
+// FilterEventsByReasonsAndTypes returns events with one of the reasons and one of the types,
+// the empty reasons or types don't filter the events
+func FilterEventsByReasonsAndTypes(events *v1.EventList, reasons, types []string) v1.EventList {
+	var filteredEvents v1.EventList
+	for i := range events.Items {
+		event := &events.Items[i]
+		if matchesAny(event.Reason, reasons) && matchesAny(event.Type, types) {
+			filteredEvents.Items = append(filteredEvents.Items, *event)
+		}
+	}
+	return filteredEvents
+}
+
+func matchesAny(value string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
+
+// EventListToCompactedEventList() converts EventList into CompactedEventList
+func EventListToCompactedEventList(events *v1.EventList) CompactedEventList {
 	var compactedEvents CompactedEventList
 	for i := range events.Items {
 		event := events.Items[i]
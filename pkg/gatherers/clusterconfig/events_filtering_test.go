@@ -9,7 +9,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func Test_getEventsForInterval(t *testing.T) {
+func Test_GetEventsForInterval(t *testing.T) {
 	timeNow := time.Now()
 	test := struct {
 		events   v1.EventList
@@ -57,11 +57,11 @@ func Test_getEventsForInterval(t *testing.T) {
 		},
 	}
 
-	filteredEvents := getEventsForInterval(1*time.Minute, &test.events)
+	filteredEvents := GetEventsForInterval(1*time.Minute, &test.events)
 	assert.Equal(t, filteredEvents, test.expected)
 }
 
-func Test_filterAbnormalEvents(t *testing.T) {
+func Test_FilterAbnormalEvents(t *testing.T) {
 	test := struct {
 		events   v1.EventList
 		expected v1.EventList
@@ -108,10 +108,43 @@ func Test_filterAbnormalEvents(t *testing.T) {
 		},
 	}
 
-	filteredEvents := filterAbnormalEvents(&test.events)
+	filteredEvents := FilterAbnormalEvents(&test.events)
 	assert.Equal(t, filteredEvents, test.expected)
 }
 
+func Test_FilterEventsByReasonsAndTypes(t *testing.T) {
+	events := v1.EventList{
+		Items: []v1.Event{
+			{ObjectMeta: metav1.ObjectMeta{Name: "backOff"}, Reason: "BackOff", Type: "Warning"},
+			{ObjectMeta: metav1.ObjectMeta{Name: "failed"}, Reason: "Failed", Type: "Warning"},
+			{ObjectMeta: metav1.ObjectMeta{Name: "pulled"}, Reason: "Pulled", Type: "Normal"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		reasons  []string
+		types    []string
+		expected []string
+	}{
+		{name: "no filter", expected: []string{"backOff", "failed", "pulled"}},
+		{name: "reasons", reasons: []string{"BackOff", "Pulled"}, expected: []string{"backOff", "pulled"}},
+		{name: "types", types: []string{"Warning"}, expected: []string{"backOff", "failed"}},
+		{name: "reasons and types", reasons: []string{"Pulled", "Failed"}, types: []string{"Warning"}, expected: []string{"failed"}},
+		{name: "nothing matches", reasons: []string{"Killing"}, expected: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filteredEvents := FilterEventsByReasonsAndTypes(&events, tt.reasons, tt.types)
+			var names []string
+			for i := range filteredEvents.Items {
+				names = append(names, filteredEvents.Items[i].Name)
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
 func Test_isEventNew(t *testing.T) {
 	tests := []struct {
 		event    v1.Event
@@ -140,7 +173,7 @@ func Test_isEventNew(t *testing.T) {
 	}
 }
 
-func Test_eventListToCompactedEventList(t *testing.T) {
+func Test_EventListToCompactedEventList(t *testing.T) {
 	timeNow := time.Now()
 	event := v1.Event{
 		ObjectMeta:    metav1.ObjectMeta{Name: "event", Namespace: "test namespace"},
@@ -156,7 +189,7 @@ func Test_eventListToCompactedEventList(t *testing.T) {
 		Message:       "test message",
 		Type:          "Normal",
 	}
-	compactedEventList := eventListToCompactedEventList(&v1.EventList{Items: []v1.Event{event}})
+	compactedEventList := EventListToCompactedEventList(&v1.EventList{Items: []v1.Event{event}})
 
 	assert.Equal(t, compactedEvent, compactedEventList.Items[0])
 }
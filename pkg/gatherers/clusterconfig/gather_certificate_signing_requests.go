@@ -31,7 +31,7 @@ import (
 // - docs/insights-archive-sample/config/certificatesigningrequests/csr-test.json
 //
 // ### Location in archive
-// - `config/certificatesigningrequests`
+// - `config/certificatesigningrequests/{name}.json`
 //
 // ### Config ID
 // `clusterconfig/certificate_signing_requests`
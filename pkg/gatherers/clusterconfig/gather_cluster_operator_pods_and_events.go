@@ -66,7 +66,8 @@ var stackTraceRegex = regexp.MustCompile(`\.go:\d+\s\+0x`)
 // ### Location in archive
 // - `config/pod/{namespace}/{pod}.json`
 // - `events/{namespace}.json`
-// - `config/pod/{namespace}/logs/{pod}/{container}_{current|previous}.log`
+// - `config/pod/{namespace}/logs/{pod}/{container}_current.log`
+// - `config/pod/{namespace}/logs/{pod}/{container}_previous.log`
 //
 // ### Config ID
 // `clusterconfig/operators_pods_and_events`
@@ -212,11 +213,11 @@ func gatherNamespaceEvents(ctx context.Context,
 		return nil, err
 	}
 	// filter the event list to only recent events
-	filteredEvents := getEventsForInterval(interval, events)
+	filteredEvents := GetEventsForInterval(interval, events)
 	if len(filteredEvents.Items) == 0 {
 		return nil, nil
 	}
-	compactedEvents := eventListToCompactedEventList(&filteredEvents)
+	compactedEvents := EventListToCompactedEventList(&filteredEvents)
 
 	return []record.Record{{Name: fmt.Sprintf("events/%s", namespace), Item: record.JSONMarshaller{Object: &compactedEvents}}}, nil
 }
@@ -50,8 +50,10 @@ type clusterOperatorResource struct {
 // ### Location in archive
 // | Version   | Path														|
 // | --------- | --------------------------------------------------------	|
+// | >= 4.2.0  | config/clusteroperator/{name}.json							|
 // | < 4.6.16  | config/clusteroperator/{kind}-{name}.json 					|
 // | >= 4.6.16 | config/clusteroperator/{group}/{kind}/{name}.json 			|
+// | >= 4.6.16 | config/clusteroperator/{group}/{kind}/{namespace}/{name}.json	|
 // | < 4.8.2   | config/pod/{namespace}/{pod}.json							|
 // | < 4.8.2   | events/{namespace}.json									|
 //
@@ -19,7 +19,7 @@ import (
 // - docs/insights-archive-sample/cluster-scoped-resources/rbac.authorization.k8s.io/clusterroles
 //
 // ### Location in archive
-// - `cluster-scoped-resources/rbac.authorization.k8s.io/clusterroles/`
+// - `cluster-scoped-resources/rbac.authorization.k8s.io/clusterroles/{name}.json`
 //
 // ### Config ID
 // `clusterconfig/clusterroles`
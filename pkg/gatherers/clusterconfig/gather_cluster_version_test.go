@@ -48,7 +48,7 @@ func Test_getClusterVersion(t *testing.T) {
 		LastTimestamp: lastTimestampEvent,
 		Count:         1,
 	}
-	compactEvents := eventListToCompactedEventList(&v1.EventList{
+	compactEvents := EventListToCompactedEventList(&v1.EventList{
 		Items: []v1.Event{event},
 	})
 
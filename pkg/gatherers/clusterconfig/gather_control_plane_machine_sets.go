@@ -21,8 +21,8 @@ import (
 // - docs/insights-archive-sample/config/controlplanemachinesets/openshift-machine-api/cluster.json
 //
 // ### Location in archive
-// - `config/controlplanemachinesets/{resource}`
-// - `config/controlplanemachinesets/{namespace}/{resource}`
+// - `config/controlplanemachinesets/{name}.json`
+// - `config/controlplanemachinesets/{namespace}/{name}.json`
 //
 // ### Config ID
 // `clusterconfig/control_plane_machine_sets`
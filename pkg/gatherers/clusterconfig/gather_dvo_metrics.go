@@ -7,7 +7,6 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-	"regexp"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -18,6 +17,7 @@ import (
 	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/klog/v2"
 
+	"github.com/openshift/insights-operator/pkg/anonymization"
 	"github.com/openshift/insights-operator/pkg/config"
 	"github.com/openshift/insights-operator/pkg/record"
 	"github.com/openshift/insights-operator/pkg/types"
@@ -131,7 +131,7 @@ func gatherDVOMetrics(
 	}
 
 	return []record.Record{
-		{Name: "config/dvo_metrics", Item: marshal.RawByte(allDVOMetricsLines)},
+		{Name: anonymization.DVOMetricsRecordName, Item: marshal.RawByte(allDVOMetricsLines)},
 	}, errors
 }
 
@@ -176,19 +176,7 @@ func gatherDVOMetricsFromEndpoint(
 
 	var f func(b []byte) []byte
 	if useUIDs {
-		// precompile regex rules
-		regexString := `(?m)(,?%s=[^\,\}]*")`
-		filterProps := []*regexp.Regexp{
-			regexp.MustCompile(fmt.Sprintf(regexString, "name")),
-			regexp.MustCompile(fmt.Sprintf(regexString, "namespace")),
-		}
-		f = func(b []byte) []byte {
-			for _, re := range filterProps {
-				str := re.ReplaceAllString(string(b), "")
-				b = []byte(str)
-			}
-			return b
-		}
+		f = anonymization.RemoveWorkloadNames
 	}
 	prefixedLines, err := utils.ReadAllLinesWithPrefix(dataReader, dvoMetricsPrefix, f)
 	if err != io.EOF {
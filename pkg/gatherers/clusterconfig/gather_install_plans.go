@@ -44,7 +44,7 @@ type installPlanAnonymizer struct {
 // - docs/insights-archive-sample/config/installplans.json
 //
 // ### Location in archive
-// - `config/instalplans.json`
+// - `config/installplans.json`
 //
 // ### Config ID
 // `clusterconfig/install_plans`
@@ -19,7 +19,7 @@ import (
 // - docs/insights-archive-sample/config/jaegertracing.io/jaeger1.json
 //
 // ### Location in archive
-// - `config/jaegers.jaegertracing.io/{name}.json`
+// - `config/jaegertracing.io/{name}.json`
 //
 // ### Config ID
 // `clusterconfig/jaegers`
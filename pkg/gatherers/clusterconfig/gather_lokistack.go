@@ -29,7 +29,7 @@ const lokiStackResourceLimit = 20
 // None
 //
 // ### Sample data
-// - docs/insights-archive-sample/namespaces/openshift-logging/lokistack-sample.json
+// - docs/insights-archive-sample/namespace/openshift-logging/loki.grafana.com/lokistacks/lokistack-sample.json
 //
 // ### Location in archive
 // - `namespace/{namespace}/loki.grafana.com/lokistacks/{name}.json`
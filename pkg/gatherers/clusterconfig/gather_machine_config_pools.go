@@ -23,7 +23,7 @@ import (
 // - docs/insights-archive-sample/config/machineconfigpools
 //
 // ### Location in archive
-// - `config/machineconfigpools`
+// - `config/machineconfigpools/{name}.json`
 //
 // ### Config ID
 // `clusterconfig/machine_config_pools`
@@ -41,7 +41,7 @@ type UnusedMachineConfigsCount struct {
 //
 // ### Location in archive
 // - `aggregated/unused_machine_configs_count.json`
-// - `config/machineconfigs/{resource}.json`
+// - `config/machineconfigs/{name}.json`
 //
 // ### Config ID
 // `clusterconfig/machine_configs`
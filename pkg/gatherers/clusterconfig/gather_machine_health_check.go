@@ -21,7 +21,8 @@ import (
 // - docs/insights-archive-sample/config/machinehealthchecks/openshift-machine-api/machine-api-termination-handler.json
 //
 // ### Location in archive
-// - `config/machinehealthchecks/{namespace}/{resource}.json`
+// - `config/machinehealthchecks/{name}.json`
+// - `config/machinehealthchecks/{namespace}/{name}.json`
 //
 // ### Config ID
 // `clusterconfig/machine_healthchecks`
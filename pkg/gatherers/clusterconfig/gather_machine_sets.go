@@ -24,7 +24,8 @@ import (
 // - docs/insights-archive-sample/d50d0126-c90b-4428-a75f-dc08cd02960a-worker-test
 //
 // ### Location in archive
-// - `config//machinesets/{resource}`
+// - `machinesets/{name}.json`
+// - `machinesets/{namespace}/{name}.json`
 //
 // ### Config ID
 // `clusterconfig/machine_sets`
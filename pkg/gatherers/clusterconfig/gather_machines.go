@@ -23,7 +23,8 @@ import (
 // - docs/insights-archive-sample/config/machines/openshift-machine-api/
 //
 // ### Location in archive
-// - `config/machines/`
+// - `config/machines/{name}.json`
+// - `config/machines/{namespace}/{name}.json`
 //
 // ### Config ID
 // `clusterconfig/machines`
@@ -2,6 +2,7 @@ package clusterconfig
 
 import (
 	"bufio"
+	"bytes"
 	"compress/gzip"
 	"context"
 	"fmt"
@@ -35,6 +36,9 @@ import (
 //   - CreateContainerError: context deadline exceeded
 //   - rpc error: code = ResourceExhausted desc = grpc: received message larger than max
 //
+// The continuation lines (e.g. stack traces) and the lines before and after the matching lines can be kept too,
+// see the `dataReporting/nodeLogs` configuration of the `insights-config` ConfigMap.
+//
 // ### API Reference
 // - https://docs.openshift.com/container-platform/4.9/rest_api/node_apis/node-core-v1.html#apiv1nodesnameproxypath
 //
@@ -42,7 +46,7 @@ import (
 // - docs/insights-archive-sample/config/nodes/logs
 //
 // ### Location in archive
-// - `config/nodes/logs/{hostname}.log`
+// - `config/node/logs/{hostname}.log`
 //
 // ### Config ID
 // `clusterconfig/node_logs`
@@ -54,34 +58,44 @@ import (
 // None
 //
 // ### Changes
-// None
+// - Optional continuation lines and context lines of the matching lines
 func (g *Gatherer) GatherNodeLogs(ctx context.Context) ([]record.Record, []error) {
 	clientSet, err := kubernetes.NewForConfig(g.gatherProtoKubeConfig)
 	if err != nil {
 		return nil, []error{err}
 	}
-	return gatherNodeLogs(ctx, clientSet.CoreV1())
+	nodeLogsConfig := g.config().DataReporting.NodeLogs
+	linesContext := common.LogLinesContext{
+		Before:       nodeLogsConfig.ContextBefore,
+		After:        nodeLogsConfig.ContextAfter,
+		Continuation: nodeLogsConfig.ContinuationLines,
+	}
+	return gatherNodeLogs(ctx, clientSet.CoreV1(), linesContext)
 }
 
-func gatherNodeLogs(ctx context.Context, client corev1client.CoreV1Interface) ([]record.Record, []error) {
+func gatherNodeLogs(
+	ctx context.Context, client corev1client.CoreV1Interface, linesContext common.LogLinesContext,
+) ([]record.Record, []error) {
 	nodes, err := client.Nodes().List(ctx, metav1.ListOptions{LabelSelector: "node-role.kubernetes.io/master"})
 	if err != nil {
 		return nil, []error{err}
 	}
-	return nodeLogRecords(ctx, client.RESTClient(), nodes)
+	return nodeLogRecords(ctx, client.RESTClient(), nodes, linesContext)
 }
 
 // nodeLogRecords generate the records and errors list
-func nodeLogRecords(ctx context.Context, restClient rest.Interface, nodes *corev1.NodeList) ([]record.Record, []error) {
+func nodeLogRecords(
+	ctx context.Context, restClient rest.Interface, nodes *corev1.NodeList, linesContext common.LogLinesContext,
+) ([]record.Record, []error) {
 	var errs []error
 	records := make([]record.Record, 0)
 
 	for i := range nodes.Items {
 		name := nodes.Items[i].Name
-		uri := nodeLogResourceURI(restClient, name)
-		req := requestNodeLog(restClient, uri, logNodeMaxTailLines, logNodeUnit)
+		uri := NodeLogResourceURI(restClient, name)
+		req := RequestNodeLog(restClient, uri, logNodeMaxTailLines, logNodeUnit)
 
-		logString, err := nodeLogString(ctx, req)
+		logString, err := nodeLogString(ctx, req, linesContext)
 		if err != nil {
 			klog.V(2).Infof("Error: %q", err)
 			errs = append(errs, err)
@@ -96,16 +110,16 @@ func nodeLogRecords(ctx context.Context, restClient rest.Interface, nodes *corev
 	return records, errs
 }
 
-// nodeLogResourceURI creates the resource path URI to be fetched
-func nodeLogResourceURI(client rest.Interface, name string) string {
+// NodeLogResourceURI creates the resource path URI to be fetched
+func NodeLogResourceURI(client rest.Interface, name string) string {
 	return client.Get().
 		Name(name).
 		Resource("nodes").SubResource("proxy", "logs").
 		Suffix("journal").URL().Path
 }
 
-// requestNodeLog creates the request to the API to retrieve the resource stream
-func requestNodeLog(client rest.Interface, uri string, tail int, unit string) *rest.Request {
+// RequestNodeLog creates the request to the API to retrieve the resource stream
+func RequestNodeLog(client rest.Interface, uri string, tail int, unit string) *rest.Request {
 	return client.Get().RequestURI(uri).
 		SetHeader("Accept", "text/plain, */*").
 		SetHeader("Accept-Encoding", "gzip").
@@ -113,8 +127,19 @@ func requestNodeLog(client rest.Interface, uri string, tail int, unit string) *r
 		Param("unit", unit)
 }
 
+// gzipHeader are the first bytes of the gzip compressed data
+var gzipHeader = []byte{0x1f, 0x8b}
+
 // nodeLogString retrieve the data from the stream, decompress it (if necessary) and return the string
-func nodeLogString(ctx context.Context, req *rest.Request) (string, error) {
+func nodeLogString(ctx context.Context, req *rest.Request, linesContext common.LogLinesContext) (string, error) {
+	return ReadNodeLog(ctx, req, nodeLogsMessagesFilter(), linesContext, logNodeMaxLines)
+}
+
+// ReadNodeLog retrieves the node log from the request stream, decompresses it (if necessary)
+// and returns at most the last maxLines lines matching any of the regular expressions
+// together with the lines of their context defined by the linesContext
+func ReadNodeLog(ctx context.Context, req *rest.Request, messagesToSearch []string,
+	linesContext common.LogLinesContext, maxLines int) (string, error) {
 	in, err := req.Stream(ctx)
 	if err != nil {
 		return "", err
@@ -125,10 +150,13 @@ func nodeLogString(ctx context.Context, req *rest.Request) (string, error) {
 		}
 	}()
 
-	var reader io.Reader
-	if r, err := gzip.NewReader(in); err != nil {
+	// the gzip reader consumes the buffered data even if it fails, so the stream is checked for the gzip header first
+	buffered := bufio.NewReader(in)
+	var reader io.Reader = buffered
+	if header, _ := buffered.Peek(2); !bytes.Equal(header, gzipHeader) {
+		klog.Warningf("the node log is not compressed. Reading uncompressed data.")
+	} else if r, err := gzip.NewReader(buffered); err != nil {
 		klog.Warningf("failed to create gzip reader: %v. Reading uncompressed data.", err)
-		reader = in
 	} else {
 		defer func() {
 			if closeErr := r.Close(); closeErr != nil {
@@ -139,10 +167,9 @@ func nodeLogString(ctx context.Context, req *rest.Request) (string, error) {
 	}
 	scanner := bufio.NewScanner(reader)
 
-	messagesToSearch := nodeLogsMessagesFilter()
-	return common.FilterLogFromScanner(scanner, messagesToSearch, true, func(lines []string) []string {
-		if len(lines) > logNodeMaxLines {
-			return lines[len(lines)-logNodeMaxLines:]
+	return common.FilterLogWithContextFromScanner(scanner, messagesToSearch, true, linesContext, func(lines []string) []string {
+		if len(lines) > maxLines {
+			return lines[len(lines)-maxLines:]
 		}
 		return lines
 	})
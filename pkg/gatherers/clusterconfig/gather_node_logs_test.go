@@ -20,6 +20,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	"k8s.io/client-go/rest"
+
+	"github.com/openshift/insights-operator/pkg/gatherers/common"
 )
 
 func Test_nodeLogRecords(t *testing.T) {
@@ -31,7 +33,7 @@ func Test_nodeLogRecords(t *testing.T) {
 	nodes, err := readNodeTestData()
 	mustNotFail(t, err, "error creating test data %+v")
 
-	records, errs := nodeLogRecords(context.TODO(), rc, nodes)
+	records, errs := nodeLogRecords(context.TODO(), rc, nodes, common.LogLinesContext{})
 	if len(errs) > 0 {
 		t.Errorf("unexpected errors: %#v", errs)
 	}
@@ -45,7 +47,7 @@ func Test_nodeLogRecords(t *testing.T) {
 	}
 }
 
-func Test_nodeLogResourceURI(t *testing.T) {
+func Test_NodeLogResourceURI(t *testing.T) {
 	c, _ := rest.NewRESTClient(&url.URL{Path: ""}, "", rest.ClientContentConfig{}, nil, nil)
 
 	type args struct {
@@ -68,8 +70,8 @@ func Test_nodeLogResourceURI(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := nodeLogResourceURI(tt.args.client, tt.args.name); got != tt.want {
-				t.Errorf("nodeLogResourceURI() = %v, want %v", got, tt.want)
+			if got := NodeLogResourceURI(tt.args.client, tt.args.name); got != tt.want {
+				t.Errorf("NodeLogResourceURI() = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -113,7 +115,7 @@ Aug 26 17:00:14 ip-10-57-11-201 hyperkube[1445]: E0826 17:00:14.128025    1445 k
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := nodeLogString(context.TODO(), tt.args.req)
+			got, err := nodeLogString(context.TODO(), tt.args.req, common.LogLinesContext{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("nodeLogString() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -125,7 +127,7 @@ Aug 26 17:00:14 ip-10-57-11-201 hyperkube[1445]: E0826 17:00:14.128025    1445 k
 	}
 }
 
-func Test_requestNodeLog(t *testing.T) {
+func Test_RequestNodeLog(t *testing.T) {
 	c, err := rest.NewRESTClient(&url.URL{}, "", rest.ClientContentConfig{}, nil, nil)
 	assert.NoErrorf(t, err, "unable to create the rest client")
 	type args struct {
@@ -157,7 +159,7 @@ func Test_requestNodeLog(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := requestNodeLog(tt.args.client, tt.args.uri, tt.args.tail, tt.args.unit)
+			got := RequestNodeLog(tt.args.client, tt.args.uri, tt.args.tail, tt.args.unit)
 
 			// This is not very nice. This reads unexported parameters of the *rest.Request type.
 			// Previously we simply checked reflect.DeepEqual(got, tt.want) but it started to fail
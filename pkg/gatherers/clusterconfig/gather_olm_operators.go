@@ -40,7 +40,7 @@ type csvRef struct {
 // - docs/insights-archive-sample/config/olm_operators.json
 //
 // ### Location in archive
-// - `config/olm_operators`
+// - `config/olm_operators.json`
 //
 // ### Config ID
 // `clusterconfig/olm_operators`
@@ -54,13 +54,13 @@ func gatherOpenshiftMachineAPIEvents(ctx context.Context,
 		return nil, err
 	}
 	// filter the event list to only recent events with type different from "Normal"
-	filteredEvents := getEventsForInterval(interval, events)
-	filteredEvents = filterAbnormalEvents(&filteredEvents)
+	filteredEvents := GetEventsForInterval(interval, events)
+	filteredEvents = FilterAbnormalEvents(&filteredEvents)
 
 	if len(filteredEvents.Items) == 0 {
 		return nil, nil
 	}
-	compactedEvents := eventListToCompactedEventList(&filteredEvents)
+	compactedEvents := EventListToCompactedEventList(&filteredEvents)
 
 	return []record.Record{{Name: "events/openshift-machine-api", Item: record.JSONMarshaller{Object: &compactedEvents}}}, nil
 }
@@ -52,7 +52,7 @@ func Test_WarningEvents_gatherOpenshiftMachineAPIEvents(t *testing.T) {
 	}
 	var events v1.EventList
 	events.Items = append(events.Items, warningEvent1, warningEvent2)
-	compactedEvents := eventListToCompactedEventList(&events)
+	compactedEvents := EventListToCompactedEventList(&events)
 
 	type args struct {
 		ctx        context.Context
@@ -25,7 +25,7 @@ import (
 // - docs/insights-archive-sample/namespaces/openstack/dataplane.openstack.org/openstackdataplanenodesets/openstack-edpm.json
 //
 // ### Location in archive
-// - `namespaces/{namespace}/dataplane.openstack.org/openstackdataplanes/{name}.json`
+// - `namespaces/{namespace}/dataplane.openstack.org/openstackdataplanenodesets/{name}.json`
 //
 // ### Config ID
 // `clusterconfig/openstack_dataplanenodesets`
@@ -19,10 +19,10 @@ import (
 // None
 //
 // ### Sample data
-// - docs/insights-archive-sample/namespaces/openstack/core.openstack.org/openstackversion/openstack-galera-network-isolation.json
+// - docs/insights-archive-sample/namespaces/openstack/core.openstack.org/openstackversions/openstack-galera-network-isolation.json
 //
 // ### Location in archive
-// - `namespaces/{namespace}/core.openstack.org/openstackversion/{name}.json`
+// - `namespaces/{namespace}/core.openstack.org/openstackversions/{name}.json`
 //
 // ### Config ID
 // `clusterconfig/openstack_version`
@@ -27,7 +27,7 @@ import (
 //
 // ### Location in archive
 // - `config/clusterrolebinding/{name}.json`
-// - `config/securitycontentconstraint/{name}.json`
+// - `config/securitycontextconstraint/{name}.json`
 //
 // ### Config ID
 // `clusterconfig/sap_config`
@@ -21,6 +21,7 @@ import (
 // ### Sample data
 // - docs/insights-archive-sample/config/storage/openshift-storage/storageclusters/ocs-storagecluster.json
 //
+// ### Location in archive
 // | Version   | Path														|
 // | --------- | --------------------------------------------------------	|
 // | < 4.12.0  | config/storage/{namespace}/{name}.json 					|
@@ -0,0 +1,81 @@
+package common
+
+import (
+	"regexp"
+)
+
+// AggregatedLogMessage is a distinct log message template together with the number of its occurrences
+type AggregatedLogMessage struct {
+	Template string `json:"template"`
+	Count    int    `json:"count"`
+	// FirstTimestamp and LastTimestamp are the timestamps added to the log lines by the kubelet, if any
+	FirstTimestamp string `json:"first_timestamp,omitempty"`
+	LastTimestamp  string `json:"last_timestamp,omitempty"`
+}
+
+// volatileTokens are the replacements of the log message parts which differ between the occurrences
+// of the same message. The order matters, the timestamps must be replaced before the times of day
+// and the times of day before the IPv6 addresses.
+var volatileTokens = []struct {
+	regex       *regexp.Regexp
+	placeholder string
+}{
+	{
+		regex:       regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`),
+		placeholder: "<TIMESTAMP>",
+	},
+	{regex: regexp.MustCompile(`\b\d{2}:\d{2}:\d{2}(\.\d+)?\b`), placeholder: "<TIME>"},
+	{
+		regex:       regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`),
+		placeholder: "<UUID>",
+	},
+	{regex: regexp.MustCompile(`\b(\d{1,3}\.){3}\d{1,3}(:\d{1,5})?\b`), placeholder: "<IP>"},
+	{regex: regexp.MustCompile(`(?i)\b[0-9a-f]{1,4}(:[0-9a-f]{0,4}){2,7}\b`), placeholder: "<IP>"},
+}
+
+// NormalizeLogMessage replaces the timestamps, UUIDs and IP addresses in the log message with the placeholders,
+// so that the occurrences of the same message share the same template
+func NormalizeLogMessage(message string) string {
+	for _, token := range volatileTokens {
+		message = token.regex.ReplaceAllString(message, token.placeholder)
+	}
+	return message
+}
+
+// AggregateLogLines groups the log lines by their normalized message templates. The templates are
+// returned in the order of their first occurrence. The timestamps added by the kubelet are split
+// from the lines and used as the first and the last timestamps of the templates.
+func AggregateLogLines(lines []string) []AggregatedLogMessage {
+	var messages []AggregatedLogMessage
+	templateIndexes := make(map[string]int)
+
+	for _, line := range lines {
+		timestamp, message := splitLogTimestamp(line)
+		if message == "" {
+			continue
+		}
+		template := NormalizeLogMessage(message)
+
+		i, ok := templateIndexes[template]
+		if !ok {
+			templateIndexes[template] = len(messages)
+			messages = append(messages, AggregatedLogMessage{
+				Template:       template,
+				Count:          1,
+				FirstTimestamp: timestamp,
+				LastTimestamp:  timestamp,
+			})
+			continue
+		}
+
+		messages[i].Count++
+		if timestamp != "" {
+			if messages[i].FirstTimestamp == "" {
+				messages[i].FirstTimestamp = timestamp
+			}
+			messages[i].LastTimestamp = timestamp
+		}
+	}
+
+	return messages
+}
@@ -0,0 +1,64 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NormalizeLogMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{
+			name:    "timestamp and time of day",
+			message: "E0501 10:00:03.000000 1 sync failed at 2024-05-01T10:00:03.123Z",
+			want:    "E0501 <TIME> 1 sync failed at <TIMESTAMP>",
+		},
+		{
+			name:    "UUID",
+			message: "pod 1b4e28ba-2fa1-11d2-883f-0016d3cca427 not found",
+			want:    "pod <UUID> not found",
+		},
+		{
+			name:    "IPv4 and IPv6 addresses",
+			message: "dial tcp 10.0.0.1:6443 and fd00::1: connection refused",
+			want:    "dial tcp <IP> and <IP>: connection refused",
+		},
+		{name: "nothing to replace", message: "sync failed", want: "sync failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NormalizeLogMessage(tt.message))
+		})
+	}
+}
+
+func Test_AggregateLogLines(t *testing.T) {
+	lines := []string{
+		"2024-05-01T10:00:00.000000000Z dial tcp 10.0.0.1:6443: connection refused",
+		"2024-05-01T10:00:01.000000000Z started",
+		"2024-05-01T10:00:02.000000000Z dial tcp 10.0.0.2:6443: connection refused",
+		"",
+		"dial tcp 10.0.0.3:6443: connection refused",
+		"2024-05-01T10:00:04.000000000Z dial tcp 10.0.0.4:6443: connection refused",
+	}
+
+	assert.Equal(t, []AggregatedLogMessage{
+		{
+			Template:       "dial tcp <IP>: connection refused",
+			Count:          4,
+			FirstTimestamp: "2024-05-01T10:00:00.000000000Z",
+			LastTimestamp:  "2024-05-01T10:00:04.000000000Z",
+		},
+		{
+			Template:       "started",
+			Count:          1,
+			FirstTimestamp: "2024-05-01T10:00:01.000000000Z",
+			LastTimestamp:  "2024-05-01T10:00:01.000000000Z",
+		},
+	}, AggregateLogLines(lines))
+	assert.Empty(t, AggregateLogLines(nil))
+}
@@ -3,6 +3,7 @@ package common
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
 	"regexp"
 	"strings"
@@ -24,6 +25,8 @@ type LogContainersFilter struct {
 	FieldSelector            string
 	ContainerNameRegexFilter string
 	MaxNamespaceContainers   int
+	// LogBudget is the log byte budget shared with the other log gathering functions, the logs are not limited when nil
+	LogBudget *SharedLogBudget
 }
 
 // LogMessagesFilter allows you to filter messages
@@ -34,19 +37,36 @@ type LogMessagesFilter struct {
 	LimitBytes       int64
 	TailLines        int64
 	Previous         bool
+	// FieldFilters enables the parsing of the structured (JSON or logfmt) log lines,
+	// only the lines matching all the filters are kept and they are stored as the JSON records
+	FieldFilters []LogFieldFilter
+	// Aggregate stores the distinct message templates of the matching lines with their counts
+	// as the JSON array of AggregatedLogMessage instead of the lines. It is ignored when the FieldFilters are set.
+	Aggregate bool
 }
 
-// ContainersSkippedError represents an error when containers are skipped due to limits
+// ContainersSkippedError represents an error when containers are skipped
+// or the log bytes are dropped due to limits
 type ContainersSkippedError struct {
 	Namespace              string
 	MaxNamespaceContainers int
 	SkippedContainers      int
+	MaxLogBytes            int64
+	DroppedBytes           int64
 }
 
 // Error implements the error interface
 func (e *ContainersSkippedError) Error() string {
-	return fmt.Sprintf("skipping %d containers on namespace %s (max: %d)",
-		e.SkippedContainers, e.Namespace, e.MaxNamespaceContainers)
+	var msgs []string
+	if e.SkippedContainers > 0 {
+		msgs = append(msgs, fmt.Sprintf("skipping %d containers on namespace %s (max: %d)",
+			e.SkippedContainers, e.Namespace, e.MaxNamespaceContainers))
+	}
+	if e.DroppedBytes > 0 {
+		msgs = append(msgs, fmt.Sprintf("dropping %d log bytes on namespace %s (max: %d)",
+			e.DroppedBytes, e.Namespace, e.MaxLogBytes))
+	}
+	return strings.Join(msgs, ", ")
 }
 
 // CollectLogsFromContainers collects logs from containers
@@ -55,6 +75,8 @@ func (e *ContainersSkippedError) Error() string {
 //   - labelSelector to filter pods by their labels (keep empty to not filter)
 //   - containerNameRegexFilter to filter containers in the pod (keep empty to not filter)
 //   - maxNamespaceContainers to limit the containers in the given namespace (keep empty to not limit)
+//   - logBudget to limit the size of all the logs (keep empty to not limit), the share of the function is split fairly
+//     by the namespaces and containers and the oldest lines are dropped, it's ignored for the aggregated logs
 //   - logMessagesFilter allows you to specify
 //   - messagesToSearch to filter the logs by substrings (case-insensitive)
 //     or regex (add `(?i)` in the beginning to make search case-insensitive). Leave nil to not filter.
@@ -62,6 +84,10 @@ func (e *ContainersSkippedError) Error() string {
 //   - sinceSeconds which sets the moment to fetch the logs from (current time - sinceSeconds)
 //   - limitBytes which sets the maximum amount of logs that can be fetched
 //   - tailLines which sets the maximum amount of log lines from the end that should be fetched
+//   - fieldFilters which filters the structured (JSON or logfmt) log lines by the values of their fields,
+//     the matching lines are stored as the StructuredLogEntry JSON records (one per line)
+//   - aggregate which stores the distinct message templates of the matching lines with their counts
+//     and the first and last timestamps instead of the lines
 //   - buildLogFileName is the function returning filename for the current log,
 //     if nil, the default implementation is used
 //
@@ -91,6 +117,7 @@ func CollectLogsFromContainers( //nolint:gocyclo
 
 	var skippedContainers int
 	var records []record.Record
+	var logs []ContainerLog
 
 	for i := range pods.Items {
 		var containerNames []string
@@ -127,16 +154,17 @@ func CollectLogsFromContainers( //nolint:gocyclo
 
 			request := coreClient.Pods(pod.Namespace).GetLogs(pod.Name, podLogOptions(containerName, messagesFilter))
 
-			logs, err := filterLogs(ctx, request, messagesFilter.MessagesToSearch, messagesFilter.IsRegexSearch)
+			log, err := filterLogs(ctx, request, &messagesFilter)
 			if err != nil {
 				return nil, err
 			}
 
-			if len(strings.TrimSpace(logs)) != 0 {
+			if len(strings.TrimSpace(log)) != 0 {
 				records = append(records, record.Record{
 					Name: buildLogFileName(pod.Namespace, pod.Name, containerName),
-					Item: marshal.Raw{Str: logs},
+					Item: marshal.Raw{Str: log},
 				})
+				logs = append(logs, ContainerLog{Namespace: pod.Namespace, Log: []byte(log)})
 			}
 		}
 	}
@@ -145,20 +173,36 @@ func CollectLogsFromContainers( //nolint:gocyclo
 		klog.Infof("no pods in %v namespace were found", containersFilter.Namespace)
 	}
 
-	if skippedContainers > 0 {
+	var droppedBytes int64
+	if containersFilter.LogBudget != nil && !messagesFilter.Aggregate {
+		for _, dropped := range containersFilter.LogBudget.Apply(logs) {
+			droppedBytes += dropped
+		}
+		budgetedRecords := records[:0]
+		for i := range records {
+			if len(logs[i].Log) == 0 {
+				continue
+			}
+			records[i].Item = marshal.Raw{Str: string(logs[i].Log)}
+			budgetedRecords = append(budgetedRecords, records[i])
+		}
+		records = budgetedRecords
+	}
+
+	if skippedContainers > 0 || droppedBytes > 0 {
 		return records, &ContainersSkippedError{
 			Namespace:              containersFilter.Namespace,
 			MaxNamespaceContainers: containersFilter.MaxNamespaceContainers,
 			SkippedContainers:      skippedContainers,
+			MaxLogBytes:            containersFilter.LogBudget.Share(),
+			DroppedBytes:           droppedBytes,
 		}
 	}
 
 	return records, nil
 }
 
-func filterLogs(
-	ctx context.Context, request *restclient.Request, messagesToSearch []string, regexSearch bool,
-) (string, error) {
+func filterLogs(ctx context.Context, request *restclient.Request, messagesFilter *LogMessagesFilter) (string, error) {
 	stream, err := request.Stream(ctx)
 	if err != nil {
 		return "", err
@@ -172,7 +216,31 @@ func filterLogs(
 	}()
 
 	scanner := bufio.NewScanner(stream)
-	return FilterLogFromScanner(scanner, messagesToSearch, regexSearch, nil)
+	if len(messagesFilter.FieldFilters) > 0 {
+		return FilterStructuredLogFromScanner(
+			scanner, messagesFilter.MessagesToSearch, messagesFilter.IsRegexSearch, messagesFilter.FieldFilters,
+		)
+	}
+	var cb func(lines []string) []string
+	if messagesFilter.Aggregate {
+		cb = aggregateLinesToJSON
+	}
+	return FilterLogFromScanner(scanner, messagesFilter.MessagesToSearch, messagesFilter.IsRegexSearch, cb)
+}
+
+// aggregateLinesToJSON aggregates the log lines and returns the aggregated messages as a single JSON line.
+// No line is returned when there is nothing to aggregate.
+func aggregateLinesToJSON(lines []string) []string {
+	messages := AggregateLogLines(lines)
+	if len(messages) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(messages)
+	if err != nil {
+		klog.Errorf("unable to marshal the aggregated log messages: %v", err)
+		return nil
+	}
+	return []string{string(data)}
 }
 
 // FilterLogFromScanner filters the desired messages from the log
@@ -0,0 +1,142 @@
+package common
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+)
+
+// ContainerLog is the log of a single container competing for the shared log budget
+type ContainerLog struct {
+	Namespace string
+	Log       []byte
+}
+
+// SharedLogBudget is the log byte budget shared by the log gathering functions running concurrently.
+// Every function joins the budget when it is created, before the functions run, and the budget is split
+// equally among the joined functions, so the share of a function doesn't depend on the order in which
+// the functions finish. The logs not fitting into the share of their function are truncated fairly.
+type SharedLogBudget struct {
+	lock      sync.Mutex
+	maxBytes  int64
+	functions int
+}
+
+// NewSharedLogBudget creates the log budget of the maxBytes bytes
+func NewSharedLogBudget(maxBytes int64) *SharedLogBudget {
+	return &SharedLogBudget{maxBytes: maxBytes}
+}
+
+// MaxBytes returns the total size of the budget, it is zero for the nil budget
+func (b *SharedLogBudget) MaxBytes() int64 {
+	if b == nil {
+		return 0
+	}
+	return b.maxBytes
+}
+
+// Join registers a log gathering function sharing the budget, it must be called before the functions run.
+// It does nothing for the nil budget.
+func (b *SharedLogBudget) Join() {
+	if b == nil {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.functions++
+}
+
+// Share returns the bytes of the budget available to every joined function, it is zero for the nil budget
+func (b *SharedLogBudget) Share() int64 {
+	if b == nil {
+		return 0
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.maxBytes / int64(max(b.functions, 1))
+}
+
+// Apply truncates the logs of a function to fit into its share of the budget (see ApplyLogBudget).
+// The number of the dropped bytes is returned per namespace.
+func (b *SharedLogBudget) Apply(logs []ContainerLog) map[string]int64 {
+	return ApplyLogBudget(b.Share(), logs)
+}
+
+// ApplyLogBudget truncates the logs so that together they fit into the totalBytes budget.
+// The budget is split fairly across the namespaces first and then across the containers of every namespace.
+// The budget left unused by the quiet namespaces and containers is shared by the noisy ones.
+// The oldest lines of the truncated logs are dropped, the number of the dropped bytes is returned per namespace.
+func ApplyLogBudget(totalBytes int64, logs []ContainerLog) map[string]int64 {
+	var namespaces []string
+	namespaceLogs := make(map[string][]int)
+	for i := range logs {
+		ns := logs[i].Namespace
+		if _, ok := namespaceLogs[ns]; !ok {
+			namespaces = append(namespaces, ns)
+		}
+		namespaceLogs[ns] = append(namespaceLogs[ns], i)
+	}
+
+	namespaceDemands := make([]int64, len(namespaces))
+	for i, ns := range namespaces {
+		for _, logIndex := range namespaceLogs[ns] {
+			namespaceDemands[i] += int64(len(logs[logIndex].Log))
+		}
+	}
+
+	droppedBytes := make(map[string]int64)
+	for i, namespaceShare := range fairShares(totalBytes, namespaceDemands) {
+		ns := namespaces[i]
+		demands := make([]int64, len(namespaceLogs[ns]))
+		for j, logIndex := range namespaceLogs[ns] {
+			demands[j] = int64(len(logs[logIndex].Log))
+		}
+
+		for j, share := range fairShares(namespaceShare, demands) {
+			logIndex := namespaceLogs[ns][j]
+			truncated := truncateLogHead(logs[logIndex].Log, share)
+			if dropped := int64(len(logs[logIndex].Log) - len(truncated)); dropped > 0 {
+				droppedBytes[ns] += dropped
+			}
+			logs[logIndex].Log = truncated
+		}
+	}
+	return droppedBytes
+}
+
+// fairShares splits the total among the demands so that no demand gets more than it asks for
+// and the part of the total not used by the small demands is split equally among the bigger ones
+func fairShares(total int64, demands []int64) []int64 {
+	indexes := make([]int, len(demands))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return demands[indexes[i]] < demands[indexes[j]]
+	})
+
+	shares := make([]int64, len(demands))
+	remaining := total
+	for k, i := range indexes {
+		share := min(demands[i], remaining/int64(len(indexes)-k))
+		shares[i] = share
+		remaining -= share
+	}
+	return shares
+}
+
+// truncateLogHead drops the oldest lines of the log so that it is at most maxBytes long
+func truncateLogHead(log []byte, maxBytes int64) []byte {
+	if int64(len(log)) <= maxBytes {
+		return log
+	}
+	start := int64(len(log)) - maxBytes
+	if log[start-1] != '\n' {
+		i := bytes.IndexByte(log[start:], '\n')
+		if i < 0 {
+			return nil
+		}
+		start += int64(i) + 1
+	}
+	return log[start:]
+}
@@ -0,0 +1,88 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_fairShares(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int64
+		demands []int64
+		want    []int64
+	}{
+		{name: "everything fits", total: 100, demands: []int64{10, 20, 30}, want: []int64{10, 20, 30}},
+		{name: "equal split", total: 90, demands: []int64{50, 50, 50}, want: []int64{30, 30, 30}},
+		{name: "unused budget goes to noisy demands", total: 100, demands: []int64{80, 10, 60}, want: []int64{45, 10, 45}},
+		{name: "no budget", total: 0, demands: []int64{10, 20}, want: []int64{0, 0}},
+		{name: "no demands", total: 100, demands: []int64{}, want: []int64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, fairShares(tt.total, tt.demands))
+		})
+	}
+}
+
+func Test_truncateLogHead(t *testing.T) {
+	log := []byte("line 1\nline 2\nline 3\n")
+	assert.Equal(t, log, truncateLogHead(log, 100))
+	assert.Equal(t, []byte("line 3\n"), truncateLogHead(log, 7))
+	assert.Equal(t, []byte("line 3\n"), truncateLogHead(log, 12))
+	assert.Equal(t, []byte("line 2\nline 3\n"), truncateLogHead(log, 14))
+	assert.Empty(t, truncateLogHead(log, 3))
+	assert.Empty(t, truncateLogHead([]byte("no new line"), 5))
+}
+
+func Test_ApplyLogBudget(t *testing.T) {
+	logs := []ContainerLog{
+		{Namespace: "quiet", Log: []byte("a\n")},
+		{Namespace: "noisy", Log: []byte("b1\nb2\nb3\nb4\n")},
+		{Namespace: "noisy", Log: []byte("c1\nc2\n")},
+	}
+
+	droppedBytes := ApplyLogBudget(14, logs)
+
+	assert.Equal(t, map[string]int64{"noisy": 6}, droppedBytes)
+	assert.Equal(t, []ContainerLog{
+		{Namespace: "quiet", Log: []byte("a\n")},
+		{Namespace: "noisy", Log: []byte("b3\nb4\n")},
+		{Namespace: "noisy", Log: []byte("c1\nc2\n")},
+	}, logs)
+}
+
+func Test_SharedLogBudget(t *testing.T) {
+	budget := NewSharedLogBudget(12)
+	assert.Equal(t, int64(12), budget.MaxBytes())
+	assert.Equal(t, int64(12), budget.Share())
+	budget.Join()
+	budget.Join()
+	assert.Equal(t, int64(6), budget.Share())
+
+	// the share of every function is the same regardless of the order in which they apply the budget
+	second := []ContainerLog{{Namespace: "second", Log: []byte("b1\nb2\nb3\nb4\n")}}
+	assert.Equal(t, map[string]int64{"second": 6}, budget.Apply(second))
+	assert.Equal(t, []byte("b3\nb4\n"), second[0].Log)
+
+	first := []ContainerLog{{Namespace: "first", Log: []byte("a1\na2\n")}}
+	assert.Empty(t, budget.Apply(first))
+	assert.Equal(t, []byte("a1\na2\n"), first[0].Log)
+
+	var nilBudget *SharedLogBudget
+	nilBudget.Join()
+	assert.Equal(t, int64(0), nilBudget.Share())
+}
+
+func Test_ContainersSkippedError(t *testing.T) {
+	err := &ContainersSkippedError{Namespace: "test", MaxNamespaceContainers: 2, SkippedContainers: 3}
+	assert.EqualError(t, err, "skipping 3 containers on namespace test (max: 2)")
+
+	err = &ContainersSkippedError{Namespace: "test", MaxLogBytes: 10, DroppedBytes: 20}
+	assert.EqualError(t, err, "dropping 20 log bytes on namespace test (max: 10)")
+
+	err.MaxNamespaceContainers, err.SkippedContainers = 2, 3
+	assert.EqualError(t, err,
+		"skipping 3 containers on namespace test (max: 2), dropping 20 log bytes on namespace test (max: 10)")
+}
@@ -0,0 +1,133 @@
+package common
+
+import (
+	"bufio"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// maxContinuationLines limits the number of the continuation lines kept after a matching line,
+// so that a log full of the lines looking like the continuation lines isn't kept whole
+const maxContinuationLines = 200
+
+// LogLinesContext defines which lines around the matching log lines are kept together with them
+type LogLinesContext struct {
+	// Before is the number of the lines kept before each matching line
+	Before int
+	// After is the number of the lines kept after each matching line (and its continuation lines)
+	After int
+	// Continuation keeps the continuation lines of the matching multi-line log entries,
+	// e.g. the frames of the Go panics or of the Java stack traces
+	Continuation bool
+}
+
+// IsEmpty returns true when only the matching lines are kept
+func (c LogLinesContext) IsEmpty() bool {
+	return c.Before <= 0 && c.After <= 0 && !c.Continuation
+}
+
+// journalLinePrefixRegex matches the prefix of the journal lines, e.g. "Aug 26 17:00:14 ip-10-57-11-201 hyperkube[1445]: "
+var journalLinePrefixRegex = regexp.MustCompile(`^[A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2} \S+ [^\s:]+: `)
+
+// continuationLineRegex matches the messages continuing the previous log entry. These are the indented
+// and empty lines, the Go panic goroutine headers and function calls and the Java exception causes.
+var continuationLineRegex = regexp.MustCompile(
+	`^(\s|$|Caused by:|Suppressed:|\.\.\. \d+ (more|common frames omitted)|goroutine \d+ \[|created by |` +
+		`[\w./*()\[\]-]+\(.*\)$|[\w.$]+(Error|Exception)\b)`,
+)
+
+// isContinuationLine checks whether the log line continues the previous log entry. The kubelet timestamp
+// and the journal prefix are not a part of the message, so they are skipped.
+func isContinuationLine(line string) bool {
+	prefix, rest, _ := strings.Cut(line, " ")
+	if _, err := time.Parse(time.RFC3339Nano, prefix); err == nil {
+		line = rest
+	}
+	if loc := journalLinePrefixRegex.FindStringIndex(line); loc != nil {
+		line = line[loc[1]:]
+	}
+	return continuationLineRegex.MatchString(line)
+}
+
+// LogContextFilter keeps the matching log lines together with the lines of their context.
+// The lines are added one by one and every line is kept at most once, even when it is in the context
+// of multiple matching lines.
+type LogContextFilter struct {
+	linesContext     LogLinesContext
+	before           []string
+	afterLeft        int
+	continuationLeft int
+	lines            []string
+}
+
+// NewLogContextFilter creates the filter keeping the lines defined by the linesContext around the matching lines
+func NewLogContextFilter(linesContext LogLinesContext) *LogContextFilter {
+	return &LogContextFilter{linesContext: linesContext}
+}
+
+// Add adds the next log line, the matches flag says whether the line matches the filtered messages
+func (f *LogContextFilter) Add(line string, matches bool) {
+	if matches {
+		f.lines = append(f.lines, f.before...)
+		f.lines = append(f.lines, line)
+		f.before = f.before[:0]
+		f.afterLeft = f.linesContext.After
+		if f.linesContext.Continuation {
+			f.continuationLeft = maxContinuationLines
+		}
+		return
+	}
+
+	if f.continuationLeft > 0 && isContinuationLine(line) {
+		f.continuationLeft--
+		f.lines = append(f.lines, line)
+		return
+	}
+	f.continuationLeft = 0
+
+	if f.afterLeft > 0 {
+		f.afterLeft--
+		f.lines = append(f.lines, line)
+		return
+	}
+
+	if f.linesContext.Before > 0 {
+		if len(f.before) == f.linesContext.Before {
+			f.before = append(f.before[:0], f.before[1:]...)
+		}
+		f.before = append(f.before, line)
+	}
+}
+
+// Lines returns the kept lines in the order they were added
+func (f *LogContextFilter) Lines() []string {
+	return f.lines
+}
+
+// FilterLogWithContextFromScanner filters the desired messages from the log the same way as FilterLogFromScanner,
+// but every matching line is kept together with the lines of its context defined by the linesContext
+func FilterLogWithContextFromScanner(scanner *bufio.Scanner, messagesToSearch []string, regexSearch bool,
+	linesContext LogLinesContext, cb func(lines []string) []string) (string, error) {
+	var messagesRegexp *regexp.Regexp
+	if regexSearch {
+		messagesRegexp = regexp.MustCompile(strings.Join(messagesToSearch, "|"))
+	}
+
+	filter := NewLogContextFilter(linesContext)
+	for scanner.Scan() {
+		line := scanner.Text()
+		filter.Add(line, matchesMessages(line, messagesToSearch, messagesRegexp))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	result := filter.Lines()
+	if cb != nil {
+		result = cb(result)
+	}
+
+	return strings.Join(result, "\n"), nil
+}
@@ -0,0 +1,95 @@
+package common
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testMultiLineLog = `2024-05-01T10:00:00.000000000Z starting
+2024-05-01T10:00:01.000000000Z loading config
+2024-05-01T10:00:02.000000000Z panic: runtime error: invalid memory address or nil pointer dereference
+2024-05-01T10:00:02.000000000Z
+2024-05-01T10:00:02.000000000Z goroutine 1 [running]:
+2024-05-01T10:00:02.000000000Z main.(*Controller).sync(0x0)
+2024-05-01T10:00:02.000000000Z 	/go/src/controller.go:42 +0x1d
+2024-05-01T10:00:03.000000000Z restarting
+2024-05-01T10:00:04.000000000Z ERROR java.lang.IllegalStateException: not ready
+2024-05-01T10:00:04.000000000Z 	at com.example.Foo.bar(Foo.java:10)
+2024-05-01T10:00:04.000000000Z Caused by: java.io.IOException: closed
+2024-05-01T10:00:04.000000000Z 	... 3 more
+2024-05-01T10:00:05.000000000Z done`
+
+func Test_isContinuationLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want bool
+	}{
+		{name: "indented frame", line: "2024-05-01T10:00:02.000000000Z \t/go/src/controller.go:42 +0x1d", want: true},
+		{name: "empty message", line: "2024-05-01T10:00:02.000000000Z ", want: true},
+		{name: "goroutine header", line: "goroutine 1 [running]:", want: true},
+		{name: "Go function call", line: "main.(*Controller).sync(0x0)", want: true},
+		{name: "Java exception cause", line: "Caused by: java.io.IOException: closed", want: true},
+		{name: "Java exception", line: "java.lang.IllegalStateException: not ready", want: true},
+		{name: "journal frame", line: "Aug 26 17:00:14 ip-10-57-11-201 hyperkube[1445]: \tmain.go:42", want: true},
+		{name: "journal message", line: "Aug 26 17:00:14 ip-10-57-11-201 hyperkube[1445]: restarting", want: false},
+		{name: "new entry", line: "2024-05-01T10:00:03.000000000Z restarting", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isContinuationLine(tt.line))
+		})
+	}
+}
+
+func Test_FilterLogWithContextFromScanner(t *testing.T) {
+	tests := []struct {
+		name         string
+		messages     []string
+		linesContext LogLinesContext
+		want         []int
+	}{
+		{name: "no context", messages: []string{"panic:", "ERROR"}, want: []int{2, 8}},
+		{
+			name:         "continuation lines",
+			messages:     []string{"panic:", "ERROR"},
+			linesContext: LogLinesContext{Continuation: true},
+			want:         []int{2, 3, 4, 5, 6, 8, 9, 10, 11},
+		},
+		{
+			name:         "lines before and after",
+			messages:     []string{"panic:"},
+			linesContext: LogLinesContext{Before: 1, After: 1},
+			want:         []int{1, 2, 3},
+		},
+		{
+			name:         "lines after the continuation lines",
+			messages:     []string{"panic:"},
+			linesContext: LogLinesContext{After: 1, Continuation: true},
+			want:         []int{2, 3, 4, 5, 6, 7},
+		},
+		{
+			name:         "overlapping context is kept once",
+			messages:     []string{" starting", " loading"},
+			linesContext: LogLinesContext{Before: 2, After: 1},
+			want:         []int{0, 1, 2},
+		},
+	}
+	logLines := strings.Split(testMultiLineLog, "\n")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := bufio.NewScanner(strings.NewReader(testMultiLineLog))
+			result, err := FilterLogWithContextFromScanner(scanner, tt.messages, true, tt.linesContext, nil)
+			assert.NoError(t, err)
+
+			var want []string
+			for _, i := range tt.want {
+				want = append(want, logLines[i])
+			}
+			assert.Equal(t, strings.Join(want, "\n"), result)
+		})
+	}
+}
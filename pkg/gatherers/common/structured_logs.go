@@ -0,0 +1,227 @@
+package common
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogFieldFilter is a predicate on a field of the structured (JSON or logfmt) log line
+type LogFieldFilter struct {
+	// Field is the name of the field, the fields nested in the JSON objects can be separated by dots (e.g. "error.kind")
+	Field string `json:"field"`
+	// Values are the accepted values of the field (case-insensitive). Any value is accepted when it is empty,
+	// so the filter only checks the presence of the field.
+	Values []string `json:"values,omitempty"`
+}
+
+// StructuredLogEntry is the log line matching the field filters stored in the archive as the JSON record
+type StructuredLogEntry struct {
+	// Timestamp is the timestamp added to the log line by the kubelet, if any
+	Timestamp string                 `json:"timestamp,omitempty"`
+	Fields    map[string]interface{} `json:"fields"`
+}
+
+// FilterStructuredLogFromScanner parses the JSON or logfmt log lines and keeps only those matching
+// all the field filters and the messages to search (the same way as FilterLogFromScanner does).
+// The lines which are not structured are skipped. The result contains one StructuredLogEntry JSON record per line.
+func FilterStructuredLogFromScanner(
+	scanner *bufio.Scanner, messagesToSearch []string, regexSearch bool, fieldFilters []LogFieldFilter,
+) (string, error) {
+	var messagesRegexp *regexp.Regexp
+	if regexSearch && len(messagesToSearch) > 0 {
+		var err error
+		messagesRegexp, err = regexp.Compile(strings.Join(messagesToSearch, "|"))
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var result []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !matchesMessages(line, messagesToSearch, messagesRegexp) {
+			continue
+		}
+
+		data, ok, err := FilterStructuredLogLine(line, fieldFilters)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			result = append(result, string(data))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return strings.Join(result, "\n"), nil
+}
+
+// FilterStructuredLogLine parses the JSON or logfmt log line and returns it as the StructuredLogEntry JSON record
+// when it matches all the field filters. The lines which are not structured don't match.
+func FilterStructuredLogLine(line string, fieldFilters []LogFieldFilter) ([]byte, bool, error) {
+	entry, ok := parseStructuredLogLine(line)
+	if !ok || !matchesFieldFilters(entry.Fields, fieldFilters) {
+		return nil, false, nil
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// matchesMessages checks whether the line matches the regular expression, or when it is nil,
+// whether it contains any of the messages (case-insensitive). Every line matches when there are no messages.
+func matchesMessages(line string, messagesToSearch []string, messagesRegexp *regexp.Regexp) bool {
+	if len(messagesToSearch) == 0 {
+		return true
+	}
+	if messagesRegexp != nil {
+		return messagesRegexp.MatchString(line)
+	}
+	lowerLine := strings.ToLower(line)
+	for _, messageToSearch := range messagesToSearch {
+		if strings.Contains(lowerLine, strings.ToLower(messageToSearch)) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseStructuredLogLine parses the JSON or logfmt log line. The line can be prefixed
+// with the RFC3339 timestamp added by the kubelet when the logs are requested with the timestamps.
+func parseStructuredLogLine(line string) (*StructuredLogEntry, bool) {
+	entry := &StructuredLogEntry{}
+	entry.Timestamp, line = splitLogTimestamp(line)
+
+	if strings.HasPrefix(line, "{") {
+		if err := json.Unmarshal([]byte(line), &entry.Fields); err != nil {
+			return nil, false
+		}
+		return entry, true
+	}
+
+	fields, err := parseLogfmt(line)
+	if err != nil {
+		return nil, false
+	}
+	entry.Fields = fields
+	return entry, true
+}
+
+// splitLogTimestamp splits the RFC3339 timestamp added by the kubelet from the log line.
+// The timestamp is empty when the line doesn't start with it.
+func splitLogTimestamp(line string) (timestamp, message string) {
+	line = strings.TrimSpace(line)
+	if prefix, rest, found := strings.Cut(line, " "); found {
+		if _, err := time.Parse(time.RFC3339Nano, prefix); err == nil {
+			return prefix, strings.TrimSpace(rest)
+		}
+	}
+	return "", line
+}
+
+// parseLogfmt parses the logfmt line consisting of the space separated key=value pairs.
+// The values can be quoted. Every token must be a key=value pair, so that the plain text lines are not
+// mistaken for the logfmt lines.
+func parseLogfmt(line string) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	for line != "" {
+		key, rest, found := strings.Cut(line, "=")
+		if !found || key == "" || strings.ContainsAny(key, " \t\"") {
+			return nil, fmt.Errorf("not a key=value pair: %q", line)
+		}
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := closingQuoteIndex(rest)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quoted value of the %s key", key)
+			}
+			unquoted, err := strconv.Unquote(rest[:end+1])
+			if err != nil {
+				return nil, err
+			}
+			value, rest = unquoted, rest[end+1:]
+			if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+				return nil, fmt.Errorf("missing separator after the value of the %s key", key)
+			}
+		} else {
+			value, rest, _ = strings.Cut(rest, " ")
+		}
+
+		fields[key] = value
+		line = strings.TrimLeft(rest, " \t")
+	}
+
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no key=value pair found")
+	}
+	return fields, nil
+}
+
+// closingQuoteIndex returns the index of the unescaped double quote closing the string starting with the double quote
+func closingQuoteIndex(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// matchesFieldFilters checks whether the fields match all the filters
+func matchesFieldFilters(fields map[string]interface{}, fieldFilters []LogFieldFilter) bool {
+	for _, filter := range fieldFilters {
+		value, ok := lookupField(fields, filter.Field)
+		if !ok {
+			return false
+		}
+		if len(filter.Values) == 0 {
+			continue
+		}
+
+		stringValue := fmt.Sprint(value)
+		matched := false
+		for _, accepted := range filter.Values {
+			if strings.EqualFold(stringValue, accepted) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// lookupField returns the value of the field. The name can be a top level key containing dots
+// or a dot separated path to the field nested in the JSON objects.
+func lookupField(fields map[string]interface{}, name string) (interface{}, bool) {
+	if value, ok := fields[name]; ok {
+		return value, true
+	}
+
+	key, rest, found := strings.Cut(name, ".")
+	if !found {
+		return nil, false
+	}
+	nested, ok := fields[key].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	return lookupField(nested, rest)
+}
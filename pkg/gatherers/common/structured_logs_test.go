@@ -0,0 +1,126 @@
+package common
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testStructuredLog = `2024-05-01T10:00:00.000000000Z {"level":"info","msg":"started","component":"controller"}
+2024-05-01T10:00:01.000000000Z {"level":"error","msg":"sync failed","component":"controller","error":{"kind":"timeout"}}
+2024-05-01T10:00:02.000000000Z level=error msg="failed to reconcile" component=webhook code=500
+2024-05-01T10:00:03.000000000Z E0501 10:00:03.000000 1 controller.go:42] plain text error
+{"level":"ERROR","msg":"no timestamp","component":"controller"}
+level=warning msg=slow component=webhook`
+
+func Test_parseLogfmt(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name: "simple pairs",
+			line: "level=error component=webhook",
+			want: map[string]interface{}{"level": "error", "component": "webhook"},
+		},
+		{
+			name: "quoted values",
+			line: `msg="failed to \"reconcile\"" empty="" level=info`,
+			want: map[string]interface{}{"msg": `failed to "reconcile"`, "empty": "", "level": "info"},
+		},
+		{name: "plain text", line: "E0501 10:00:03.000000 1 controller.go:42] plain text", wantErr: true},
+		{name: "bare key", line: "level=error failed", wantErr: true},
+		{name: "unterminated quote", line: `msg="failed`, wantErr: true},
+		{name: "empty line", line: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseLogfmt(tt.line)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_matchesFieldFilters(t *testing.T) {
+	fields := map[string]interface{}{
+		"level":     "error",
+		"code":      float64(500),
+		"error":     map[string]interface{}{"kind": "timeout"},
+		"log.level": "error",
+	}
+	tests := []struct {
+		name    string
+		filters []LogFieldFilter
+		want    bool
+	}{
+		{name: "no filters", want: true},
+		{name: "case-insensitive value", filters: []LogFieldFilter{{Field: "level", Values: []string{"ERROR"}}}, want: true},
+		{name: "one of the values", filters: []LogFieldFilter{{Field: "level", Values: []string{"warning", "error"}}}, want: true},
+		{name: "number value", filters: []LogFieldFilter{{Field: "code", Values: []string{"500"}}}, want: true},
+		{name: "nested field", filters: []LogFieldFilter{{Field: "error.kind", Values: []string{"timeout"}}}, want: true},
+		{name: "dotted top level field", filters: []LogFieldFilter{{Field: "log.level", Values: []string{"error"}}}, want: true},
+		{name: "field presence", filters: []LogFieldFilter{{Field: "error"}}, want: true},
+		{name: "missing field", filters: []LogFieldFilter{{Field: "component"}}, want: false},
+		{
+			name: "all the filters must match",
+			filters: []LogFieldFilter{
+				{Field: "level", Values: []string{"error"}},
+				{Field: "code", Values: []string{"404"}},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchesFieldFilters(fields, tt.filters))
+		})
+	}
+}
+
+func Test_FilterStructuredLogFromScanner(t *testing.T) {
+	tests := []struct {
+		name             string
+		messagesToSearch []string
+		isRegexSearch    bool
+		fieldFilters     []LogFieldFilter
+		expectedOutput   string
+	}{
+		{
+			name:         "JSON and logfmt lines with the level",
+			fieldFilters: []LogFieldFilter{{Field: "level", Values: []string{"error"}}},
+			// nolint: lll
+			expectedOutput: `{"timestamp":"2024-05-01T10:00:01.000000000Z","fields":{"component":"controller","error":{"kind":"timeout"},"level":"error","msg":"sync failed"}}
+{"timestamp":"2024-05-01T10:00:02.000000000Z","fields":{"code":"500","component":"webhook","level":"error","msg":"failed to reconcile"}}
+{"fields":{"component":"controller","level":"ERROR","msg":"no timestamp"}}`,
+		},
+		{
+			name:             "fields and messages",
+			fieldFilters:     []LogFieldFilter{{Field: "component", Values: []string{"webhook"}}},
+			isRegexSearch:    true,
+			messagesToSearch: []string{"slow"},
+			expectedOutput:   `{"fields":{"component":"webhook","level":"warning","msg":"slow"}}`,
+		},
+		{
+			name:           "no matching line",
+			fieldFilters:   []LogFieldFilter{{Field: "component", Values: []string{"scheduler"}}},
+			expectedOutput: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := bufio.NewScanner(strings.NewReader(testStructuredLog))
+			result, err := FilterStructuredLogFromScanner(scanner, tt.messagesToSearch, tt.isRegexSearch, tt.fieldFilters)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedOutput, result)
+		})
+	}
+}
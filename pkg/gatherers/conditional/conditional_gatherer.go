@@ -17,12 +17,17 @@ import (
 	"net/http"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	configv1 "github.com/openshift/api/config/v1"
 	configv1client "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 
@@ -37,6 +42,15 @@ import (
 //go:embed default_remote_configuration.json
 var defaultRemoteConfiguration string
 
+const (
+	// maxMetricQueries is the maximum number of the metric_matches queries evaluated in one gathering
+	maxMetricQueries = 32
+	// metricQueryTimeout is the evaluation timeout of a single metric_matches query
+	metricQueryTimeout = "10s"
+	// maxResourceQueries is the maximum number of the resource_exists resources checked in one gathering
+	maxResourceQueries = 32
+)
+
 // Gatherer implements the conditional gatherer
 type Gatherer struct {
 	gatherProtoKubeConfig   *rest.Config
@@ -44,11 +58,21 @@ type Gatherer struct {
 	imageKubeConfig         *rest.Config
 	gatherKubeConfig        *rest.Config
 	// there can be multiple instances of the same alert
-	firingAlerts       map[string][]AlertLabels
-	clusterVersion     string
+	firingAlerts   map[string][]AlertLabels
+	clusterVersion string
+	// values of the metric_matches queries
+	metricValues        map[string][]float64
+	platform            string
+	enabledFeatureGates map[string]struct{}
+	degradedOperators   map[string]struct{}
+	// existence of the resource_exists resources by their keys
+	existingResources  map[string]bool
+	clusterID          string
 	configurator       configobserver.Interface
 	insightsCli        InsightsGetClient
 	remoteConfigStatus gatherers.RemoteConfigStatus
+	// log byte budget shared by all the log gathering functions of the current gathering
+	logBudget *common.SharedLogBudget
 }
 
 type InsightsGetClient interface {
@@ -103,11 +127,10 @@ func (g *Gatherer) GetName() string {
 func (g *Gatherer) GetGatheringFunctions(ctx context.Context) (map[string]gatherers.GatheringClosure, error) {
 	remoteConfigData, err := g.getRemoteConfiguration(ctx)
 	if err != nil {
-		// failed to get the remote configuration -> use default
+		// failed to get the remote configuration -> use the fallback
 		klog.Info(err.Error())
 		g.remoteConfigStatus.ConfigAvailable = false
 		g.remoteConfigStatus.Err = err
-		g.remoteConfigStatus.ConfigData = []byte(defaultRemoteConfiguration)
 		g.remoteConfigStatus.ValidReason = "NoValidation"
 
 		var httpErr insightsclient.HttpError
@@ -117,37 +140,58 @@ func (g *Gatherer) GetGatheringFunctions(ctx context.Context) (map[string]gather
 			g.remoteConfigStatus.AvailableReason = NotAvailableReason
 		}
 
-		return g.useBuiltInRemoteConfiguration(ctx)
+		return g.useFallbackRemoteConfiguration(ctx, true)
 	}
 	g.remoteConfigStatus.ConfigAvailable = true
 	g.remoteConfigStatus.AvailableReason = SucceededReason
 
-	remoteConfig, err := parseRemoteConfiguration(remoteConfigData)
+	remoteConfig, reason, err := g.validateRemoteConfiguration(remoteConfigData)
 	if err != nil {
-		// failed to parse the remote configuration -> use default
-		klog.Infof("Failed to parse the remote configuration data: %v. Using the default built-in configuration", err)
+		// the remote configuration is invalid -> use the fallback
+		klog.Infof("The remote configuration is not valid: %v", err)
 		g.remoteConfigStatus.ConfigValid = false
 		g.remoteConfigStatus.Err = err
-		g.remoteConfigStatus.ValidReason = InvalidReason
-		g.remoteConfigStatus.ConfigData = []byte(defaultRemoteConfiguration)
+		g.remoteConfigStatus.ValidReason = reason
 
-		return g.useBuiltInRemoteConfiguration(ctx)
+		return g.useFallbackRemoteConfiguration(ctx, true)
 	}
 
-	errs := validateRemoteConfig(remoteConfig)
-	if len(errs) > 0 {
-		validationErr := utils.UniqueErrors(errs)
-		klog.Infof("Failed to validate the remote configuration data: %v. Using the default built-in configuration", validationErr)
-		g.remoteConfigStatus.ConfigData = []byte(defaultRemoteConfiguration)
+	if err := g.checkRemoteConfigVersion(remoteConfig.Version); err != nil {
+		// the remote configuration version is not allowed in this cluster -> use the fallback
+		klog.Infof("The remote configuration cannot be used: %v", err)
 		g.remoteConfigStatus.ConfigValid = false
-		g.remoteConfigStatus.Err = validationErr
-		g.remoteConfigStatus.ValidReason = InvalidReason
-		return g.useBuiltInRemoteConfiguration(ctx)
+		g.remoteConfigStatus.Err = err
+		g.remoteConfigStatus.ValidReason = VersionNotAllowedReason
+
+		return g.useFallbackRemoteConfiguration(ctx, true)
 	}
 
 	g.remoteConfigStatus.ConfigValid = true
-	g.remoteConfigStatus.ValidReason = SucceededReason
+	g.remoteConfigStatus.ValidReason = reason
+	g.remoteConfigStatus.Err = nil
+	if reason == NoPublicKeyReason {
+		// the remote configuration is used, but the condition reports that its signature was not verified
+		klog.Warningf("The remote configuration is used without the signature verification: %v", errNoPublicKey)
+		g.remoteConfigStatus.Err = errNoPublicKey
+	}
+
+	inRollout, err := g.isInRollout(ctx, &remoteConfig)
+	if err != nil {
+		klog.Errorf("Unable to check the rollout of the remote configuration version %s: %v", remoteConfig.Version, err)
+	}
+	if !inRollout {
+		// the remote configuration version is not rolled out to this cluster yet -> keep using the fallback,
+		// the last known good configuration doesn't expire when the rollout is the only reason not to use the new version
+		klog.Infof("The cluster is not in the %d%% rollout of the remote configuration version %s",
+			*remoteConfig.RolloutPercentage, remoteConfig.Version)
+		return g.useFallbackRemoteConfiguration(ctx, err != nil)
+	}
+
 	g.remoteConfigStatus.ConfigData = remoteConfigData
+	g.remoteConfigStatus.Source = gatherers.RemoteConfigSourceRemote
+	if err := g.storeLastKnownGoodConfig(remoteConfig.Version, remoteConfigData); err != nil {
+		klog.Errorf("Failed to store the last known good remote configuration: %v", err)
+	}
 	return g.createAllGatheringFunctions(ctx, remoteConfig)
 }
 
@@ -155,10 +199,61 @@ func (g *Gatherer) RemoteConfigStatus() gatherers.RemoteConfigStatus {
 	return g.remoteConfigStatus
 }
 
+// validateRemoteConfiguration verifies the signature of the remote configuration data, parses and validates them.
+// In case of an error, it also returns the reason of the failure. The valid remote configuration is returned
+// with the NoPublicKeyReason when its signature could not be verified because no public key is available.
+func (g *Gatherer) validateRemoteConfiguration(data []byte) (RemoteConfiguration, string, error) {
+	payload, signature, err := signedRemoteConfigPayload(data)
+	if err != nil {
+		return RemoteConfiguration{}, InvalidReason, err
+	}
+
+	reason := SucceededReason
+	err = g.verifyRemoteConfigSignature(payload, signature)
+	if errors.Is(err, errNoPublicKey) {
+		reason = NoPublicKeyReason
+	} else if err != nil {
+		return RemoteConfiguration{}, InvalidSignatureReason, err
+	}
+
+	remoteConfig, err := parseRemoteConfiguration(data)
+	if err != nil {
+		return RemoteConfiguration{}, InvalidReason, err
+	}
+
+	errs := validateRemoteConfig(remoteConfig)
+	if len(errs) > 0 {
+		return RemoteConfiguration{}, InvalidReason, utils.UniqueErrors(errs)
+	}
+
+	return remoteConfig, reason, nil
+}
+
+// useFallbackRemoteConfiguration uses the last known good remote configuration when it is available
+// and not too old, otherwise the default/built-in remote configuration is used. The age is not checked
+// (checkMaxAge is false) when the remote configuration is available and valid, but the cluster is not
+// in its rollout yet, so that the clusters outside of the rollout keep their previous version.
+func (g *Gatherer) useFallbackRemoteConfiguration(
+	ctx context.Context, checkMaxAge bool,
+) (map[string]gatherers.GatheringClosure, error) {
+	lastKnownGood, remoteConfig, err := g.loadLastKnownGoodConfig(checkMaxAge)
+	if err != nil {
+		klog.Infof("The last known good remote configuration cannot be used: %v. Using the default built-in configuration", err)
+		return g.useBuiltInRemoteConfiguration(ctx)
+	}
+
+	klog.Infof("Using the last known good remote configuration version %s fetched at %s",
+		lastKnownGood.Version, lastKnownGood.FetchedAt.Format(time.RFC3339))
+	g.remoteConfigStatus.ConfigData = []byte(lastKnownGood.Data)
+	g.remoteConfigStatus.Source = gatherers.RemoteConfigSourceLastKnownGood
+	return g.createAllGatheringFunctions(ctx, remoteConfig)
+}
+
 // useBuiltInRemoteConfiguration is a helper function parsing the default/built-in remote configuration and
 // using it for gathering functions creation
 func (g *Gatherer) useBuiltInRemoteConfiguration(ctx context.Context) (map[string]gatherers.GatheringClosure, error) {
-	// failed to parse the remote configuration -> use default
+	g.remoteConfigStatus.ConfigData = []byte(defaultRemoteConfiguration)
+	g.remoteConfigStatus.Source = gatherers.RemoteConfigSourceBuiltIn
 	remoteConfig, err := parseRemoteConfiguration([]byte(defaultRemoteConfiguration))
 	if err != nil {
 		return nil, err
@@ -167,10 +262,12 @@ func (g *Gatherer) useBuiltInRemoteConfiguration(ctx context.Context) (map[strin
 }
 
 // createAllGatheringFunctions is a wrapper function to create all gathering functions - the original
-// conditional gathering functions and the new ("rapid") container logs function
+// conditional gathering functions and the new ("rapid") container logs function. The log gathering
+// functions share a new log budget.
 func (g *Gatherer) createAllGatheringFunctions(ctx context.Context,
 	remoteConfiguration RemoteConfiguration,
 ) (map[string]gatherers.GatheringClosure, error) {
+	g.logBudget = common.NewSharedLogBudget(g.logsMaxBytes())
 	gatheringClosures := g.createConditionalGatheringFunctions(ctx, remoteConfiguration)
 	rapidContainerLogsClosure, err := g.GatherContainersLogs(remoteConfiguration.ContainerLogRequests)
 	if err != nil {
@@ -185,7 +282,7 @@ func (g *Gatherer) createAllGatheringFunctions(ctx context.Context,
 func (g *Gatherer) createConditionalGatheringFunctions(ctx context.Context,
 	remoteConfiguration RemoteConfiguration,
 ) map[string]gatherers.GatheringClosure {
-	g.updateCache(ctx)
+	g.updateCache(ctx, remoteConfiguration.ConditionalGatheringRules)
 
 	gatheringFunctions := make(map[string]gatherers.GatheringClosure)
 
@@ -200,30 +297,9 @@ func (g *Gatherer) createConditionalGatheringFunctions(ctx context.Context,
 	}
 
 	for _, conditionalGathering := range remoteConfiguration.ConditionalGatheringRules {
-		ruleMetadata := GatheringRuleMetadata{
-			Rule: conditionalGathering,
-		}
-
-		allConditionsAreSatisfied, err := g.areAllConditionsSatisfied(conditionalGathering.Conditions)
-		if err != nil {
-			klog.Errorf("error checking conditions for a gathering rule: %v", err)
-			ruleMetadata.Errors = append(ruleMetadata.Errors, err.Error())
-		}
-
-		ruleMetadata.WasTriggered = allConditionsAreSatisfied
-
-		if allConditionsAreSatisfied {
-			functions, errs := g.createGatheringClosures(conditionalGathering.GatheringFunctions)
-			if len(errs) > 0 {
-				klog.Errorf("error(s) creating a closure for a gathering rule: %v", errs)
-				for _, err := range errs {
-					ruleMetadata.Errors = append(ruleMetadata.Errors, err.Error())
-				}
-			}
-
-			for funcName, function := range functions {
-				gatheringFunctions[funcName] = function
-			}
+		ruleMetadata, functions := g.evaluateGatheringRule(conditionalGathering)
+		for funcName, function := range functions {
+			gatheringFunctions[funcName] = function
 		}
 
 		metadata.Rules = append(metadata.Rules, ruleMetadata)
@@ -243,8 +319,39 @@ func (g *Gatherer) createConditionalGatheringFunctions(ctx context.Context,
 	return gatheringFunctions
 }
 
+// evaluateGatheringRule checks the conditions of the gathering rule and creates its gathering functions
+// when all the conditions are satisfied
+func (g *Gatherer) evaluateGatheringRule(
+	conditionalGathering GatheringRule,
+) (GatheringRuleMetadata, map[string]gatherers.GatheringClosure) {
+	ruleMetadata := GatheringRuleMetadata{
+		Rule: conditionalGathering,
+	}
+
+	allConditionsAreSatisfied, err := g.areAllConditionsSatisfied(conditionalGathering.Conditions)
+	if err != nil {
+		klog.Errorf("error checking conditions for a gathering rule: %v", err)
+		ruleMetadata.Errors = append(ruleMetadata.Errors, err.Error())
+	}
+
+	ruleMetadata.WasTriggered = allConditionsAreSatisfied
+	if !allConditionsAreSatisfied {
+		return ruleMetadata, nil
+	}
+
+	functions, errs := g.createGatheringClosures(conditionalGathering.GatheringFunctions)
+	if len(errs) > 0 {
+		klog.Errorf("error(s) creating a closure for a gathering rule: %v", errs)
+		for _, err := range errs {
+			ruleMetadata.Errors = append(ruleMetadata.Errors, err.Error())
+		}
+	}
+
+	return ruleMetadata, functions
+}
+
 // getRemoteConfiguration returns json version of the rules from the server
-func (g *Gatherer) getRemoteConfiguration(ctx context.Context) ([]byte, error) {
+func (g *Gatherer) getRemoteConfiguration(ctx context.Context) (data []byte, err error) {
 	if g.configurator == nil {
 		return nil, fmt.Errorf("no configurator was provided")
 	}
@@ -271,7 +378,6 @@ func (g *Gatherer) getRemoteConfiguration(ctx context.Context) ([]byte, error) {
 		Cap:      3 * time.Minute,
 	}
 	endpointWithVersion := fmt.Sprintf(endpoint, ocpVersion)
-	var remoteConfigData []byte
 	err = wait.ExponentialBackoffWithContext(ctx, backOff, func(ctx context.Context) (done bool, err error) {
 		resp, err := g.insightsCli.GetWithPathParam(ctx, endpoint, ocpVersion, false)
 		if err != nil {
@@ -284,11 +390,11 @@ func (g *Gatherer) getRemoteConfiguration(ctx context.Context) ([]byte, error) {
 				return false, nil
 			}
 			return true, insightsclient.HttpError{
-				Err:        fmt.Errorf("received HTTP %s from %s. Using the default built-in configuration", resp.Status, endpointWithVersion),
+				Err:        fmt.Errorf("received HTTP %s from %s", resp.Status, endpointWithVersion),
 				StatusCode: resp.StatusCode,
 			}
 		}
-		remoteConfigData, err = io.ReadAll(resp.Body)
+		data, err = io.ReadAll(resp.Body)
 		defer resp.Body.Close()
 		if err != nil {
 			return false, nil
@@ -299,7 +405,42 @@ func (g *Gatherer) getRemoteConfiguration(ctx context.Context) ([]byte, error) {
 		return nil, err
 	}
 
-	return remoteConfigData, nil
+	return data, nil
+}
+
+// verifyRemoteConfigSignature verifies the signature of the signed payload of the remote configuration
+// with the built-in public keys and the public keys from the configuration. The errNoPublicKey is returned
+// when there is no public key available, the signature can't be verified then.
+func (g *Gatherer) verifyRemoteConfigSignature(payload []byte, signature string) error {
+	keys, err := parsePublicKeys(builtInPublicKeys)
+	if err != nil {
+		return fmt.Errorf("unable to parse the built-in public keys: %v", err)
+	}
+
+	if config := g.configurator.Config(); config != nil {
+		configuredKeys, err := parsePublicKeys(config.DataReporting.RemoteConfigPublicKeys)
+		if err != nil {
+			return fmt.Errorf("unable to parse the configured public keys: %v", err)
+		}
+		keys = append(keys, configuredKeys...)
+	}
+
+	if len(keys) == 0 {
+		return errNoPublicKey
+	}
+
+	return verifySignature(payload, signature, keys)
+}
+
+// logsMaxBytes returns the configured log byte budget of the log gathering functions or the default one
+func (g *Gatherer) logsMaxBytes() int64 {
+	if g.configurator == nil {
+		return containerLogsMaxBytes
+	}
+	if config := g.configurator.Config(); config != nil && config.DataReporting.ConditionalLogsMaxBytes > 0 {
+		return config.DataReporting.ConditionalLogsMaxBytes
+	}
+	return containerLogsMaxBytes
 }
 
 func (g *Gatherer) getRemoteConfigEndpoint() (string, error) {
@@ -311,8 +452,8 @@ func (g *Gatherer) getRemoteConfigEndpoint() (string, error) {
 	return config.DataReporting.ConditionalGathererEndpoint, nil
 }
 
-// updateCache updates alerts and version caches
-func (g *Gatherer) updateCache(ctx context.Context) {
+// updateCache updates alerts, metrics, version, platform, feature gates, cluster operators and resources caches
+func (g *Gatherer) updateCache(ctx context.Context, rules []GatheringRule) {
 	if g.metricsGatherKubeConfig == nil {
 		return
 	}
@@ -320,18 +461,47 @@ func (g *Gatherer) updateCache(ctx context.Context) {
 	metricsClient, err := rest.RESTClientFor(g.metricsGatherKubeConfig)
 	if err != nil {
 		klog.Errorf("unable to update alerts cache: %v", err)
-	} else if err := g.updateAlertsCache(ctx, metricsClient); err != nil { //nolint:govet
-		klog.Errorf("unable to update alerts cache: %v", err)
-		g.firingAlerts = nil
+	} else {
+		if err := g.updateAlertsCache(ctx, metricsClient); err != nil { //nolint:govet
+			klog.Errorf("unable to update alerts cache: %v", err)
+			g.firingAlerts = nil
+		}
+		g.updateMetricsCache(ctx, metricsClient, metricQueries(rules))
 	}
 
 	configClient, err := configv1client.NewForConfig(g.gatherKubeConfig)
 	if err != nil {
-		klog.Errorf("unable to update version cache: %v", err)
-	} else if err := g.updateVersionCache(ctx, configClient); err != nil {
+		klog.Errorf("unable to update version, platform, feature gates and cluster operators caches: %v", err)
+	} else {
+		g.updateConfigCaches(ctx, configClient)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(g.gatherKubeConfig)
+	if err != nil {
+		klog.Errorf("unable to update resources cache: %v", err)
+	} else {
+		g.updateResourcesCache(ctx, dynamicClient, resourceQueries(rules))
+	}
+}
+
+// updateConfigCaches updates the caches populated from the config.openshift.io resources
+func (g *Gatherer) updateConfigCaches(ctx context.Context, configClient configv1client.ConfigV1Interface) {
+	if err := g.updateVersionCache(ctx, configClient); err != nil {
 		klog.Errorf("unable to update version cache: %v", err)
 		g.clusterVersion = ""
 	}
+	if err := g.updatePlatformCache(ctx, configClient); err != nil {
+		klog.Errorf("unable to update platform cache: %v", err)
+		g.platform = ""
+	}
+	if err := g.updateFeatureGatesCache(ctx, configClient); err != nil {
+		klog.Errorf("unable to update feature gates cache: %v", err)
+		g.enabledFeatureGates = nil
+	}
+	if err := g.updateClusterOperatorsCache(ctx, configClient); err != nil {
+		klog.Errorf("unable to update cluster operators cache: %v", err)
+		g.degradedOperators = nil
+	}
 }
 
 func (g *Gatherer) updateAlertsCache(ctx context.Context, metricsClient rest.Interface) error {
@@ -380,6 +550,93 @@ func (g *Gatherer) updateAlertsCache(ctx context.Context, metricsClient rest.Int
 	return nil
 }
 
+// updateMetricsCache runs the instant queries of the metric_matches conditions and caches their values.
+// The failed queries are not cached, so the conditions using them end with an error.
+func (g *Gatherer) updateMetricsCache(ctx context.Context, metricsClient rest.Interface, queries []string) {
+	g.metricValues = make(map[string][]float64)
+	if len(queries) == 0 {
+		return
+	}
+	klog.Info("updating metrics cache for conditional gatherer")
+
+	if len(queries) > maxMetricQueries {
+		klog.Errorf("only the first %d of %d metric queries are evaluated", maxMetricQueries, len(queries))
+		queries = queries[:maxMetricQueries]
+	}
+
+	for _, query := range queries {
+		values, err := queryMetricValues(ctx, metricsClient, query)
+		if err != nil {
+			klog.Errorf("unable to update metrics cache for the query %q: %v", query, err)
+			continue
+		}
+		klog.Infof("query %q has values %v", query, values)
+		g.metricValues[query] = values
+	}
+}
+
+// queryMetricValues runs the instant query and returns the values of the vector or the scalar result
+func queryMetricValues(ctx context.Context, metricsClient rest.Interface, query string) ([]float64, error) {
+	data, err := metricsClient.Get().
+		AbsPath("api/v1/query").
+		Param("query", query).
+		Param("timeout", metricQueryTimeout).
+		DoRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Data struct {
+			ResultType string          `json:"resultType"`
+			Result     json.RawMessage `json:"result"`
+		} `json:"data"`
+	}
+	err = json.Unmarshal(data, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	var samples [][]interface{}
+	switch response.Data.ResultType {
+	case "vector":
+		var vector []struct {
+			Value []interface{} `json:"value"`
+		}
+		if err := json.Unmarshal(response.Data.Result, &vector); err != nil {
+			return nil, err
+		}
+		for _, sample := range vector {
+			samples = append(samples, sample.Value)
+		}
+	case "scalar":
+		var scalar []interface{}
+		if err := json.Unmarshal(response.Data.Result, &scalar); err != nil {
+			return nil, err
+		}
+		samples = append(samples, scalar)
+	default:
+		return nil, fmt.Errorf("unsupported result type %q, only vector and scalar are supported", response.Data.ResultType)
+	}
+
+	values := make([]float64, 0, len(samples))
+	for _, sample := range samples {
+		if len(sample) != 2 {
+			return nil, fmt.Errorf("unexpected sample format: %v", sample)
+		}
+		valueStr, ok := sample[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected sample value: %v", sample[1])
+		}
+		value, err := strconv.ParseFloat(valueStr, 64)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
 func (g *Gatherer) updateVersionCache(ctx context.Context, configClient configv1client.ConfigV1Interface) error {
 	klog.Info("updating version cache for conditional gatherer")
 
@@ -393,6 +650,115 @@ func (g *Gatherer) updateVersionCache(ctx context.Context, configClient configv1
 	return nil
 }
 
+func (g *Gatherer) updatePlatformCache(ctx context.Context, configClient configv1client.ConfigV1Interface) error {
+	klog.Info("updating platform cache for conditional gatherer")
+
+	infrastructure, err := configClient.Infrastructures().Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	g.platform = string(infrastructure.Status.Platform) //nolint:staticcheck
+	if infrastructure.Status.PlatformStatus != nil {
+		g.platform = string(infrastructure.Status.PlatformStatus.Type)
+	}
+	klog.Infof("platform is '%v'", g.platform)
+	return nil
+}
+
+// updateFeatureGatesCache caches the feature gates enabled for the current cluster version
+func (g *Gatherer) updateFeatureGatesCache(ctx context.Context, configClient configv1client.ConfigV1Interface) error {
+	klog.Info("updating feature gates cache for conditional gatherer")
+
+	featureGate, err := configClient.FeatureGates().Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	g.enabledFeatureGates = make(map[string]struct{})
+	for i := range featureGate.Status.FeatureGates {
+		details := &featureGate.Status.FeatureGates[i]
+		if details.Version != g.clusterVersion {
+			continue
+		}
+		for _, enabled := range details.Enabled {
+			g.enabledFeatureGates[string(enabled.Name)] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// updateClusterOperatorsCache caches the names of the degraded cluster operators
+func (g *Gatherer) updateClusterOperatorsCache(ctx context.Context, configClient configv1client.ConfigV1Interface) error {
+	klog.Info("updating cluster operators cache for conditional gatherer")
+
+	clusterOperators, err := configClient.ClusterOperators().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	g.degradedOperators = make(map[string]struct{})
+	for i := range clusterOperators.Items {
+		clusterOperator := &clusterOperators.Items[i]
+		for _, condition := range clusterOperator.Status.Conditions {
+			if condition.Type == configv1.OperatorDegraded && condition.Status == configv1.ConditionTrue {
+				g.degradedOperators[clusterOperator.Name] = struct{}{}
+			}
+		}
+	}
+	klog.Infof("%d cluster operator(s) are degraded", len(g.degradedOperators))
+	return nil
+}
+
+// updateResourcesCache checks the existence of the resource_exists resources and caches it.
+// The resources which failed to be checked are not cached, so the conditions using them end with an error.
+func (g *Gatherer) updateResourcesCache(
+	ctx context.Context, dynamicClient dynamic.Interface, resources []ResourceExistsConditionParams,
+) {
+	g.existingResources = make(map[string]bool)
+	if len(resources) == 0 {
+		return
+	}
+	klog.Info("updating resources cache for conditional gatherer")
+
+	if len(resources) > maxResourceQueries {
+		klog.Errorf("only the first %d of %d resources are checked", maxResourceQueries, len(resources))
+		resources = resources[:maxResourceQueries]
+	}
+
+	for i := range resources {
+		resource := &resources[i]
+		exists, err := resourceExists(ctx, dynamicClient, resource)
+		if err != nil {
+			klog.Errorf("unable to update resources cache for the resource %q: %v", resource.key(), err)
+			continue
+		}
+		g.existingResources[resource.key()] = exists
+	}
+}
+
+func resourceExists(ctx context.Context, dynamicClient dynamic.Interface, resource *ResourceExistsConditionParams) (bool, error) {
+	gvr := schema.GroupVersionResource{Group: resource.Group, Version: resource.Version, Resource: resource.Resource}
+	client := dynamicClient.Resource(gvr).Namespace(resource.Namespace)
+
+	if resource.Name != "" {
+		_, err := client.Get(ctx, resource.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	list, err := client.List(ctx, metav1.ListOptions{Limit: 1})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return len(list.Items) > 0, nil
+}
+
 // createGatheringClosures produces gathering closures
 func (g *Gatherer) createGatheringClosures(
 	gatheringFunctions map[GatheringFunctionName]interface{},
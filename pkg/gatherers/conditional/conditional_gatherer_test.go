@@ -9,7 +9,14 @@ import (
 	"strings"
 	"testing"
 
+	configv1 "github.com/openshift/api/config/v1"
+	configfake "github.com/openshift/client-go/config/clientset/versioned/fake"
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest/fake"
 
@@ -17,7 +24,8 @@ import (
 	"github.com/openshift/insights-operator/pkg/gatherers"
 )
 
-var testRemoteConfig = `{
+// testRemoteConfigUnsigned is testRemoteConfig before it was signed
+var testRemoteConfigUnsigned = `{
 			"version": "1.0.0",
 			"conditional_gathering_rules": [{
 				"conditions": [
@@ -39,7 +47,9 @@ var testRemoteConfig = `{
 			"container_logs":[]
 		}`
 
-var testRemoteConfigInvalid = `{
+var testRemoteConfig = signTestRemoteConfig(testRemoteConfigUnsigned)
+
+var testRemoteConfigInvalid = signTestRemoteConfig(`{
 	"version": "1.0.0",
 	"conditional_gathering_rules": [{
 		"conditions": [
@@ -60,7 +70,7 @@ var testRemoteConfigInvalid = `{
 		"pod_name_regex": "container-log-test", 
 		"messages": [".*"]
 	}]
-}`
+}`)
 
 func Test_Gatherer_Basic(t *testing.T) {
 	t.Setenv("RELEASE_VERSION", "1.2.3")
@@ -122,6 +132,7 @@ func Test_Gatherer_GetGatheringFunctions_BuiltInConfigIsUsed(t *testing.T) {
 		ConfigData:      []byte(defaultRemoteConfiguration),
 		ConfigAvailable: false,
 		ConfigValid:     true,
+		Source:          gatherers.RemoteConfigSourceBuiltIn,
 	}, gatherer.remoteConfigStatus)
 	assert.Len(t, gatheringFunctions, 5)
 	_, found = gatheringFunctions["conditional_gatherer_rules"]
@@ -235,6 +246,135 @@ func newFakeClientWithAlerts(alerts ...string) *fake.RESTClient {
 	return fakeClient
 }
 
+func newFakeClientWithMetrics(responses map[string]string) *fake.RESTClient {
+	return &fake.RESTClient{
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			response, found := responses[req.URL.Query().Get("query")]
+			if !found {
+				return &http.Response{
+					StatusCode: http.StatusBadRequest,
+					Body:       io.NopCloser(strings.NewReader(`{"status": "error"}`)),
+				}, nil
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(response)),
+			}, nil
+		}),
+	}
+}
+
+func Test_Gatherer_updateMetricsCache(t *testing.T) {
+	gatherer := newEmptyGatherer(nil, "")
+	metricsClient := newFakeClientWithMetrics(map[string]string{
+		"vector_query": `{"status": "success", "data": {"resultType": "vector", "result": [
+			{"metric": {"namespace": "a"}, "value": [1.0, "3"]},
+			{"metric": {"namespace": "b"}, "value": [1.0, "12.5"]}
+		]}}`,
+		"scalar_query": `{"status": "success", "data": {"resultType": "scalar", "result": [1.0, "42"]}}`,
+		"empty_query":  `{"status": "success", "data": {"resultType": "vector", "result": []}}`,
+		"matrix_query": `{"status": "success", "data": {"resultType": "matrix", "result": []}}`,
+	})
+
+	gatherer.updateMetricsCache(context.Background(), metricsClient, []string{
+		"vector_query", "scalar_query", "empty_query", "matrix_query", "failing_query",
+	})
+	assert.Equal(t, map[string][]float64{
+		"vector_query": {3, 12.5},
+		"scalar_query": {42},
+		"empty_query":  {},
+	}, gatherer.metricValues)
+
+	match, err := gatherer.doesMetricMatch("vector_query", ">", 10)
+	assert.NoError(t, err)
+	assert.True(t, match)
+
+	_, err = gatherer.doesMetricMatch("failing_query", ">", 10)
+	assert.EqualError(t, err, `value of the query "failing_query" is missing`)
+}
+
+func Test_Gatherer_updateConfigCaches(t *testing.T) {
+	configClient := configfake.NewSimpleClientset(
+		&configv1.ClusterVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: "version"},
+			Status:     configv1.ClusterVersionStatus{Desired: configv1.Release{Version: "4.16.0"}},
+		},
+		&configv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Status: configv1.InfrastructureStatus{
+				PlatformStatus: &configv1.PlatformStatus{Type: configv1.GCPPlatformType},
+			},
+		},
+		&configv1.FeatureGate{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Status: configv1.FeatureGateStatus{FeatureGates: []configv1.FeatureGateDetails{
+				{Version: "4.15.3", Enabled: []configv1.FeatureGateAttributes{{Name: "OldGate"}}},
+				{Version: "4.16.0", Enabled: []configv1.FeatureGateAttributes{{Name: "GatewayAPI"}}},
+			}},
+		},
+		&configv1.ClusterOperator{
+			ObjectMeta: metav1.ObjectMeta{Name: "ingress"},
+			Status: configv1.ClusterOperatorStatus{Conditions: []configv1.ClusterOperatorStatusCondition{
+				{Type: configv1.OperatorDegraded, Status: configv1.ConditionTrue},
+			}},
+		},
+		&configv1.ClusterOperator{
+			ObjectMeta: metav1.ObjectMeta{Name: "dns"},
+			Status: configv1.ClusterOperatorStatus{Conditions: []configv1.ClusterOperatorStatusCondition{
+				{Type: configv1.OperatorDegraded, Status: configv1.ConditionFalse},
+			}},
+		},
+	)
+	gatherer := newEmptyGatherer(nil, "")
+
+	gatherer.updateConfigCaches(context.Background(), configClient.ConfigV1())
+	assert.Equal(t, "4.16.0", gatherer.clusterVersion)
+	assert.Equal(t, "GCP", gatherer.platform)
+	assert.Equal(t, map[string]struct{}{"GatewayAPI": {}}, gatherer.enabledFeatureGates)
+	assert.Equal(t, map[string]struct{}{"ingress": {}}, gatherer.degradedOperators)
+
+	// the caches are reset when the resources are not available
+	gatherer.updateConfigCaches(context.Background(), configfake.NewSimpleClientset().ConfigV1())
+	assert.Empty(t, gatherer.clusterVersion)
+	assert.Empty(t, gatherer.platform)
+	assert.Nil(t, gatherer.enabledFeatureGates)
+	assert.Equal(t, map[string]struct{}{}, gatherer.degradedOperators)
+}
+
+func Test_Gatherer_updateResourcesCache(t *testing.T) {
+	lokistacksGVR := schema.GroupVersionResource{Group: "loki.grafana.com", Version: "v1", Resource: "lokistacks"}
+	subscriptionsGVR := schema.GroupVersionResource{Group: "operators.coreos.com", Version: "v1alpha1", Resource: "subscriptions"}
+	subscription := &unstructured.Unstructured{}
+	subscription.SetAPIVersion("operators.coreos.com/v1alpha1")
+	subscription.SetKind("Subscription")
+	subscription.SetNamespace("openshift-logging")
+	subscription.SetName("loki")
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		lokistacksGVR:    "LokiStackList",
+		subscriptionsGVR: "SubscriptionList",
+	}, subscription)
+
+	anySubscription := ResourceExistsConditionParams{Group: "operators.coreos.com", Version: "v1alpha1", Resource: "subscriptions"}
+	lokiSubscription := anySubscription
+	lokiSubscription.Namespace = "openshift-logging"
+	lokiSubscription.Name = "loki"
+	otherSubscription := lokiSubscription
+	otherSubscription.Name = "other"
+	anyLokistack := ResourceExistsConditionParams{Group: "loki.grafana.com", Version: "v1", Resource: "lokistacks"}
+
+	gatherer := newEmptyGatherer(nil, "")
+	gatherer.updateResourcesCache(context.Background(), dynamicClient, []ResourceExistsConditionParams{
+		anySubscription, lokiSubscription, otherSubscription, anyLokistack,
+	})
+	assert.Equal(t, map[string]bool{
+		anySubscription.key():   true,
+		lokiSubscription.key():  true,
+		otherSubscription.key(): false,
+		anyLokistack.key():      false,
+	}, gatherer.existingResources)
+}
+
 func Test_Gatherer_doesClusterVersionMatch(t *testing.T) {
 	gatherer := newEmptyGatherer(nil, "")
 
@@ -475,6 +615,33 @@ func TestBuiltInConfigIsUsed(t *testing.T) {
 	assert.Equal(t, defaultRemoteConfiguration, string(gatherer.RemoteConfigStatus().ConfigData))
 }
 
+func Test_Gatherer_GetGatheringFunctions_LogBudget(t *testing.T) {
+	gatherer := newEmptyGatherer(&MockGatheringRulesServiceClient{err: fmt.Errorf("unavailable")}, "")
+
+	closures, err := gatherer.GetGatheringFunctions(context.Background())
+	assert.NoError(t, err)
+	firstBudget := gatherer.logBudget
+	assert.Equal(t, int64(containerLogsMaxBytes), firstBudget.MaxBytes())
+	// the budget is split equally among the log gathering functions before they run
+	var logFunctions int64
+	for name := range closures {
+		if name == "rapid_container_logs" ||
+			strings.HasPrefix(name, string(GatherContainersLogs)) || strings.HasPrefix(name, string(GatherLogsOfNamespace)) {
+			logFunctions++
+		}
+	}
+	assert.Equal(t, int64(containerLogsMaxBytes)/logFunctions, firstBudget.Share())
+
+	gatherer.configurator = config.NewMockConfigMapConfigurator(&config.InsightsConfiguration{
+		DataReporting: config.DataReporting{ConditionalLogsMaxBytes: 16 * 1024 * 1024},
+	})
+	_, err = gatherer.GetGatheringFunctions(context.Background())
+	assert.NoError(t, err)
+	// every gathering gets its own budget shared by its log gathering functions
+	assert.NotSame(t, firstBudget, gatherer.logBudget)
+	assert.Equal(t, int64(16*1024*1024), gatherer.logBudget.MaxBytes())
+}
+
 func newEmptyGatherer(remoteConfig *MockGatheringRulesServiceClient, conditionalGathererEndpoint string) *Gatherer { // nolint:gocritic
 	if conditionalGathererEndpoint == "" {
 		conditionalGathererEndpoint = "/gathering_rules"
@@ -482,6 +649,7 @@ func newEmptyGatherer(remoteConfig *MockGatheringRulesServiceClient, conditional
 	testConf := &config.InsightsConfiguration{
 		DataReporting: config.DataReporting{
 			ConditionalGathererEndpoint: conditionalGathererEndpoint,
+			RemoteConfigPublicKeys:      testPublicKeys,
 		},
 	}
 	mockConfigurator := config.NewMockConfigMapConfigurator(testConf)
@@ -510,11 +678,10 @@ func (s *MockGatheringRulesServiceClient) GetWithPathParam(_ context.Context, en
 		return nil, s.err
 	}
 	if strings.HasSuffix(endpoint, "gathering_rules") {
-		resp := &http.Response{
+		return &http.Response{
 			StatusCode: cmp.Or(s.status, http.StatusOK),
 			Body:       io.NopCloser(strings.NewReader(cmp.Or(s.value, testRemoteConfig))),
-		}
-		return resp, nil
+		}, nil
 	}
 
 	return nil, fmt.Errorf("endpoint not supported")
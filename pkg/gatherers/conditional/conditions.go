@@ -4,12 +4,13 @@ package conditional
 // 1. Add *ConditionParams field to ConditionWithParams
 // 2. Create a value in ConditionType enum
 // 3. Create *ConditionParam type
-// 4. Modify areAllConditionsSatisfied function to handle the new condition. All the initialization code such as
+// 4. Modify isConditionSatisfied function to handle the new condition. All the initialization code such as
 // populating a cache with values should be written in conditional_gatherer.go file
 // 5. Add validation in gathering_rule.schema.json
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/blang/semver/v4"
 )
@@ -19,6 +20,15 @@ type ConditionWithParams struct {
 	Type                  ConditionType                         `json:"type"`
 	Alert                 *AlertConditionParams                 `json:"alert,omitempty"`
 	ClusterVersionMatches *ClusterVersionMatchesConditionParams `json:"cluster_version_matches,omitempty"`
+	MetricMatches         *MetricMatchesConditionParams         `json:"metric_matches,omitempty"`
+	Platform              *PlatformIsConditionParams            `json:"platform,omitempty"`
+	FeatureGate           *FeatureGateEnabledConditionParams    `json:"feature_gate,omitempty"`
+	ClusterOperator       *ClusterOperatorConditionParams       `json:"cluster_operator,omitempty"`
+	Resource              *ResourceExistsConditionParams        `json:"resource,omitempty"`
+	// Conditions are the nested conditions of the all and any conditions
+	Conditions []ConditionWithParams `json:"conditions,omitempty"`
+	// Condition is the nested condition of the not condition
+	Condition *ConditionWithParams `json:"condition,omitempty"`
 }
 
 // condition types:
@@ -34,6 +44,39 @@ const AlertIsFiring ConditionType = "alert_is_firing"
 // matches the provided semantic versioning expression
 const ClusterVersionMatches ConditionType = "cluster_version_matches"
 
+// MetricMatches is a condition to check that the value of an instant Prometheus query
+// compared with the threshold by the operator is true, the params are in the field `metric_matches`
+const MetricMatches ConditionType = "metric_matches"
+
+// PlatformIs is a condition to check that the cluster runs on the infrastructure platform
+// the params are in the field `platform`
+const PlatformIs ConditionType = "platform_is"
+
+// FeatureGateEnabled is a condition to check that the feature gate is enabled for the current cluster version
+// the params are in the field `feature_gate`
+const FeatureGateEnabled ConditionType = "feature_gate_enabled"
+
+// ClusterOperatorDegraded is a condition to check that the cluster operator is degraded
+// the params are in the field `cluster_operator`
+const ClusterOperatorDegraded ConditionType = "cluster_operator_degraded"
+
+// ResourceExists is a condition to check that the resource exists in the cluster
+// the params are in the field `resource`
+const ResourceExists ConditionType = "resource_exists"
+
+// All is a condition group satisfied when all the nested conditions in the field `conditions` are satisfied
+const All ConditionType = "all"
+
+// Any is a condition group satisfied when at least one of the nested conditions in the field `conditions`
+// is satisfied
+const Any ConditionType = "any"
+
+// Not is a condition satisfied when the nested condition in the field `condition` is not satisfied
+const Not ConditionType = "not"
+
+// maxConditionDepth is the maximum nesting level of the all, any and not conditions
+const maxConditionDepth = 4
+
 // params:
 
 // AlertConditionParams is a type holding params for alert_is_firing condition
@@ -48,28 +91,149 @@ type ClusterVersionMatchesConditionParams struct {
 	Version string `json:"version"`
 }
 
+// MetricMatchesConditionParams is a type holding params for metric_matches condition
+type MetricMatchesConditionParams struct {
+	// Query is an instant PromQL query
+	Query string `json:"query"`
+	// Operator is used to compare the query result with the threshold, one of >, >=, <, <=, == and !=
+	Operator string `json:"operator"`
+	// Threshold is the value compared with the query result
+	Threshold float64 `json:"threshold"`
+}
+
+// PlatformIsConditionParams is a type holding params for platform_is condition
+type PlatformIsConditionParams struct {
+	// Type is the infrastructure platform type such as AWS or BareMetal, the comparison is case-insensitive
+	Type string `json:"type"`
+}
+
+// FeatureGateEnabledConditionParams is a type holding params for feature_gate_enabled condition
+type FeatureGateEnabledConditionParams struct {
+	// Name of the feature gate
+	Name string `json:"name"`
+}
+
+// ClusterOperatorConditionParams is a type holding params for cluster_operator_degraded condition
+type ClusterOperatorConditionParams struct {
+	// Name of the cluster operator
+	Name string `json:"name"`
+}
+
+// ResourceExistsConditionParams is a type holding params for resource_exists condition.
+// Without the name the condition is satisfied if any resource of the group, version and resource exists
+// (in the namespace if it's set).
+type ResourceExistsConditionParams struct {
+	Group     string `json:"group"`
+	Version   string `json:"version"`
+	Resource  string `json:"resource"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+}
+
+// key returns the key of the resource in the cache
+func (r *ResourceExistsConditionParams) key() string {
+	return strings.Join([]string{r.Group, r.Version, r.Resource, r.Namespace, r.Name}, "/")
+}
+
 // conditions definitions:
 
 // areAllConditionsSatisfied returns true if all the conditions are satisfied, for example if the condition is
 // to check if a metric is firing, it will look at that metric and return the result according to that
 func (g *Gatherer) areAllConditionsSatisfied(conditions []ConditionWithParams) (bool, error) {
 	for k := range conditions {
-		condition := conditions[k]
+		if ok, err := g.isConditionSatisfied(&conditions[k]); !ok || err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// isAnyConditionSatisfied returns true if at least one of the conditions is satisfied. The error of a condition
+// is returned only when none of the conditions is satisfied.
+func (g *Gatherer) isAnyConditionSatisfied(conditions []ConditionWithParams) (bool, error) {
+	var firstErr error
+	for k := range conditions {
+		ok, err := g.isConditionSatisfied(&conditions[k])
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if ok {
+			return true, nil
+		}
+	}
+
+	return false, firstErr
+}
+
+// isConditionSatisfied evaluates a single condition including the nested condition groups
+func (g *Gatherer) isConditionSatisfied(condition *ConditionWithParams) (bool, error) {
+	switch condition.Type {
+	case AlertIsFiring:
+		return g.checkAlertIsFiring(condition.Alert)
+	case ClusterVersionMatches:
+		return g.checkClusterVersionMatches(condition.ClusterVersionMatches)
+	case MetricMatches:
+		return g.checkMetricMatches(condition.MetricMatches)
+	case PlatformIs:
+		return g.checkPlatformIs(condition.Platform)
+	case FeatureGateEnabled:
+		return g.checkFeatureGateEnabled(condition.FeatureGate)
+	case ClusterOperatorDegraded:
+		return g.checkClusterOperatorDegraded(condition.ClusterOperator)
+	case ResourceExists:
+		return g.checkResourceExists(condition.Resource)
+	case All:
+		return g.areAllConditionsSatisfied(condition.Conditions)
+	case Any:
+		return g.isAnyConditionSatisfied(condition.Conditions)
+	case Not:
+		if condition.Condition == nil {
+			return false, fmt.Errorf("condition field should not be nil")
+		}
+		ok, err := g.isConditionSatisfied(condition.Condition)
+		if err != nil {
+			return false, err
+		}
+		return !ok, nil
+	default:
+		return false, fmt.Errorf("unknown condition type: %v", condition.Type)
+	}
+}
+
+// validateConditionGroups checks the structure of the all, any and not conditions
+// which can't be fully expressed by the JSON schema, such as the nesting level
+func validateConditionGroups(conditions []ConditionWithParams, depth int) error {
+	for i := range conditions {
+		condition := &conditions[i]
 		switch condition.Type {
-		case AlertIsFiring:
-			if ok, err := g.checkAlertIsFiring(condition.Alert); !ok || err != nil {
-				return false, err
+		case All, Any:
+			if depth >= maxConditionDepth {
+				return fmt.Errorf("the %s condition exceeds the maximum nesting level %d", condition.Type, maxConditionDepth)
+			}
+			if len(condition.Conditions) == 0 {
+				return fmt.Errorf("the %s condition has no nested conditions", condition.Type)
+			}
+			if err := validateConditionGroups(condition.Conditions, depth+1); err != nil {
+				return err
+			}
+		case Not:
+			if depth >= maxConditionDepth {
+				return fmt.Errorf("the %s condition exceeds the maximum nesting level %d", condition.Type, maxConditionDepth)
 			}
-		case ClusterVersionMatches:
-			if ok, err := g.checkClusterVersionMatches(condition.ClusterVersionMatches); !ok || err != nil {
-				return false, err
+			if condition.Condition == nil {
+				return fmt.Errorf("the %s condition has no nested condition", condition.Type)
+			}
+			if err := validateConditionGroups([]ConditionWithParams{*condition.Condition}, depth+1); err != nil {
+				return err
 			}
-		default:
-			return false, fmt.Errorf("unknown condition type: %v", condition.Type)
 		}
 	}
 
-	return true, nil
+	return nil
 }
 
 // checkAlertIsFiring verifies whether an alert is currently in a firing state. This function is used to
@@ -92,6 +256,170 @@ func (g *Gatherer) checkClusterVersionMatches(clusterVersion *ClusterVersionMatc
 	return g.doesClusterVersionMatch(clusterVersion.Version)
 }
 
+// checkMetricMatches verifies whether the value of the Prometheus query matches the threshold. This function is used
+// to evaluate the MetricMatches condition.
+func (g *Gatherer) checkMetricMatches(metric *MetricMatchesConditionParams) (bool, error) {
+	if metric == nil {
+		return false, fmt.Errorf("metric_matches field should not be nil")
+	}
+
+	return g.doesMetricMatch(metric.Query, metric.Operator, metric.Threshold)
+}
+
+// doesMetricMatch using the cache it returns true if any of the query result values compared
+// with the threshold by the operator is true. An empty result doesn't match.
+func (g *Gatherer) doesMetricMatch(query, operator string, threshold float64) (bool, error) {
+	if g.metricValues == nil {
+		return false, fmt.Errorf("metrics cache is missing")
+	}
+
+	values, found := g.metricValues[query]
+	if !found {
+		return false, fmt.Errorf("value of the query %q is missing", query)
+	}
+
+	for _, value := range values {
+		ok, err := compareMetricValue(value, operator, threshold)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+func compareMetricValue(value float64, operator string, threshold float64) (bool, error) {
+	switch operator {
+	case ">":
+		return value > threshold, nil
+	case ">=":
+		return value >= threshold, nil
+	case "<":
+		return value < threshold, nil
+	case "<=":
+		return value <= threshold, nil
+	case "==":
+		return value == threshold, nil
+	case "!=":
+		return value != threshold, nil
+	default:
+		return false, fmt.Errorf("unknown operator: %v", operator)
+	}
+}
+
+// checkPlatformIs verifies whether the cluster runs on the platform. This function is used to evaluate
+// the PlatformIs condition.
+func (g *Gatherer) checkPlatformIs(platform *PlatformIsConditionParams) (bool, error) {
+	if platform == nil {
+		return false, fmt.Errorf("platform field should not be nil")
+	}
+
+	if len(g.platform) == 0 {
+		return false, fmt.Errorf("platform is missing")
+	}
+
+	return strings.EqualFold(g.platform, platform.Type), nil
+}
+
+// checkFeatureGateEnabled verifies whether the feature gate is enabled. This function is used to evaluate
+// the FeatureGateEnabled condition.
+func (g *Gatherer) checkFeatureGateEnabled(featureGate *FeatureGateEnabledConditionParams) (bool, error) {
+	if featureGate == nil {
+		return false, fmt.Errorf("feature_gate field should not be nil")
+	}
+
+	if g.enabledFeatureGates == nil {
+		return false, fmt.Errorf("feature gates cache is missing")
+	}
+
+	_, enabled := g.enabledFeatureGates[featureGate.Name]
+	return enabled, nil
+}
+
+// checkClusterOperatorDegraded verifies whether the cluster operator is degraded. This function is used to evaluate
+// the ClusterOperatorDegraded condition.
+func (g *Gatherer) checkClusterOperatorDegraded(clusterOperator *ClusterOperatorConditionParams) (bool, error) {
+	if clusterOperator == nil {
+		return false, fmt.Errorf("cluster_operator field should not be nil")
+	}
+
+	if g.degradedOperators == nil {
+		return false, fmt.Errorf("cluster operators cache is missing")
+	}
+
+	_, degraded := g.degradedOperators[clusterOperator.Name]
+	return degraded, nil
+}
+
+// checkResourceExists verifies whether the resource exists in the cluster. This function is used to evaluate
+// the ResourceExists condition.
+func (g *Gatherer) checkResourceExists(resource *ResourceExistsConditionParams) (bool, error) {
+	if resource == nil {
+		return false, fmt.Errorf("resource field should not be nil")
+	}
+
+	if g.existingResources == nil {
+		return false, fmt.Errorf("resources cache is missing")
+	}
+
+	exists, found := g.existingResources[resource.key()]
+	if !found {
+		return false, fmt.Errorf("existence of the resource %q is unknown", resource.key())
+	}
+	return exists, nil
+}
+
+// walkConditions calls the function for all the conditions of the rules including the nested conditions
+func walkConditions(rules []GatheringRule, fn func(condition *ConditionWithParams)) {
+	var walk func(conditions []ConditionWithParams)
+	walk = func(conditions []ConditionWithParams) {
+		for i := range conditions {
+			condition := &conditions[i]
+			fn(condition)
+			walk(condition.Conditions)
+			if condition.Condition != nil {
+				walk([]ConditionWithParams{*condition.Condition})
+			}
+		}
+	}
+	for i := range rules {
+		walk(rules[i].Conditions)
+	}
+}
+
+// metricQueries returns the unique queries of all the metric_matches conditions of the rules
+// including the nested conditions
+func metricQueries(rules []GatheringRule) []string {
+	var queries []string
+	seen := make(map[string]struct{})
+	walkConditions(rules, func(condition *ConditionWithParams) {
+		if condition.MetricMatches == nil {
+			return
+		}
+		if _, exists := seen[condition.MetricMatches.Query]; !exists {
+			seen[condition.MetricMatches.Query] = struct{}{}
+			queries = append(queries, condition.MetricMatches.Query)
+		}
+	})
+	return queries
+}
+
+// resourceQueries returns the unique resources of all the resource_exists conditions of the rules
+// including the nested conditions
+func resourceQueries(rules []GatheringRule) []ResourceExistsConditionParams {
+	var resources []ResourceExistsConditionParams
+	seen := make(map[string]struct{})
+	walkConditions(rules, func(condition *ConditionWithParams) {
+		if condition.Resource == nil {
+			return
+		}
+		if _, exists := seen[condition.Resource.key()]; !exists {
+			seen[condition.Resource.key()] = struct{}{}
+			resources = append(resources, *condition.Resource)
+		}
+	})
+	return resources
+}
+
 // isAlertFiring using the cache it returns true if the alert is firing
 func (g *Gatherer) isAlertFiring(alertName string) (bool, error) {
 	if g.firingAlerts == nil {
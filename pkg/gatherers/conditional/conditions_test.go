@@ -215,3 +215,332 @@ func TestGatherer_checkClusterVersionMatches(t *testing.T) {
 		})
 	}
 }
+
+func TestGatherer_checkMetricMatches(t *testing.T) {
+	metricValues := map[string][]float64{
+		"failed_pods":  {3, 12},
+		"etcd_leaders": {1},
+		"empty":        {},
+	}
+	tests := []struct {
+		name            string
+		metricValues    map[string][]float64
+		conditionParams *MetricMatchesConditionParams
+		want            bool
+		wantErr         error
+	}{
+		{
+			name:            "any value is greater than threshold",
+			metricValues:    metricValues,
+			conditionParams: &MetricMatchesConditionParams{Query: "failed_pods", Operator: ">", Threshold: 10},
+			want:            true,
+		},
+		{
+			name:            "no value is less than threshold",
+			metricValues:    metricValues,
+			conditionParams: &MetricMatchesConditionParams{Query: "failed_pods", Operator: "<", Threshold: 3},
+			want:            false,
+		},
+		{
+			name:            "value equals threshold",
+			metricValues:    metricValues,
+			conditionParams: &MetricMatchesConditionParams{Query: "etcd_leaders", Operator: "==", Threshold: 1},
+			want:            true,
+		},
+		{
+			name:            "value is not different from threshold",
+			metricValues:    metricValues,
+			conditionParams: &MetricMatchesConditionParams{Query: "etcd_leaders", Operator: "!=", Threshold: 1},
+			want:            false,
+		},
+		{
+			name:            "empty result doesn't match",
+			metricValues:    metricValues,
+			conditionParams: &MetricMatchesConditionParams{Query: "empty", Operator: ">=", Threshold: 0},
+			want:            false,
+		},
+		{
+			name:            "unknown operator",
+			metricValues:    metricValues,
+			conditionParams: &MetricMatchesConditionParams{Query: "etcd_leaders", Operator: "=~", Threshold: 1},
+			wantErr:         fmt.Errorf("unknown operator: =~"),
+		},
+		{
+			name:            "query value is missing",
+			metricValues:    metricValues,
+			conditionParams: &MetricMatchesConditionParams{Query: "unknown", Operator: "<=", Threshold: 1},
+			wantErr:         fmt.Errorf(`value of the query "unknown" is missing`),
+		},
+		{
+			name:            "metrics cache is missing",
+			conditionParams: &MetricMatchesConditionParams{Query: "failed_pods", Operator: ">", Threshold: 1},
+			wantErr:         fmt.Errorf("metrics cache is missing"),
+		},
+		{
+			name:    "params are missing",
+			wantErr: fmt.Errorf("metric_matches field should not be nil"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Gatherer{metricValues: tt.metricValues}
+			got, err := g.checkMetricMatches(tt.conditionParams)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGatherer_isConditionSatisfied_ClusterConditions(t *testing.T) {
+	g := &Gatherer{
+		platform:            "AWS",
+		enabledFeatureGates: map[string]struct{}{"GatewayAPI": {}},
+		degradedOperators:   map[string]struct{}{"ingress": {}},
+		existingResources: map[string]bool{
+			"logging.openshift.io/v1/clusterloggings//":                          true,
+			"/v1/namespaces//openshift-storage":                                  false,
+			"operators.coreos.com/v1alpha1/subscriptions/openshift-logging/loki": true,
+		},
+	}
+	tests := []struct {
+		name      string
+		condition ConditionWithParams
+		want      bool
+		wantErr   error
+	}{
+		{
+			name:      "platform matches case-insensitively",
+			condition: ConditionWithParams{Type: PlatformIs, Platform: &PlatformIsConditionParams{Type: "aws"}},
+			want:      true,
+		},
+		{
+			name:      "platform doesn't match",
+			condition: ConditionWithParams{Type: PlatformIs, Platform: &PlatformIsConditionParams{Type: "BareMetal"}},
+			want:      false,
+		},
+		{
+			name:      "feature gate is enabled",
+			condition: ConditionWithParams{Type: FeatureGateEnabled, FeatureGate: &FeatureGateEnabledConditionParams{Name: "GatewayAPI"}},
+			want:      true,
+		},
+		{
+			name:      "feature gate is not enabled",
+			condition: ConditionWithParams{Type: FeatureGateEnabled, FeatureGate: &FeatureGateEnabledConditionParams{Name: "NodeSwap"}},
+			want:      false,
+		},
+		{
+			name:      "cluster operator is degraded",
+			condition: ConditionWithParams{Type: ClusterOperatorDegraded, ClusterOperator: &ClusterOperatorConditionParams{Name: "ingress"}},
+			want:      true,
+		},
+		{
+			name:      "cluster operator is not degraded",
+			condition: ConditionWithParams{Type: ClusterOperatorDegraded, ClusterOperator: &ClusterOperatorConditionParams{Name: "dns"}},
+			want:      false,
+		},
+		{
+			name: "any resource exists",
+			condition: ConditionWithParams{Type: ResourceExists, Resource: &ResourceExistsConditionParams{
+				Group: "logging.openshift.io", Version: "v1", Resource: "clusterloggings",
+			}},
+			want: true,
+		},
+		{
+			name: "named resource exists",
+			condition: ConditionWithParams{Type: ResourceExists, Resource: &ResourceExistsConditionParams{
+				Group: "operators.coreos.com", Version: "v1alpha1", Resource: "subscriptions", Namespace: "openshift-logging", Name: "loki",
+			}},
+			want: true,
+		},
+		{
+			name: "resource doesn't exist",
+			condition: ConditionWithParams{Type: ResourceExists, Resource: &ResourceExistsConditionParams{
+				Version: "v1", Resource: "namespaces", Name: "openshift-storage",
+			}},
+			want: false,
+		},
+		{
+			name: "resource existence is unknown",
+			condition: ConditionWithParams{Type: ResourceExists, Resource: &ResourceExistsConditionParams{
+				Version: "v1", Resource: "pods",
+			}},
+			wantErr: fmt.Errorf(`existence of the resource "/v1/pods//" is unknown`),
+		},
+		{
+			name:      "params are missing",
+			condition: ConditionWithParams{Type: PlatformIs},
+			wantErr:   fmt.Errorf("platform field should not be nil"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := g.isConditionSatisfied(&tt.condition)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	empty := &Gatherer{}
+	for _, condition := range []ConditionWithParams{
+		{Type: PlatformIs, Platform: &PlatformIsConditionParams{Type: "AWS"}},
+		{Type: FeatureGateEnabled, FeatureGate: &FeatureGateEnabledConditionParams{Name: "GatewayAPI"}},
+		{Type: ClusterOperatorDegraded, ClusterOperator: &ClusterOperatorConditionParams{Name: "ingress"}},
+		{Type: ResourceExists, Resource: &ResourceExistsConditionParams{Version: "v1", Resource: "pods"}},
+	} {
+		_, err := empty.isConditionSatisfied(&condition)
+		assert.Error(t, err, "the condition %v should fail without the cache", condition.Type)
+	}
+}
+
+func Test_metricQueries(t *testing.T) {
+	metricCondition := func(query string) ConditionWithParams {
+		return ConditionWithParams{
+			Type:          MetricMatches,
+			MetricMatches: &MetricMatchesConditionParams{Query: query, Operator: ">", Threshold: 0},
+		}
+	}
+	rules := []GatheringRule{
+		{Conditions: []ConditionWithParams{
+			metricCondition("query_a"),
+			{Type: AlertIsFiring, Alert: &AlertConditionParams{Name: "AlertA"}},
+		}},
+		{Conditions: []ConditionWithParams{
+			{Type: Any, Conditions: []ConditionWithParams{metricCondition("query_b"), metricCondition("query_a")}},
+			{Type: Not, Condition: &ConditionWithParams{Type: All, Conditions: []ConditionWithParams{metricCondition("query_c")}}},
+		}},
+	}
+
+	assert.Equal(t, []string{"query_a", "query_b", "query_c"}, metricQueries(rules))
+	assert.Empty(t, metricQueries(nil))
+}
+
+func Test_resourceQueries(t *testing.T) {
+	pods := ResourceExistsConditionParams{Version: "v1", Resource: "pods", Namespace: "openshift-logging"}
+	lokistacks := ResourceExistsConditionParams{Group: "loki.grafana.com", Version: "v1", Resource: "lokistacks"}
+	rules := []GatheringRule{
+		{Conditions: []ConditionWithParams{
+			{Type: ResourceExists, Resource: &pods},
+			{Type: Not, Condition: &ConditionWithParams{Type: ResourceExists, Resource: &lokistacks}},
+		}},
+		{Conditions: []ConditionWithParams{{Type: ResourceExists, Resource: &pods}}},
+	}
+
+	assert.Equal(t, []ResourceExistsConditionParams{pods, lokistacks}, resourceQueries(rules))
+}
+
+func TestGatherer_isConditionSatisfied_ConditionGroups(t *testing.T) {
+	alertA := ConditionWithParams{Type: AlertIsFiring, Alert: &AlertConditionParams{Name: "AlertA"}}
+	alertB := ConditionWithParams{Type: AlertIsFiring, Alert: &AlertConditionParams{Name: "AlertB"}}
+	// alert A or alert B is firing, but not on version 4.12.x
+	condition := ConditionWithParams{
+		Type: All,
+		Conditions: []ConditionWithParams{
+			{Type: Any, Conditions: []ConditionWithParams{alertA, alertB}},
+			{Type: Not, Condition: &ConditionWithParams{
+				Type:                  ClusterVersionMatches,
+				ClusterVersionMatches: &ClusterVersionMatchesConditionParams{Version: "4.12.x"},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		firingAlerts   map[string][]AlertLabels
+		clusterVersion string
+		want           bool
+		wantErr        error
+	}{
+		{
+			name:           "first alert is firing",
+			firingAlerts:   map[string][]AlertLabels{"AlertA": {}},
+			clusterVersion: "4.13.0",
+			want:           true,
+		},
+		{
+			name:           "second alert is firing",
+			firingAlerts:   map[string][]AlertLabels{"AlertB": {}},
+			clusterVersion: "4.13.0",
+			want:           true,
+		},
+		{
+			name:           "no alert is firing",
+			firingAlerts:   map[string][]AlertLabels{},
+			clusterVersion: "4.13.0",
+			want:           false,
+		},
+		{
+			name:           "excluded cluster version",
+			firingAlerts:   map[string][]AlertLabels{"AlertA": {}, "AlertB": {}},
+			clusterVersion: "4.12.5",
+			want:           false,
+		},
+		{
+			name:           "missing alerts cache",
+			clusterVersion: "4.13.0",
+			want:           false,
+			wantErr:        fmt.Errorf("alerts cache is missing"),
+		},
+		{
+			name:         "missing cluster version",
+			firingAlerts: map[string][]AlertLabels{"AlertA": {}},
+			want:         false,
+			wantErr:      fmt.Errorf("cluster version is missing"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Gatherer{
+				firingAlerts:   tt.firingAlerts,
+				clusterVersion: tt.clusterVersion,
+			}
+			got, err := g.isConditionSatisfied(&condition)
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_validateConditionGroups(t *testing.T) {
+	alert := ConditionWithParams{Type: AlertIsFiring, Alert: &AlertConditionParams{Name: "AlertA"}}
+	nested := alert
+	for i := 0; i < maxConditionDepth; i++ {
+		inner := nested
+		nested = ConditionWithParams{Type: Not, Condition: &inner}
+	}
+
+	tests := []struct {
+		name       string
+		conditions []ConditionWithParams
+		wantErr    error
+	}{
+		{
+			name:       "no condition groups",
+			conditions: []ConditionWithParams{alert},
+		},
+		{
+			name:       "maximum nesting level",
+			conditions: []ConditionWithParams{nested},
+		},
+		{
+			name:       "too deep nesting",
+			conditions: []ConditionWithParams{{Type: Any, Conditions: []ConditionWithParams{nested}}},
+			wantErr:    fmt.Errorf("the not condition exceeds the maximum nesting level 4"),
+		},
+		{
+			name:       "empty all condition",
+			conditions: []ConditionWithParams{{Type: All}},
+			wantErr:    fmt.Errorf("the all condition has no nested conditions"),
+		},
+		{
+			name:       "not condition without the nested condition",
+			conditions: []ConditionWithParams{{Type: Not}},
+			wantErr:    fmt.Errorf("the not condition has no nested condition"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantErr, validateConditionGroups(tt.conditions, 0))
+		})
+	}
+}
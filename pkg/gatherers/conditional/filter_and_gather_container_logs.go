@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	"github.com/openshift/insights-operator/pkg/gatherers"
+	"github.com/openshift/insights-operator/pkg/gatherers/common"
 	"github.com/openshift/insights-operator/pkg/record"
 	"github.com/openshift/insights-operator/pkg/types"
 	"github.com/openshift/insights-operator/pkg/utils"
@@ -29,10 +31,15 @@ import (
 type recordWithError struct {
 	r   *record.Record
 	err error
+	// namespace of the container log sharing the log budget, empty for the logs not subject to the budget
+	namespace string
 }
 
 var sinceSeconds = int64(6 * 60 * 60)
 
+// containerLogsMaxBytes is the default log byte budget shared by all the log gathering functions of one gathering
+const containerLogsMaxBytes = 8 * 1024 * 1024
+
 // GatherContainersLogs is used for more dynamic log gathering based on the
 // [Rapid Recommendations](https://github.com/openshift/enhancements/blob/master/enhancements/insights/rapid-recommendations.md).
 //
@@ -46,6 +53,16 @@ var sinceSeconds = int64(6 * 60 * 60)
 //
 // The configuration used for the data gathering is always stored in the Insights archive in the `insights-operator/remote-configuration.json` file.
 //
+// ### Location in archive
+// - `namespaces/{namespace}/pods/{pod}/{container}/current.log`
+// - `namespaces/{namespace}/pods/{pod}/{container}/previous.log`
+// - `namespaces/{namespace}/pods/{pod}/{container}/current-aggregated.json`
+// - `namespaces/{namespace}/pods/{pod}/{container}/previous-aggregated.json`
+// - `namespaces/{namespace}/pods/{pod}/{container}/current-structured.jsonl`
+// - `namespaces/{namespace}/pods/{pod}/{container}/previous-structured.jsonl`
+// - `insights-operator/remote-configuration.json`
+// - `insights-operator/conditional-gatherer-rules.json`
+//
 // ### Config ID
 // `conditional/rapid_container_logs`
 // `conditional/remote_configuration`
@@ -54,8 +71,13 @@ var sinceSeconds = int64(6 * 60 * 60)
 // ### Released version
 //
 // The gatherer finds the Pods (and containers) that match the requested data and filters all the container logs
-// to match the specific messages up to a maximum of 6 hours old.
+// to match the specific messages up to a maximum of 6 hours old. The logs share the log budget (8 MiB by default,
+// configurable with `dataReporting.conditionalLogsMaxBytes`) with the other conditional log gathering functions.
+// The share of the function in the budget is split fairly across the requested namespaces and their containers,
+// the oldest lines of the logs exceeding their share are dropped.
 func (g *Gatherer) GatherContainersLogs(rawLogRequests []RawLogRequest) (gatherers.GatheringClosure, error) { // nolint: dupl
+	logBudget := g.logBudget
+	logBudget.Join()
 	return gatherers.GatheringClosure{
 		Run: func(ctx context.Context) ([]record.Record, []error) {
 			kubeConfigCopy := rest.CopyConfig(g.gatherProtoKubeConfig)
@@ -66,7 +88,7 @@ func (g *Gatherer) GatherContainersLogs(rawLogRequests []RawLogRequest) (gathere
 				return nil, []error{err}
 			}
 			coreClient := kubeClient.CoreV1()
-			return gatherContainerLogs(ctx, coreClient, rawLogRequests)
+			return gatherContainerLogs(ctx, coreClient, rawLogRequests, logBudget)
 		},
 	}, nil
 }
@@ -75,9 +97,11 @@ func gatherContainerLogs(
 	ctx context.Context,
 	coreClient corev1client.CoreV1Interface,
 	rawLogRequests []RawLogRequest,
+	logBudget *common.SharedLogBudget,
 ) ([]record.Record, []error) {
 	var errs []error
 	var records []record.Record
+	var budgetedRecords []recordWithError
 
 	namespaceToLogRequestMap := groupRawLogRequestsByNamespace(rawLogRequests)
 	recCh := make(chan *recordWithError)
@@ -86,7 +110,9 @@ func gatherContainerLogs(
 	go func() {
 		defer receiveWG.Done()
 		for r := range recCh {
-			if r.r != nil {
+			if r.r != nil && r.namespace != "" {
+				budgetedRecords = append(budgetedRecords, *r)
+			} else if r.r != nil {
 				records = append(records, *r.r)
 			}
 			if r.err != nil {
@@ -110,6 +136,47 @@ func gatherContainerLogs(
 	sendWG.Wait()
 	close(recCh)
 	receiveWG.Wait()
+
+	budgetRecords, budgetErrs := applyContainerLogsBudget(budgetedRecords, logBudget)
+	return append(records, budgetRecords...), append(errs, budgetErrs...)
+}
+
+// applyContainerLogsBudget truncates the container logs so that together they fit into the share of the function
+// in the log budget, the share is split fairly by the namespaces and containers.
+// A warning is returned for every namespace with dropped log bytes. The logs are not limited when the budget is nil.
+func applyContainerLogsBudget(recordsWithErr []recordWithError, logBudget *common.SharedLogBudget) ([]record.Record, []error) {
+	logs := make([]common.ContainerLog, 0, len(recordsWithErr))
+	for _, r := range recordsWithErr {
+		log, err := r.r.Item.Marshal()
+		if err != nil {
+			return nil, []error{err}
+		}
+		logs = append(logs, common.ContainerLog{Namespace: r.namespace, Log: log})
+	}
+
+	droppedBytes := map[string]int64{}
+	if logBudget != nil {
+		droppedBytes = logBudget.Apply(logs)
+	}
+
+	records := make([]record.Record, 0, len(logs))
+	for i := range logs {
+		if len(logs[i].Log) == 0 {
+			continue
+		}
+		r := *recordsWithErr[i].r
+		r.Item = marshal.RawByte(logs[i].Log)
+		records = append(records, r)
+	}
+
+	var errs []error
+	for _, namespace := range sets.List(sets.KeySet(droppedBytes)) {
+		errs = append(errs, &types.Warning{UnderlyingValue: &common.ContainersSkippedError{
+			Namespace:    namespace,
+			MaxLogBytes:  logBudget.Share(),
+			DroppedBytes: droppedBytes[namespace],
+		}})
+	}
 	return records, errs
 }
 
@@ -161,6 +228,15 @@ func filterContainerLogs(ctx context.Context,
 			}
 			continue
 		}
+		fieldFilters, err := decodeFieldFilters(containersAndMessages.fieldFilters)
+		if err != nil {
+			recCh <- &recordWithError{
+				r: nil,
+				err: fmt.Errorf("failed to decode the field filters for the %s Pod in the %s namespace: %v",
+					podName, logRequest.Namespace, err),
+			}
+			continue
+		}
 		wgContainers.Add(len(containersAndMessages.containerNames))
 		for _, container := range containersAndMessages.containerNames {
 			containerLogReq := ContainerLogRequest{
@@ -169,6 +245,12 @@ func filterContainerLogs(ctx context.Context,
 				PodName:       podName,
 				MessageRegex:  messagesRegex,
 				Previous:      containersAndMessages.previous,
+				FieldFilters:  fieldFilters,
+			}
+			// the structured log lines are neither aggregated nor kept with the context
+			if len(fieldFilters) == 0 {
+				containerLogReq.Aggregate = containersAndMessages.aggregate
+				containerLogReq.LinesContext = containersAndMessages.linesContext
 			}
 			go func() {
 				defer wgContainers.Done()
@@ -177,6 +259,10 @@ func filterContainerLogs(ctx context.Context,
 					r:   rec,
 					err: err,
 				}
+				// the aggregated logs are JSON documents and they can't be truncated
+				if !containerLogReq.Aggregate {
+					recWithErr.namespace = containerLogReq.Namespace
+				}
 				recCh <- recWithErr
 			}()
 		}
@@ -203,16 +289,49 @@ func getAndFilterContainerLogs(ctx context.Context, coreClient corev1client.Core
 	defer stream.Close()
 	scanner := bufio.NewScanner(stream)
 	var byteBuffer bytes.Buffer
+	var matchingLines []string
+	var contextFilter *common.LogContextFilter
+	if !containerLogRequest.LinesContext.IsEmpty() {
+		contextFilter = common.NewLogContextFilter(containerLogRequest.LinesContext)
+	}
 
 	for scanner.Scan() {
 		line := scanner.Bytes()
-		if containerLogRequest.MessageRegex.Match(line) {
-			line = append(line, '\n')
-			_, err = byteBuffer.Write(line)
+		matches := containerLogRequest.MessageRegex.Match(line)
+		switch {
+		case len(containerLogRequest.FieldFilters) > 0:
+			if !matches {
+				continue
+			}
+			data, ok, err := common.FilterStructuredLogLine(string(line), containerLogRequest.FieldFilters)
 			if err != nil {
-				klog.Errorf("Failed to write line for container %s in the %s: %v",
-					containerLogRequest.ContainerName, containerLogRequest.Namespace, err)
+				return nil, err
+			}
+			if ok {
+				writeLogLine(&byteBuffer, data, containerLogRequest)
 			}
+		case contextFilter != nil:
+			contextFilter.Add(string(line), matches)
+		case !matches:
+		case containerLogRequest.Aggregate:
+			matchingLines = append(matchingLines, string(line))
+		default:
+			writeLogLine(&byteBuffer, line, containerLogRequest)
+		}
+	}
+
+	if contextFilter != nil {
+		matchingLines = contextFilter.Lines()
+		if !containerLogRequest.Aggregate {
+			for _, line := range matchingLines {
+				writeLogLine(&byteBuffer, []byte(line), containerLogRequest)
+			}
+		}
+	}
+
+	if containerLogRequest.Aggregate && len(matchingLines) > 0 {
+		if err := json.NewEncoder(&byteBuffer).Encode(common.AggregateLogLines(matchingLines)); err != nil {
+			return nil, err
 		}
 	}
 
@@ -229,9 +348,17 @@ func getAndFilterContainerLogs(ctx context.Context, coreClient corev1client.Core
 		containerLogRequest.PodName,
 		containerLogRequest.ContainerName)
 
-	recordName := fmt.Sprintf("%s/current.log", recordPath)
+	recordName := fmt.Sprintf("%s/current", recordPath)
 	if containerLogRequest.Previous {
-		recordName = fmt.Sprintf("%s/previous.log", recordPath)
+		recordName = fmt.Sprintf("%s/previous", recordPath)
+	}
+	switch {
+	case len(containerLogRequest.FieldFilters) > 0:
+		recordName += "-structured.jsonl"
+	case containerLogRequest.Aggregate:
+		recordName += "-aggregated.json"
+	default:
+		recordName += ".log"
 	}
 
 	r := record.Record{
@@ -241,6 +368,15 @@ func getAndFilterContainerLogs(ctx context.Context, coreClient corev1client.Core
 	return &r, nil
 }
 
+// writeLogLine writes the log line terminated with the new line to the buffer
+func writeLogLine(byteBuffer *bytes.Buffer, line []byte, containerLogRequest ContainerLogRequest) {
+	line = append(line, '\n')
+	if _, err := byteBuffer.Write(line); err != nil {
+		klog.Errorf("Failed to write line for container %s in the %s: %v",
+			containerLogRequest.ContainerName, containerLogRequest.Namespace, err)
+	}
+}
+
 // groupRawLogRequestsByNamespace iterates over slice of the provided raw log requests and maps
 // them with namespace name as the key and the logRequest as the value. The LogRequest data structure
 // contains another map for mapping Pod name regex together with Previous value
@@ -248,7 +384,17 @@ func getAndFilterContainerLogs(ctx context.Context, coreClient corev1client.Core
 func groupRawLogRequestsByNamespace(rawLogRequests []RawLogRequest) map[string]LogRequest {
 	namespaceToLogRequestMap := make(map[string]LogRequest, len(rawLogRequests))
 	for _, logRequest := range rawLogRequests {
-		podNameRegexPrevious := PodNameRegexPrevious{PodNameRegex: logRequest.PodNameRegex, Previous: logRequest.Previous}
+		podNameRegexPrevious := PodNameRegexPrevious{
+			PodNameRegex: logRequest.PodNameRegex,
+			Previous:     logRequest.Previous,
+			Aggregate:    logRequest.Aggregate,
+			LinesContext: common.LogLinesContext{
+				Before:       logRequest.ContextBefore,
+				After:        logRequest.ContextAfter,
+				Continuation: logRequest.ContinuationLines,
+			},
+			FieldFilters: encodeFieldFilters(logRequest.FieldFilters),
+		}
 		existingLogRequest, ok := namespaceToLogRequestMap[logRequest.Namespace]
 
 		if !ok {
@@ -275,6 +421,9 @@ type containersAndMessages struct {
 	containerNames []string
 	messsages      sets.Set[string]
 	previous       bool
+	aggregate      bool
+	linesContext   common.LogLinesContext
+	fieldFilters   string
 }
 
 // createPodToContainersAndMessagesMapping iterates over all the Pod name regular
@@ -303,6 +452,13 @@ func createPodToContainersAndMessagesMapping(ctx context.Context,
 			if podNameRegex.Match([]byte(pod.Name)) { // nolint: gocritic
 				if cm, ok := podContainers[pod.Name]; ok {
 					cm.messsages = cm.messsages.Union(messages)
+					// the log is aggregated only when all the requests for the Pod ask for it
+					cm.aggregate = cm.aggregate && podNameRegexKey.Aggregate
+					cm.linesContext = mergeLinesContexts(cm.linesContext, podNameRegexKey.LinesContext)
+					// the structured log lines are kept only when all the requests for the Pod ask for the same field filters
+					if cm.fieldFilters != podNameRegexKey.FieldFilters {
+						cm.fieldFilters = ""
+					}
 					podContainers[pod.Name] = cm
 				} else {
 					var containerNames []string
@@ -314,6 +470,9 @@ func createPodToContainersAndMessagesMapping(ctx context.Context,
 						messsages:      messages,
 						containerNames: containerNames,
 						previous:       podNameRegexKey.Previous,
+						aggregate:      podNameRegexKey.Aggregate,
+						linesContext:   podNameRegexKey.LinesContext,
+						fieldFilters:   podNameRegexKey.FieldFilters,
 					}
 				}
 			}
@@ -322,6 +481,41 @@ func createPodToContainersAndMessagesMapping(ctx context.Context,
 	return podContainers, regexErrs
 }
 
+// mergeLinesContexts returns the context keeping all the lines kept by any of the provided contexts
+func mergeLinesContexts(a, b common.LogLinesContext) common.LogLinesContext {
+	return common.LogLinesContext{
+		Before:       max(a.Before, b.Before),
+		After:        max(a.After, b.After),
+		Continuation: a.Continuation || b.Continuation,
+	}
+}
+
+// encodeFieldFilters encodes the field filters to the JSON string, so that they can be used in the map key.
+// The empty string is returned when there are no filters.
+func encodeFieldFilters(fieldFilters []common.LogFieldFilter) string {
+	if len(fieldFilters) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(fieldFilters)
+	if err != nil {
+		klog.Errorf("Failed to encode the log field filters: %v", err)
+		return ""
+	}
+	return string(data)
+}
+
+// decodeFieldFilters decodes the field filters encoded by the encodeFieldFilters
+func decodeFieldFilters(encoded string) ([]common.LogFieldFilter, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+	var fieldFilters []common.LogFieldFilter
+	if err := json.Unmarshal([]byte(encoded), &fieldFilters); err != nil {
+		return nil, err
+	}
+	return fieldFilters, nil
+}
+
 // listOfMessagesToRegex takes the provided set of strings and each message
 // is appended as "|" (or value) to the final regular expression, which is then compiled.
 // It returns an error if the provided set is empty, nil or if the created regular expression
@@ -3,11 +3,15 @@ package conditional
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"regexp"
 	"regexp/syntax"
 	"strings"
 	"testing"
 
+	"github.com/openshift/insights-operator/pkg/gatherers/common"
 	"github.com/openshift/insights-operator/pkg/record"
 	"github.com/openshift/insights-operator/pkg/types"
 	"github.com/openshift/insights-operator/pkg/utils/marshal"
@@ -16,6 +20,9 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
 )
 
 func TestGroupRawLogRequestsByNamespace(t *testing.T) {
@@ -55,13 +62,13 @@ func TestGroupRawLogRequestsByNamespace(t *testing.T) {
 				"namespace-A": {
 					Namespace: "namespace-A",
 					PodNameRegexToMessages: map[PodNameRegexPrevious]sets.Set[string]{
-						{"test-A-.*", true}: sets.Set[string](sets.NewString("message 1.*", "message 2.*")),
+						{PodNameRegex: "test-A-.*", Previous: true}: sets.Set[string](sets.NewString("message 1.*", "message 2.*")),
 					},
 				},
 				"namespace-B": {
 					Namespace: "namespace-B",
 					PodNameRegexToMessages: map[PodNameRegexPrevious]sets.Set[string]{
-						{"test-B-.*", false}: sets.Set[string](sets.NewString("message 1.*", "message 2.*")),
+						{PodNameRegex: "test-B-.*"}: sets.Set[string](sets.NewString("message 1.*", "message 2.*")),
 					},
 				},
 			},
@@ -102,8 +109,8 @@ func TestGroupRawLogRequestsByNamespace(t *testing.T) {
 				"namespace-A": {
 					Namespace: "namespace-A",
 					PodNameRegexToMessages: map[PodNameRegexPrevious]sets.Set[string]{
-						{"test-A-.*", true}:  sets.Set[string](sets.NewString("message 1.*", "message 2.*")),
-						{"test-B-.*", false}: sets.Set[string](sets.NewString("message 1.*", "message 2.*", "message 3.*", "message 4.*", "message 5.*")),
+						{PodNameRegex: "test-A-.*", Previous: true}: sets.Set[string](sets.NewString("message 1.*", "message 2.*")),
+						{PodNameRegex: "test-B-.*"}:                 sets.Set[string](sets.NewString("message 1.*", "message 2.*", "message 3.*", "message 4.*", "message 5.*")),
 					},
 				},
 			},
@@ -134,8 +141,86 @@ func TestGroupRawLogRequestsByNamespace(t *testing.T) {
 				"namespace-A": {
 					Namespace: "namespace-A",
 					PodNameRegexToMessages: map[PodNameRegexPrevious]sets.Set[string]{
-						{"test-A-.*", true}:  sets.Set[string](sets.NewString("message 1.*", "message 2.*")),
-						{"test-A-.*", false}: sets.Set[string](sets.NewString("message 3.*", "message 4.*")),
+						{PodNameRegex: "test-A-.*", Previous: true}: sets.Set[string](sets.NewString("message 1.*", "message 2.*")),
+						{PodNameRegex: "test-A-.*"}:                 sets.Set[string](sets.NewString("message 3.*", "message 4.*")),
+					},
+				},
+			},
+		},
+		{
+			name: "same Pod regex in the same namespace but different aggregation",
+			rawLogReuests: []RawLogRequest{
+				{
+					Namespace:    "namespace-A",
+					PodNameRegex: "test-A-.*",
+					Messages: []string{
+						"message 1.*",
+					},
+				},
+				{
+					Namespace:    "namespace-A",
+					PodNameRegex: "test-A-.*",
+					Aggregate:    true,
+					Messages: []string{
+						"message 2.*",
+					},
+				},
+			},
+			expectedResult: map[string]LogRequest{
+				"namespace-A": {
+					Namespace: "namespace-A",
+					PodNameRegexToMessages: map[PodNameRegexPrevious]sets.Set[string]{
+						{PodNameRegex: "test-A-.*"}:                  sets.Set[string](sets.NewString("message 1.*")),
+						{PodNameRegex: "test-A-.*", Aggregate: true}: sets.Set[string](sets.NewString("message 2.*")),
+					},
+				},
+			},
+		},
+		{
+			name: "field filters are part of the Pod regex key",
+			rawLogReuests: []RawLogRequest{
+				{
+					Namespace:    "namespace-A",
+					PodNameRegex: "test-A-.*",
+					FieldFilters: []common.LogFieldFilter{{Field: "level", Values: []string{"error"}}},
+					Messages: []string{
+						"timeout",
+					},
+				},
+			},
+			expectedResult: map[string]LogRequest{
+				"namespace-A": {
+					Namespace: "namespace-A",
+					PodNameRegexToMessages: map[PodNameRegexPrevious]sets.Set[string]{
+						{
+							PodNameRegex: "test-A-.*",
+							FieldFilters: `[{"field":"level","values":["error"]}]`,
+						}: sets.Set[string](sets.NewString("timeout")),
+					},
+				},
+			},
+		},
+		{
+			name: "context lines are part of the Pod regex key",
+			rawLogReuests: []RawLogRequest{
+				{
+					Namespace:         "namespace-A",
+					PodNameRegex:      "test-A-.*",
+					ContextBefore:     2,
+					ContinuationLines: true,
+					Messages: []string{
+						"panic:",
+					},
+				},
+			},
+			expectedResult: map[string]LogRequest{
+				"namespace-A": {
+					Namespace: "namespace-A",
+					PodNameRegexToMessages: map[PodNameRegexPrevious]sets.Set[string]{
+						{
+							PodNameRegex: "test-A-.*",
+							LinesContext: common.LogLinesContext{Before: 2, Continuation: true},
+						}: sets.Set[string](sets.NewString("panic:")),
 					},
 				},
 			},
@@ -214,7 +299,7 @@ func TestCreatePodToContainersMap(t *testing.T) {
 			logRequest: LogRequest{
 				Namespace: "test-namespace",
 				PodNameRegexToMessages: map[PodNameRegexPrevious]sets.Set[string]{
-					{"foo-pod.*", false}: sets.Set[string](sets.NewString("foo-message")),
+					{PodNameRegex: "foo-pod.*"}: sets.Set[string](sets.NewString("foo-message")),
 				},
 			},
 			pods:        []*corev1.Pod{},
@@ -225,8 +310,8 @@ func TestCreatePodToContainersMap(t *testing.T) {
 			logRequest: LogRequest{
 				Namespace: "test-namespace",
 				PodNameRegexToMessages: map[PodNameRegexPrevious]sets.Set[string]{
-					{"(?!BBB)", false}: sets.Set[string](sets.NewString("foo-message")),
-					{"?!", false}:      sets.Set[string](sets.NewString("foo-message")),
+					{PodNameRegex: "(?!BBB)"}: sets.Set[string](sets.NewString("foo-message")),
+					{PodNameRegex: "?!"}:      sets.Set[string](sets.NewString("foo-message")),
 				},
 			},
 			pods:        []*corev1.Pod{},
@@ -241,7 +326,7 @@ func TestCreatePodToContainersMap(t *testing.T) {
 			logRequest: LogRequest{
 				Namespace: "test-namespace",
 				PodNameRegexToMessages: map[PodNameRegexPrevious]sets.Set[string]{
-					{"foo-.*", false}: sets.Set[string](sets.NewString("foo-message")),
+					{PodNameRegex: "foo-.*"}: sets.Set[string](sets.NewString("foo-message")),
 				},
 			},
 			pods: []*corev1.Pod{
@@ -326,8 +411,8 @@ func TestCreatePodToContainersMap(t *testing.T) {
 			logRequest: LogRequest{
 				Namespace: "test-namespace",
 				PodNameRegexToMessages: map[PodNameRegexPrevious]sets.Set[string]{
-					{"foo-.*", false}: sets.Set[string](sets.NewString("foo-general")),
-					{"foo-1", false}:  sets.Set[string](sets.NewString(".*hello world.*", ".*bye.*")),
+					{PodNameRegex: "foo-.*"}: sets.Set[string](sets.NewString("foo-general")),
+					{PodNameRegex: "foo-1"}:  sets.Set[string](sets.NewString(".*hello world.*", ".*bye.*")),
 				},
 			},
 			pods: []*corev1.Pod{
@@ -728,9 +813,106 @@ func TestGatherContainerLogs(t *testing.T) {
 				assert.NoError(t, err)
 			}
 
-			recs, errs := gatherContainerLogs(ctx, cli.CoreV1(), tt.rawLogRequests)
+			recs, errs := gatherContainerLogs(ctx, cli.CoreV1(), tt.rawLogRequests, common.NewSharedLogBudget(containerLogsMaxBytes))
 			assert.ElementsMatch(t, tt.expectedRecords, recs)
 			assert.ElementsMatch(t, tt.expectedErrors, errs)
 		})
 	}
 }
+
+func TestApplyContainerLogsBudget(t *testing.T) {
+	recordsWithErr := []recordWithError{
+		{
+			r:         &record.Record{Name: "namespaces/ns-a/pods/a/a-1/current.log", Item: marshal.RawByte("a1\na2\na3\n")},
+			namespace: "ns-a",
+		},
+		{
+			r:         &record.Record{Name: "namespaces/ns-b/pods/b/b-1/current.log", Item: marshal.RawByte("b1\n")},
+			namespace: "ns-b",
+		},
+		{
+			r:         &record.Record{Name: "namespaces/ns-c/pods/c/c-1/current.log", Item: marshal.RawByte("c1\nc2\n")},
+			namespace: "ns-c",
+		},
+	}
+
+	records, errs := applyContainerLogsBudget(recordsWithErr, common.NewSharedLogBudget(9))
+
+	assert.Equal(t, []record.Record{
+		{Name: "namespaces/ns-a/pods/a/a-1/current.log", Item: marshal.RawByte("a3\n")},
+		{Name: "namespaces/ns-b/pods/b/b-1/current.log", Item: marshal.RawByte("b1\n")},
+		{Name: "namespaces/ns-c/pods/c/c-1/current.log", Item: marshal.RawByte("c2\n")},
+	}, records)
+	assert.Equal(t, []error{
+		&types.Warning{UnderlyingValue: &common.ContainersSkippedError{Namespace: "ns-a", MaxLogBytes: 9, DroppedBytes: 6}},
+		&types.Warning{UnderlyingValue: &common.ContainersSkippedError{Namespace: "ns-c", MaxLogBytes: 9, DroppedBytes: 3}},
+	}, errs)
+}
+
+// podLogsClient serves the container logs from the REST client instead of the fake core client
+// which always returns the "fake logs"
+type podLogsClient struct {
+	corev1client.CoreV1Interface
+	restClient rest.Interface
+}
+
+func (c podLogsClient) Pods(namespace string) corev1client.PodInterface {
+	return podLogs{PodInterface: c.CoreV1Interface.Pods(namespace), restClient: c.restClient, namespace: namespace}
+}
+
+type podLogs struct {
+	corev1client.PodInterface
+	restClient rest.Interface
+	namespace  string
+}
+
+func (p podLogs) GetLogs(name string, opts *corev1.PodLogOptions) *rest.Request {
+	return p.restClient.Get().Namespace(p.namespace).Resource("pods").Name(name).SubResource("log").
+		VersionedParams(opts, scheme.ParameterCodec)
+}
+
+func TestGatherContainerLogs_FieldFilters(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `2024-05-01T10:00:00Z {"level":"error","msg":"request timeout","error":{"kind":"io"}}
+2024-05-01T10:00:01Z {"level":"info","msg":"retrying after timeout"}
+2024-05-01T10:00:02Z level=ERROR msg="dial timeout" component=etcd
+2024-05-01T10:00:03Z plain text error: timeout
+2024-05-01T10:00:04Z {"level":"error","msg":"connection refused"}
+`)
+	}))
+	defer srv.Close()
+	base, err := url.Parse(srv.URL)
+	assert.NoError(t, err)
+	restClient, err := rest.NewRESTClient(base, "", rest.ClientContentConfig{GroupVersion: corev1.SchemeGroupVersion}, nil, nil)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	cli := kubefake.NewClientset()
+	_, err = cli.CoreV1().Pods("test-namespace").Create(ctx, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-foo", Namespace: "test-namespace"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "foo-1"}}},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	recs, errs := gatherContainerLogs(ctx, podLogsClient{CoreV1Interface: cli.CoreV1(), restClient: restClient}, []RawLogRequest{
+		{
+			Namespace:    "test-namespace",
+			PodNameRegex: "test-.*",
+			Messages:     []string{"timeout"},
+			// the structured log lines are neither aggregated nor kept with the context
+			Aggregate:    true,
+			ContextAfter: 1,
+			FieldFilters: []common.LogFieldFilter{{Field: "level", Values: []string{"error"}}},
+		},
+	}, nil)
+	assert.Empty(t, errs)
+	assert.Equal(t, []record.Record{
+		{
+			Name: "namespaces/test-namespace/pods/test-foo/foo-1/current-structured.jsonl",
+			Item: marshal.RawByte(
+				`{"timestamp":"2024-05-01T10:00:00Z","fields":{"error":{"kind":"io"},"level":"error","msg":"request timeout"}}` + "\n" +
+					`{"timestamp":"2024-05-01T10:00:02Z","fields":{"component":"etcd","level":"ERROR","msg":"dial timeout"}}` + "\n"),
+		},
+	}, recs)
+}
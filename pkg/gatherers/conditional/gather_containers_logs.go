@@ -2,6 +2,7 @@ package conditional
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"k8s.io/client-go/kubernetes"
@@ -11,19 +12,25 @@ import (
 	"github.com/openshift/insights-operator/pkg/gatherers"
 	"github.com/openshift/insights-operator/pkg/gatherers/common"
 	"github.com/openshift/insights-operator/pkg/record"
+	"github.com/openshift/insights-operator/pkg/types"
 )
 
 // BuildLegacyGatherContainersLogs Collects either current or previous containers logs for pods firing one of the
 // alerts from the conditions fetched from insights conditions service.
+// The logs share the log budget with the other conditional log gathering functions.
 //
 // ### API Reference
 // None
 //
 // ### Sample data
 // - docs/insights-archive-sample/conditional/namespaces/openshift-cluster-samples-operator/pods/cluster-samples-operator-8ffb9b45f-49mjr/containers/cluster-samples-operator-watch/logs/last-100-lines.log
+// - docs/insights-archive-sample/conditional/namespaces/openshift-cluster-samples-operator/pods/cluster-samples-operator-8ffb9b45f-49mjr/containers/cluster-samples-operator/logs/last-100-lines-aggregated.json
 //
 // ### Location in archive
-// - `conditional/namespaces/{namespace}/pods/{pod}/containers/{container}/{logs|logs-previous}/last-{tail-length}-lines.log`
+// - `conditional/namespaces/{namespace}/pods/{pod}/containers/{container}/logs/last-{tail-length}-lines.log`
+// - `conditional/namespaces/{namespace}/pods/{pod}/containers/{container}/logs/last-{tail-length}-lines-aggregated.json`
+// - `conditional/namespaces/{namespace}/pods/{pod}/containers/{container}/logs-previous/last-{tail-length}-lines.log`
+// - `conditional/namespaces/{namespace}/pods/{pod}/containers/{container}/logs-previous/last-{tail-length}-lines-aggregated.json`
 //
 // ### Config ID
 // `conditional/containers_logs`
@@ -35,7 +42,8 @@ import (
 // None
 //
 // ### Changes
-// None
+// - Optional aggregation of the log lines into the distinct message templates with their counts
+// - The logs are limited by the log budget shared by the conditional log gathering functions
 func (g *Gatherer) BuildLegacyGatherContainersLogs(paramsInterface interface{}) (gatherers.GatheringClosure, error) { // nolint: dupl
 	params, ok := paramsInterface.(GatherContainersLogsParams)
 	if !ok {
@@ -45,6 +53,8 @@ func (g *Gatherer) BuildLegacyGatherContainersLogs(paramsInterface interface{})
 			paramsInterface)
 	}
 
+	logBudget := g.logBudget
+	logBudget.Join()
 	return gatherers.GatheringClosure{
 		Run: func(ctx context.Context) ([]record.Record, []error) {
 			kubeClient, err := kubernetes.NewForConfig(g.gatherProtoKubeConfig)
@@ -52,7 +62,7 @@ func (g *Gatherer) BuildLegacyGatherContainersLogs(paramsInterface interface{})
 				return nil, []error{err}
 			}
 			coreClient := kubeClient.CoreV1()
-			return g.gatherContainersLogs(ctx, params, coreClient)
+			return g.gatherContainersLogs(ctx, params, coreClient, logBudget)
 		},
 	}, nil
 }
@@ -61,6 +71,7 @@ func (g *Gatherer) gatherContainersLogs(
 	ctx context.Context,
 	params GatherContainersLogsParams,
 	coreClient corev1client.CoreV1Interface,
+	logBudget *common.SharedLogBudget,
 ) ([]record.Record, []error) {
 	alertInstances, ok := g.firingAlerts[params.AlertName]
 	if !ok {
@@ -100,6 +111,7 @@ func (g *Gatherer) gatherContainersLogs(
 		logContainersFilter := common.LogContainersFilter{
 			Namespace:     podNamespace,
 			FieldSelector: fmt.Sprintf("metadata.name=%s", podName),
+			LogBudget:     logBudget,
 		}
 
 		// The container label may not be present for all alerts (e.g., KubePodNotReady).
@@ -114,24 +126,33 @@ func (g *Gatherer) gatherContainersLogs(
 			common.LogMessagesFilter{
 				TailLines: params.TailLines,
 				Previous:  params.Previous,
+				Aggregate: params.Aggregate,
 			},
 			func(namespace, podName, containerName string) string {
 				logDirName := "logs"
 				if params.Previous {
 					logDirName = "logs-previous"
 				}
+				fileExt := ".log"
+				if params.Aggregate {
+					fileExt = "-aggregated.json"
+				}
 				return fmt.Sprintf(
-					"%s/namespaces/%s/pods/%s/containers/%s/%s/last-%d-lines.log",
+					"%s/namespaces/%s/pods/%s/containers/%s/%s/last-%d-lines%s",
 					g.GetName(),
 					namespace,
 					podName,
 					containerName,
 					logDirName,
 					params.TailLines,
+					fileExt,
 				)
 			},
 		)
-		if err != nil {
+		var skippedErr *common.ContainersSkippedError
+		if errors.As(err, &skippedErr) {
+			errs = append(errs, &types.Warning{UnderlyingValue: err})
+		} else if err != nil {
 			newErr := fmt.Errorf("unable to get container logs: %v", err)
 			klog.Warning(newErr.Error())
 			errs = append(errs, newErr)
@@ -69,11 +69,27 @@ func TestGatherer_gatherContainersLogs(t *testing.T) {
 			}},
 			wantErr: nil,
 		},
+		{
+			name: "Can record aggregated previous logs",
+			params: GatherContainersLogsParams{
+				AlertName: "AlertmanagerFailedToSendAlerts",
+				TailLines: 50,
+				Previous:  true,
+				Aggregate: true,
+			},
+			want: []record.Record{{
+				// nolint: lll
+				Name:     "conditional/namespaces/openshift-monitoring/pods/alertmanager-main-0/containers/alertmanager/logs-previous/last-50-lines-aggregated.json",
+				Captured: time.Time{},
+				Item:     marshal.Raw{Str: `[{"template":"fake logs","count":1}]`},
+			}},
+			wantErr: nil,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			g := &Gatherer{firingAlerts: testAlertManagerFiringAlerts}
-			got, gotErr := g.gatherContainersLogs(ctx, tt.params, coreClient)
+			got, gotErr := g.gatherContainersLogs(ctx, tt.params, coreClient, nil)
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("gatherContainersLogs() got = %v, want %v", got, tt.want)
 			}
@@ -0,0 +1,91 @@
+package conditional
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/openshift/insights-operator/pkg/gatherers"
+	"github.com/openshift/insights-operator/pkg/gatherers/clusterconfig"
+	"github.com/openshift/insights-operator/pkg/record"
+)
+
+// BuildGatherEventsOfNamespace Collects events from the provided namespace. The events can be filtered
+// by their reasons and types and limited to the ones seen in the time window. Only the most recent
+// events up to the maximum count are kept.
+//
+// ### API Reference
+// - https://docs.openshift.com/container-platform/4.16/rest_api/metadata_apis/event-core-v1.html
+//
+// ### Sample data
+// - docs/insights-archive-sample/conditional/namespaces/openshift-monitoring/events.json
+//
+// ### Location in archive
+// - `conditional/namespaces/{namespace}/events.json`
+//
+// ### Config ID
+// `conditional/events_of_namespace`
+//
+// ### Released version
+// - 4.18.0
+//
+// ### Backported versions
+// None
+//
+// ### Changes
+// None
+func (g *Gatherer) BuildGatherEventsOfNamespace(paramsInterface interface{}) (gatherers.GatheringClosure, error) {
+	params, ok := paramsInterface.(GatherEventsOfNamespaceParams)
+	if !ok {
+		return gatherers.GatheringClosure{}, fmt.Errorf(
+			"unexpected type in paramsInterface, expected %T, got %T",
+			GatherEventsOfNamespaceParams{}, paramsInterface,
+		)
+	}
+
+	return gatherers.GatheringClosure{
+		Run: func(ctx context.Context) ([]record.Record, []error) {
+			kubeClient, err := kubernetes.NewForConfig(g.gatherProtoKubeConfig)
+			if err != nil {
+				return nil, []error{err}
+			}
+			records, err := g.gatherEventsOfNamespace(ctx, kubeClient.CoreV1(), params)
+			if err != nil {
+				return records, []error{err}
+			}
+			return records, nil
+		},
+	}, nil
+}
+
+func (g *Gatherer) gatherEventsOfNamespace(
+	ctx context.Context, coreClient corev1client.CoreV1Interface, params GatherEventsOfNamespaceParams,
+) ([]record.Record, error) {
+	events, err := coreClient.Events(params.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	filteredEvents := clusterconfig.FilterEventsByReasonsAndTypes(events, params.Reasons, params.Types)
+	if params.TimeWindowMinutes > 0 {
+		filteredEvents = clusterconfig.GetEventsForInterval(time.Duration(params.TimeWindowMinutes)*time.Minute, &filteredEvents)
+	}
+	if len(filteredEvents.Items) == 0 {
+		return nil, nil
+	}
+
+	// the compacted events are sorted from the oldest, so the most recent ones are at the end
+	compactedEvents := clusterconfig.EventListToCompactedEventList(&filteredEvents)
+	if params.MaxCount > 0 && len(compactedEvents.Items) > params.MaxCount {
+		compactedEvents.Items = compactedEvents.Items[len(compactedEvents.Items)-params.MaxCount:]
+	}
+
+	return []record.Record{{
+		Name: fmt.Sprintf("%v/namespaces/%v/events", g.GetName(), params.Namespace),
+		Item: record.JSONMarshaller{Object: &compactedEvents},
+	}}, nil
+}
@@ -0,0 +1,94 @@
+package conditional
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/insights-operator/pkg/gatherers/clusterconfig"
+	"github.com/openshift/insights-operator/pkg/record"
+)
+
+func TestGatherer_gatherEventsOfNamespace(t *testing.T) {
+	now := time.Now()
+	newEvent := func(name, reason, eventType string, age time.Duration) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:    metav1.ObjectMeta{Name: name, Namespace: "openshift-monitoring"},
+			Reason:        reason,
+			Type:          eventType,
+			Message:       name,
+			LastTimestamp: metav1.NewTime(now.Add(-age)),
+		}
+	}
+	coreClient := kubefake.NewClientset(
+		newEvent("old-backoff", "BackOff", "Warning", 2*time.Hour),
+		newEvent("backoff", "BackOff", "Warning", 10*time.Minute),
+		newEvent("failed", "Failed", "Warning", 5*time.Minute),
+		newEvent("pulled", "Pulled", "Normal", time.Minute),
+	).CoreV1()
+
+	tests := []struct {
+		name         string
+		params       GatherEventsOfNamespaceParams
+		wantMessages []string
+	}{
+		{
+			name:         "all events",
+			params:       GatherEventsOfNamespaceParams{Namespace: "openshift-monitoring", MaxCount: 10},
+			wantMessages: []string{"old-backoff", "backoff", "failed", "pulled"},
+		},
+		{
+			name: "events filtered by reasons in the time window",
+			params: GatherEventsOfNamespaceParams{
+				Namespace:         "openshift-monitoring",
+				Reasons:           []string{"BackOff", "Pulled"},
+				TimeWindowMinutes: 60,
+				MaxCount:          10,
+			},
+			wantMessages: []string{"backoff", "pulled"},
+		},
+		{
+			name: "most recent warning events",
+			params: GatherEventsOfNamespaceParams{
+				Namespace: "openshift-monitoring",
+				Types:     []string{"Warning"},
+				MaxCount:  2,
+			},
+			wantMessages: []string{"backoff", "failed"},
+		},
+		{
+			name: "no events match",
+			params: GatherEventsOfNamespaceParams{
+				Namespace: "openshift-monitoring",
+				Reasons:   []string{"Killing"},
+				MaxCount:  10,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Gatherer{}
+			records, err := g.gatherEventsOfNamespace(context.Background(), coreClient, tt.params)
+			assert.NoError(t, err)
+			if tt.wantMessages == nil {
+				assert.Empty(t, records)
+				return
+			}
+
+			assert.Len(t, records, 1)
+			assert.Equal(t, "conditional/namespaces/openshift-monitoring/events", records[0].Name)
+			events, ok := records[0].Item.(record.JSONMarshaller).Object.(*clusterconfig.CompactedEventList)
+			assert.True(t, ok)
+			var messages []string
+			for _, event := range events.Items {
+				messages = append(messages, event.Message)
+			}
+			assert.Equal(t, tt.wantMessages, messages)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package conditional
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"k8s.io/client-go/kubernetes"
@@ -9,9 +10,11 @@ import (
 	"github.com/openshift/insights-operator/pkg/gatherers"
 	"github.com/openshift/insights-operator/pkg/gatherers/common"
 	"github.com/openshift/insights-operator/pkg/record"
+	"github.com/openshift/insights-operator/pkg/types"
 )
 
 // BuildGatherLogsOfNamespace Collects logs from pods in the provided namespace.
+// The logs share the log budget with the other conditional log gathering functions.
 //
 // ### API Reference
 // - https://github.com/kubernetes/client-go/blob/master/kubernetes/typed/core/v1/pod_expansion.go#L48
@@ -19,9 +22,13 @@ import (
 //
 // ### Sample data
 // - docs/insights-archive-sample/conditional/namespaces/openshift-cluster-samples-operator/pods/cluster-samples-operator-8ffb9b45f-49mjr/containers/cluster-samples-operator/logs/last-100-lines.log
+// - docs/insights-archive-sample/conditional/namespaces/openshift-cluster-samples-operator/pods/cluster-samples-operator-8ffb9b45f-49mjr/containers/cluster-samples-operator/logs/last-100-lines-aggregated.json
+// - docs/insights-archive-sample/conditional/namespaces/openshift-cluster-samples-operator/pods/cluster-samples-operator-8ffb9b45f-49mjr/containers/cluster-samples-operator/logs/last-100-lines-structured.jsonl
 //
 // ### Location in archive
 // - `conditional/namespaces/{namespace}/pods/{pod_name}/containers/{container_name}/logs/last-{n}-lines.log`
+// - `conditional/namespaces/{namespace}/pods/{pod_name}/containers/{container_name}/logs/last-{n}-lines-aggregated.json`
+// - `conditional/namespaces/{namespace}/pods/{pod_name}/containers/{container_name}/logs/last-{n}-lines-structured.jsonl`
 //
 // ### Config ID
 // `conditional/logs_of_namespace`
@@ -33,7 +40,9 @@ import (
 // None
 //
 // ### Changes
-// None
+// - Optional aggregation of the log lines into the distinct message templates with their counts
+// - Optional filtering of the structured (JSON or logfmt) log lines by the values of their fields
+// - The logs are limited by the log budget shared by the conditional log gathering functions
 func (g *Gatherer) BuildGatherLogsOfNamespace(paramsInterface interface{}) (gatherers.GatheringClosure, error) {
 	params, ok := paramsInterface.(GatherLogsOfNamespaceParams)
 	if !ok {
@@ -43,9 +52,11 @@ func (g *Gatherer) BuildGatherLogsOfNamespace(paramsInterface interface{}) (gath
 		)
 	}
 
+	logBudget := g.logBudget
+	logBudget.Join()
 	return gatherers.GatheringClosure{
 		Run: func(ctx context.Context) ([]record.Record, []error) {
-			records, err := g.gatherLogsOfNamespace(ctx, params.Namespace, params.TailLines)
+			records, err := g.gatherLogsOfNamespace(ctx, params, logBudget)
 			if err != nil {
 				return records, []error{err}
 			}
@@ -54,7 +65,9 @@ func (g *Gatherer) BuildGatherLogsOfNamespace(paramsInterface interface{}) (gath
 	}, nil
 }
 
-func (g *Gatherer) gatherLogsOfNamespace(ctx context.Context, namespace string, tailLines int64) ([]record.Record, error) {
+func (g *Gatherer) gatherLogsOfNamespace(
+	ctx context.Context, params GatherLogsOfNamespaceParams, logBudget *common.SharedLogBudget,
+) ([]record.Record, error) {
 	kubeClient, err := kubernetes.NewForConfig(g.gatherProtoKubeConfig)
 	if err != nil {
 		return nil, err
@@ -62,17 +75,26 @@ func (g *Gatherer) gatherLogsOfNamespace(ctx context.Context, namespace string,
 
 	coreClient := kubeClient.CoreV1()
 
-	fileName := fmt.Sprintf("last-%v-lines.log", tailLines)
+	fileName := fmt.Sprintf("last-%v-lines.log", params.TailLines)
+	switch {
+	case len(params.FieldFilters) > 0:
+		fileName = fmt.Sprintf("last-%v-lines-structured.jsonl", params.TailLines)
+	case params.Aggregate:
+		fileName = fmt.Sprintf("last-%v-lines-aggregated.json", params.TailLines)
+	}
 
 	records, err := common.CollectLogsFromContainers(
 		ctx,
 		coreClient,
 		common.LogContainersFilter{
-			Namespace:              namespace,
+			Namespace:              params.Namespace,
 			MaxNamespaceContainers: 64, // arbitrary fixed value
+			LogBudget:              logBudget,
 		},
 		common.LogMessagesFilter{
-			TailLines: tailLines,
+			TailLines:    params.TailLines,
+			Aggregate:    params.Aggregate,
+			FieldFilters: params.FieldFilters,
 		},
 		func(namespace string, podName string, containerName string) string {
 			return fmt.Sprintf(
@@ -81,6 +103,11 @@ func (g *Gatherer) gatherLogsOfNamespace(ctx context.Context, namespace string,
 			)
 		},
 	)
+	var skippedErr *common.ContainersSkippedError
+	if errors.As(err, &skippedErr) {
+		// the skipped containers and the dropped log bytes don't invalidate the gathered logs
+		return records, &types.Warning{UnderlyingValue: err}
+	}
 	if err != nil {
 		return nil, err
 	}
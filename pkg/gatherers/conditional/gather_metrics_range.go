@@ -0,0 +1,157 @@
+package conditional
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"k8s.io/client-go/rest"
+
+	"github.com/openshift/insights-operator/pkg/gatherers"
+	"github.com/openshift/insights-operator/pkg/record"
+)
+
+// metricsRangeMaxPoints is the maximum number of the points of a single series in the range query
+const metricsRangeMaxPoints = 1000
+
+// MetricsRange is the result of the PromQL range query
+type MetricsRange struct {
+	Query string    `json:"query"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Step  string    `json:"step"`
+	// Series are the returned series up to the maximum count
+	Series []MetricSeries `json:"series"`
+	// DroppedSeries is the number of the returned series over the maximum count. The query is limited
+	// on the server side already, so the series are dropped only when the limit wasn't applied there.
+	DroppedSeries int `json:"dropped_series"`
+}
+
+// MetricSeries is a single series of the range query result
+type MetricSeries struct {
+	Metric map[string]string `json:"metric"`
+	// Values are the [unix timestamp, value] pairs
+	Values [][]interface{} `json:"values"`
+}
+
+// BuildGatherMetricsRange Collects the result of the bounded PromQL range query ending at the time of gathering.
+// The number of the points in a series (window / step) is limited to 1000. The number of the series is limited
+// on the Prometheus side, the query is wrapped in `topk(max_series, ...)` and the `limit` parameter is set,
+// so only the series with the highest values are returned. The returned series over the maximum count are dropped.
+//
+// ### API Reference
+// - https://prometheus.io/docs/prometheus/latest/querying/api/#range-queries
+//
+// ### Sample data
+// - docs/insights-archive-sample/conditional/metrics/etcd_leader_changes.json
+//
+// ### Location in archive
+// - `conditional/metrics/{name}.json`
+//
+// ### Config ID
+// `conditional/metrics_range`
+//
+// ### Released version
+// - 4.18.0
+//
+// ### Backported versions
+// None
+//
+// ### Changes
+// None
+func (g *Gatherer) BuildGatherMetricsRange(paramsInterface interface{}) (gatherers.GatheringClosure, error) {
+	params, ok := paramsInterface.(GatherMetricsRangeParams)
+	if !ok {
+		return gatherers.GatheringClosure{}, fmt.Errorf(
+			"unexpected type in paramsInterface, expected %T, got %T",
+			GatherMetricsRangeParams{}, paramsInterface,
+		)
+	}
+
+	if params.StepSeconds <= 0 || params.WindowMinutes <= 0 {
+		return gatherers.GatheringClosure{}, fmt.Errorf("the window and the step of the range query must be positive")
+	}
+	if points := params.WindowMinutes * 60 / params.StepSeconds; points > metricsRangeMaxPoints {
+		return gatherers.GatheringClosure{}, fmt.Errorf(
+			"the range query has %d points per series, the maximum is %d", points, metricsRangeMaxPoints,
+		)
+	}
+
+	return gatherers.GatheringClosure{
+		Run: func(ctx context.Context) ([]record.Record, []error) {
+			if g.metricsGatherKubeConfig == nil {
+				return nil, []error{fmt.Errorf("metrics client config is missing")}
+			}
+			metricsClient, err := rest.RESTClientFor(g.metricsGatherKubeConfig)
+			if err != nil {
+				return nil, []error{err}
+			}
+			records, err := g.gatherMetricsRange(ctx, metricsClient, params, time.Now())
+			if err != nil {
+				return records, []error{err}
+			}
+			return records, nil
+		},
+	}, nil
+}
+
+func (g *Gatherer) gatherMetricsRange(
+	ctx context.Context, metricsClient rest.Interface, params GatherMetricsRangeParams, end time.Time,
+) ([]record.Record, error) {
+	end = end.UTC().Truncate(time.Second)
+	start := end.Add(-time.Duration(params.WindowMinutes) * time.Minute)
+	step := (time.Duration(params.StepSeconds) * time.Second).String()
+	// topk bounds the series of every step and the limit parameter, supported by the newer Prometheus versions,
+	// bounds the total number of the series, so that the high cardinality query isn't downloaded as a whole
+	query := fmt.Sprintf("topk(%d, %s)", params.MaxSeries, params.Query)
+
+	data, err := metricsClient.Get().
+		AbsPath("api/v1/query_range").
+		Param("query", query).
+		Param("start", strconv.FormatInt(start.Unix(), 10)).
+		Param("end", strconv.FormatInt(end.Unix(), 10)).
+		Param("step", step).
+		Param("limit", strconv.Itoa(params.MaxSeries)).
+		Param("timeout", metricQueryTimeout).
+		DoRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Data struct {
+			ResultType string         `json:"resultType"`
+			Result     []MetricSeries `json:"result"`
+		} `json:"data"`
+	}
+	err = json.Unmarshal(data, &response)
+	if err != nil {
+		return nil, err
+	}
+	if response.Data.ResultType != "matrix" {
+		return nil, fmt.Errorf("unsupported result type %q, only matrix is supported", response.Data.ResultType)
+	}
+
+	metricsRange := MetricsRange{
+		Query:  query,
+		Start:  start,
+		End:    end,
+		Step:   step,
+		Series: response.Data.Result,
+	}
+	if metricsRange.Series == nil {
+		metricsRange.Series = []MetricSeries{}
+	}
+	// topk can return different series for every step, so the count is checked also here
+	if len(metricsRange.Series) > params.MaxSeries {
+		metricsRange.DroppedSeries = len(metricsRange.Series) - params.MaxSeries
+		metricsRange.Series = metricsRange.Series[:params.MaxSeries]
+	}
+
+	return []record.Record{{
+		Name: fmt.Sprintf("%v/metrics/%v", g.GetName(), params.Name),
+		Item: record.JSONMarshaller{Object: &metricsRange},
+	}}, nil
+}
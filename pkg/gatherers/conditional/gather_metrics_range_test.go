@@ -0,0 +1,59 @@
+package conditional
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/insights-operator/pkg/record"
+)
+
+func TestGatherer_gatherMetricsRange(t *testing.T) {
+	metricsClient := newFakeClientWithMetrics(map[string]string{
+		"topk(2, etcd_server_leader_changes_seen_total)": `{"status": "success", "data": {"resultType": "matrix", "result": [
+			{"metric": {"pod": "etcd-master-0"}, "values": [[1718180400, "1"], [1718180460, "2"]]},
+			{"metric": {"pod": "etcd-master-1"}, "values": [[1718180400, "0"], [1718180460, "0"]]},
+			{"metric": {"pod": "etcd-master-2"}, "values": [[1718180400, "3"], [1718180460, "3"]]}
+		]}}`,
+		"topk(1, up)": `{"status": "success", "data": {"resultType": "vector", "result": []}}`,
+	})
+	end := time.Date(2024, 6, 12, 8, 30, 0, 0, time.UTC)
+	g := &Gatherer{}
+
+	records, err := g.gatherMetricsRange(context.Background(), metricsClient, GatherMetricsRangeParams{
+		Name:          "etcd_leader_changes",
+		Query:         "etcd_server_leader_changes_seen_total",
+		WindowMinutes: 60,
+		StepSeconds:   60,
+		MaxSeries:     2,
+	}, end)
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "conditional/metrics/etcd_leader_changes", records[0].Name)
+	assert.Equal(t, &MetricsRange{
+		Query: "topk(2, etcd_server_leader_changes_seen_total)",
+		Start: end.Add(-time.Hour),
+		End:   end,
+		Step:  "1m0s",
+		Series: []MetricSeries{
+			{Metric: map[string]string{"pod": "etcd-master-0"}, Values: [][]interface{}{{1718180400.0, "1"}, {1718180460.0, "2"}}},
+			{Metric: map[string]string{"pod": "etcd-master-1"}, Values: [][]interface{}{{1718180400.0, "0"}, {1718180460.0, "0"}}},
+		},
+		DroppedSeries: 1,
+	}, records[0].Item.(record.JSONMarshaller).Object)
+
+	_, err = g.gatherMetricsRange(context.Background(), metricsClient, GatherMetricsRangeParams{
+		Name: "up", Query: "up", WindowMinutes: 60, StepSeconds: 60, MaxSeries: 1,
+	}, end)
+	assert.EqualError(t, err, `unsupported result type "vector", only matrix is supported`)
+}
+
+func TestGatherer_BuildGatherMetricsRange_TooManyPoints(t *testing.T) {
+	g := &Gatherer{}
+	_, err := g.BuildGatherMetricsRange(GatherMetricsRangeParams{
+		Name: "up", Query: "up", WindowMinutes: 1440, StepSeconds: 15, MaxSeries: 1,
+	})
+	assert.EqualError(t, err, "the range query has 5760 points per series, the maximum is 1000")
+}
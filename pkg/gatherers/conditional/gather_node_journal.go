@@ -0,0 +1,123 @@
+package conditional
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/insights-operator/pkg/gatherers"
+	"github.com/openshift/insights-operator/pkg/gatherers/clusterconfig"
+	"github.com/openshift/insights-operator/pkg/gatherers/common"
+	"github.com/openshift/insights-operator/pkg/record"
+	"github.com/openshift/insights-operator/pkg/utils/marshal"
+)
+
+const (
+	// nodeJournalMaxNodes is the maximum number of nodes the journal is collected from
+	nodeJournalMaxNodes = 16
+	// nodeJournalMaxTailLines is the number of the journal lines fetched when the lines are filtered by the patterns
+	nodeJournalMaxTailLines = 5000
+	// nodeRoleLabelPrefix is the prefix of the node role labels
+	nodeRoleLabelPrefix = "node-role.kubernetes.io/"
+)
+
+// BuildGatherNodeJournal Collects the journal of the systemd unit from the nodes with the provided role
+// using the node proxy logs API. The journal lines can be limited to the ones matching any of the regular
+// expressions and to the ones logged in the last minutes. Only the last lines up to the maximum count are kept
+// and at most 16 nodes are checked.
+//
+// ### API Reference
+// - https://docs.openshift.com/container-platform/4.16/rest_api/node_apis/node-core-v1.html#apiv1nodesnameproxypath
+//
+// ### Sample data
+// - docs/insights-archive-sample/conditional/nodes/master-0/journal/crio.log
+//
+// ### Location in archive
+// - `conditional/nodes/{node}/journal/{unit}.log`
+//
+// ### Config ID
+// `conditional/node_journal`
+//
+// ### Released version
+// - 4.18.0
+//
+// ### Backported versions
+// None
+//
+// ### Changes
+// None
+func (g *Gatherer) BuildGatherNodeJournal(paramsInterface interface{}) (gatherers.GatheringClosure, error) {
+	params, ok := paramsInterface.(GatherNodeJournalParams)
+	if !ok {
+		return gatherers.GatheringClosure{}, fmt.Errorf(
+			"unexpected type in paramsInterface, expected %T, got %T",
+			GatherNodeJournalParams{}, paramsInterface,
+		)
+	}
+
+	for _, pattern := range params.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return gatherers.GatheringClosure{}, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+	}
+
+	return gatherers.GatheringClosure{
+		Run: func(ctx context.Context) ([]record.Record, []error) {
+			kubeClient, err := kubernetes.NewForConfig(g.gatherProtoKubeConfig)
+			if err != nil {
+				return nil, []error{err}
+			}
+			return g.gatherNodeJournal(ctx, kubeClient.CoreV1(), params)
+		},
+	}, nil
+}
+
+func (g *Gatherer) gatherNodeJournal(
+	ctx context.Context, coreClient corev1client.CoreV1Interface, params GatherNodeJournalParams,
+) ([]record.Record, []error) {
+	nodes, err := coreClient.Nodes().List(ctx, metav1.ListOptions{LabelSelector: nodeRoleLabelPrefix + params.NodeRole})
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	items := nodes.Items
+	if len(items) > nodeJournalMaxNodes {
+		klog.Infof("the journal is collected only from %d of %d nodes", nodeJournalMaxNodes, len(items))
+		items = items[:nodeJournalMaxNodes]
+	}
+
+	tail := params.MaxLines
+	if len(params.Patterns) > 0 {
+		tail = nodeJournalMaxTailLines
+	}
+
+	restClient := coreClient.RESTClient()
+	var records []record.Record
+	var errs []error
+	for i := range items {
+		name := items[i].Name
+		uri := clusterconfig.NodeLogResourceURI(restClient, name)
+		req := clusterconfig.RequestNodeLog(restClient, uri, tail, params.Unit)
+		if params.SinceMinutes > 0 {
+			req = req.Param("since", fmt.Sprintf("-%dm", params.SinceMinutes))
+		}
+
+		journal, err := clusterconfig.ReadNodeLog(ctx, req, params.Patterns, common.LogLinesContext{}, params.MaxLines)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to get the %s journal of the node %s: %v", params.Unit, name, err))
+			continue
+		}
+
+		records = append(records, record.Record{
+			Name: fmt.Sprintf("%v/nodes/%v/journal/%v.log", g.GetName(), name, params.Unit),
+			Item: marshal.Raw{Str: journal},
+		})
+	}
+
+	return records, errs
+}
@@ -0,0 +1,82 @@
+package conditional
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/openshift/insights-operator/pkg/utils/marshal"
+)
+
+// coreClientWithREST overrides the REST client of the fake core client which doesn't support the node proxy
+type coreClientWithREST struct {
+	corev1client.CoreV1Interface
+	restClient rest.Interface
+}
+
+func (c coreClientWithREST) RESTClient() rest.Interface {
+	return c.restClient
+}
+
+func TestGatherer_gatherNodeJournal(t *testing.T) {
+	var queries []url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query())
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "%s starting\n%s error: connection refused\n%s stopping\n", r.URL.Path, r.URL.Path, r.URL.Path)
+	}))
+	defer srv.Close()
+	base, err := url.Parse(srv.URL)
+	assert.NoError(t, err)
+	restClient, err := rest.NewRESTClient(base, "", rest.ClientContentConfig{}, nil, nil)
+	assert.NoError(t, err)
+
+	newNode := func(name, role string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{nodeRoleLabelPrefix + role: ""}}}
+	}
+	coreClient := coreClientWithREST{
+		CoreV1Interface: kubefake.NewClientset(newNode("master-0", "master"), newNode("worker-0", "worker")).CoreV1(),
+		restClient:      restClient,
+	}
+	g := &Gatherer{}
+
+	records, errs := g.gatherNodeJournal(context.Background(), coreClient, GatherNodeJournalParams{
+		NodeRole:     "master",
+		Unit:         "crio",
+		Patterns:     []string{"error: .*"},
+		SinceMinutes: 30,
+		MaxLines:     10,
+	})
+	assert.Empty(t, errs)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "conditional/nodes/master-0/journal/crio.log", records[0].Name)
+	assert.Equal(t, marshal.Raw{Str: "/nodes/master-0/proxy/logs/journal error: connection refused"}, records[0].Item)
+	assert.Equal(t, url.Values{"unit": {"crio"}, "tail": {"5000"}, "since": {"-30m"}}, queries[0])
+
+	records, errs = g.gatherNodeJournal(context.Background(), coreClient, GatherNodeJournalParams{
+		NodeRole: "worker",
+		Unit:     "NetworkManager",
+		MaxLines: 2,
+	})
+	assert.Empty(t, errs)
+	assert.Len(t, records, 1)
+	assert.Equal(t, marshal.Raw{Str: "/nodes/worker-0/proxy/logs/journal error: connection refused\n" +
+		"/nodes/worker-0/proxy/logs/journal stopping"}, records[0].Item)
+	assert.Equal(t, url.Values{"unit": {"NetworkManager"}, "tail": {"2"}}, queries[1])
+}
+
+func TestGatherer_BuildGatherNodeJournal_InvalidPattern(t *testing.T) {
+	g := &Gatherer{}
+	_, err := g.BuildGatherNodeJournal(GatherNodeJournalParams{NodeRole: "master", Unit: "crio", Patterns: []string{"("}, MaxLines: 1})
+	assert.ErrorContains(t, err, `invalid pattern "("`)
+}
@@ -0,0 +1,215 @@
+package conditional
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/openshift/insights-operator/pkg/gatherers"
+	"github.com/openshift/insights-operator/pkg/record"
+	"github.com/openshift/insights-operator/pkg/utils/anonymize"
+)
+
+const (
+	// coreGroupName is used in the archive path instead of the empty core API group
+	coreGroupName = "core"
+	// openShiftNamespacePrefix is the prefix of the namespaces whose resources can be collected
+	openShiftNamespacePrefix = "openshift-"
+)
+
+// deniedResources are the resources carrying credentials, they are never collected
+var deniedResources = map[schema.GroupResource]bool{
+	{Resource: "secrets"}: true,
+	{Group: "oauth.openshift.io", Resource: "oauthaccesstokens"}:     true,
+	{Group: "oauth.openshift.io", Resource: "oauthauthorizetokens"}:  true,
+	{Group: "oauth.openshift.io", Resource: "useroauthaccesstokens"}: true,
+	{Group: "oauth.openshift.io", Resource: "oauthclients"}:          true,
+	{Group: "authentication.k8s.io", Resource: "tokenreviews"}:       true,
+}
+
+// BuildGatherResourcesOfKind Collects the resources of the provided group, version and resource
+// optionally limited to the namespace and the label selector. Only the cluster-scoped resources
+// and the resources from the `openshift-*` namespaces are collected. Only the identifying metadata
+// (name, namespace and creation timestamp) and the allowed fields are kept, the string values
+// of the anonymized fields are anonymized. The resources carrying credentials (secrets, OAuth tokens
+// and clients, token reviews) and the subresources (e.g. `serviceaccounts/token`) are never collected.
+//
+// The fields are the dot separated paths to the nested fields of the resource (e.g. `spec.replicas`),
+// the lists can be kept only as a whole (e.g. `status.conditions`).
+//
+// ### API Reference
+// - https://github.com/kubernetes/client-go/blob/master/dynamic/interface.go
+//
+// ### Sample data
+// - docs/insights-archive-sample/conditional/namespaces/openshift-logging/loki.grafana.com/lokistacks/lokistack-sample.json
+//
+// ### Location in archive
+// - `conditional/namespaces/{namespace}/{group}/{resource}/{name}.json`
+// - `conditional/cluster-scoped-resources/{group}/{resource}/{name}.json`
+//
+// ### Config ID
+// `conditional/resources_of_kind`
+//
+// ### Released version
+// - 4.18.0
+//
+// ### Backported versions
+// None
+//
+// ### Changes
+// - The OAuth tokens and clients and the resources outside of the `openshift-*` namespaces are not collected.
+func (g *Gatherer) BuildGatherResourcesOfKind(paramsInterface interface{}) (gatherers.GatheringClosure, error) {
+	params, ok := paramsInterface.(GatherResourcesOfKindParams)
+	if !ok {
+		return gatherers.GatheringClosure{}, fmt.Errorf(
+			"unexpected type in paramsInterface, expected %T, got %T",
+			GatherResourcesOfKindParams{}, paramsInterface,
+		)
+	}
+
+	return gatherers.GatheringClosure{
+		Run: func(ctx context.Context) ([]record.Record, []error) {
+			dynamicClient, err := dynamic.NewForConfig(g.gatherKubeConfig)
+			if err != nil {
+				return nil, []error{err}
+			}
+			return g.gatherResourcesOfKind(ctx, dynamicClient, params)
+		},
+	}, nil
+}
+
+func (g *Gatherer) gatherResourcesOfKind(
+	ctx context.Context, dynamicClient dynamic.Interface, params GatherResourcesOfKindParams,
+) ([]record.Record, []error) {
+	gvr := schema.GroupVersionResource{Group: params.Group, Version: params.Version, Resource: params.Resource}
+	if deniedResources[gvr.GroupResource()] || strings.Contains(params.Resource, "/") {
+		return nil, []error{fmt.Errorf("gathering of the %s is not allowed", gvr.GroupResource())}
+	}
+	if params.Namespace != "" && !strings.HasPrefix(params.Namespace, openShiftNamespacePrefix) {
+		return nil, []error{fmt.Errorf("gathering of the resources from the %s namespace is not allowed", params.Namespace)}
+	}
+
+	items, err := listOpenShiftResources(ctx, dynamicClient.Resource(gvr).Namespace(params.Namespace), params)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	group := params.Group
+	if group == "" {
+		group = coreGroupName
+	}
+
+	var records []record.Record
+	var errs []error
+	for i := range items {
+		item := &items[i]
+		filtered, err := filterResourceFields(item, params.Fields, params.AnonymizedFields)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to filter fields of %s %s: %v", params.Resource, item.GetName(), err))
+			continue
+		}
+
+		name := fmt.Sprintf("%v/cluster-scoped-resources/%v/%v/%v", g.GetName(), group, params.Resource, item.GetName())
+		if item.GetNamespace() != "" {
+			name = fmt.Sprintf("%v/namespaces/%v/%v/%v/%v",
+				g.GetName(), item.GetNamespace(), group, params.Resource, item.GetName())
+		}
+		records = append(records, record.Record{
+			Name: name,
+			Item: record.JSONMarshaller{Object: filtered},
+		})
+	}
+
+	return records, errs
+}
+
+// listOpenShiftResources lists the cluster-scoped resources and the resources from the openshift-* namespaces
+// page by page until the max count of them is reached. The resources from the other namespaces are skipped.
+func listOpenShiftResources(
+	ctx context.Context, client dynamic.ResourceInterface, params GatherResourcesOfKindParams,
+) ([]unstructured.Unstructured, error) {
+	opts := metav1.ListOptions{
+		LabelSelector: params.LabelSelector,
+		Limit:         int64(params.MaxCount),
+	}
+	var items []unstructured.Unstructured
+	for {
+		list, err := client.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			namespace := list.Items[i].GetNamespace()
+			if namespace != "" && !strings.HasPrefix(namespace, openShiftNamespacePrefix) {
+				continue
+			}
+			items = append(items, list.Items[i])
+			if params.MaxCount > 0 && len(items) == params.MaxCount {
+				return items, nil
+			}
+		}
+		if list.GetContinue() == "" {
+			return items, nil
+		}
+		opts.Continue = list.GetContinue()
+	}
+}
+
+// filterResourceFields returns a copy of the resource with the identifying metadata and the allowed fields only.
+// The string values of the anonymized fields are anonymized, the other values are removed.
+func filterResourceFields(item *unstructured.Unstructured, fields, anonymizedFields []string) (map[string]interface{}, error) {
+	filtered := map[string]interface{}{
+		"apiVersion": item.GetAPIVersion(),
+		"kind":       item.GetKind(),
+	}
+	metadata := map[string]interface{}{
+		"name": item.GetName(),
+	}
+	if creationTimestamp, found, _ := unstructured.NestedString(item.Object, "metadata", "creationTimestamp"); found {
+		metadata["creationTimestamp"] = creationTimestamp
+	}
+	if item.GetNamespace() != "" {
+		metadata["namespace"] = item.GetNamespace()
+	}
+	filtered["metadata"] = metadata
+
+	for _, field := range fields {
+		path := strings.Split(field, ".")
+		value, found, err := unstructured.NestedFieldCopy(item.Object, path...)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		if err := unstructured.SetNestedField(filtered, value, path...); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, field := range anonymizedFields {
+		path := strings.Split(field, ".")
+		value, found, err := unstructured.NestedFieldNoCopy(filtered, path...)
+		if err != nil || !found {
+			continue
+		}
+		if _, isString := value.(string); !isString {
+			unstructured.RemoveNestedField(filtered, path...)
+			continue
+		}
+		if err := anonymize.UnstructuredNestedStringField(filtered, path...); err != nil {
+			return nil, err
+		}
+	}
+
+	return filtered, nil
+}
@@ -0,0 +1,146 @@
+package conditional
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/openshift/insights-operator/pkg/record"
+)
+
+func TestGatherer_gatherResourcesOfKind(t *testing.T) {
+	lokistacksGVR := schema.GroupVersionResource{Group: "loki.grafana.com", Version: "v1", Resource: "lokistacks"}
+	nodesGVR := schema.GroupVersionResource{Version: "v1", Resource: "nodes"}
+	newLokiStack := func(namespace, name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "loki.grafana.com/v1",
+			"kind":       "LokiStack",
+			"metadata": map[string]interface{}{
+				"name":              name,
+				"namespace":         namespace,
+				"creationTimestamp": "2024-06-12T08:00:00Z",
+				"annotations":       map[string]interface{}{"secret": "value"},
+			},
+			"spec": map[string]interface{}{
+				"size": "1x.small",
+				"storage": map[string]interface{}{
+					"secret": map[string]interface{}{"name": "logging-loki-s3", "type": "s3"},
+				},
+			},
+			"status": map[string]interface{}{
+				"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+			},
+		}}
+	}
+	node := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Node",
+		"metadata":   map[string]interface{}{"name": "master-0"},
+		"spec":       map[string]interface{}{"unschedulable": true},
+	}}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		lokistacksGVR: "LokiStackList",
+		nodesGVR:      "NodeList",
+	}, newLokiStack("openshift-logging", "logging-loki"), newLokiStack("openshift-logging", "other-loki"),
+		newLokiStack("customer", "customer-loki"), node)
+	g := &Gatherer{}
+
+	records, errs := g.gatherResourcesOfKind(context.Background(), dynamicClient, GatherResourcesOfKindParams{
+		Group:            "loki.grafana.com",
+		Version:          "v1",
+		Resource:         "lokistacks",
+		Namespace:        "openshift-logging",
+		Fields:           []string{"spec.size", "spec.storage.secret", "status.conditions", "spec.unknown"},
+		AnonymizedFields: []string{"spec.storage.secret.name"},
+		MaxCount:         1,
+	})
+	assert.Empty(t, errs)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "conditional/namespaces/openshift-logging/loki.grafana.com/lokistacks/logging-loki", records[0].Name)
+	assert.Equal(t, map[string]interface{}{
+		"apiVersion": "loki.grafana.com/v1",
+		"kind":       "LokiStack",
+		"metadata": map[string]interface{}{
+			"name":              "logging-loki",
+			"namespace":         "openshift-logging",
+			"creationTimestamp": "2024-06-12T08:00:00Z",
+		},
+		"spec": map[string]interface{}{
+			"size": "1x.small",
+			"storage": map[string]interface{}{
+				"secret": map[string]interface{}{"name": "xxxxxxxxxxxxxxx", "type": "s3"},
+			},
+		},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+		},
+	}, records[0].Item.(record.JSONMarshaller).Object)
+
+	records, errs = g.gatherResourcesOfKind(context.Background(), dynamicClient, GatherResourcesOfKindParams{
+		Version:  "v1",
+		Resource: "nodes",
+		Fields:   []string{"spec.unschedulable"},
+		MaxCount: 10,
+	})
+	assert.Empty(t, errs)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "conditional/cluster-scoped-resources/core/nodes/master-0", records[0].Name)
+
+	// the resources from the namespaces other than openshift-* are skipped
+	records, errs = g.gatherResourcesOfKind(context.Background(), dynamicClient, GatherResourcesOfKindParams{
+		Group:    "loki.grafana.com",
+		Version:  "v1",
+		Resource: "lokistacks",
+		Fields:   []string{"spec.size"},
+		MaxCount: 10,
+	})
+	assert.Empty(t, errs)
+	var names []string
+	for _, r := range records {
+		names = append(names, r.Name)
+	}
+	assert.ElementsMatch(t, []string{
+		"conditional/namespaces/openshift-logging/loki.grafana.com/lokistacks/logging-loki",
+		"conditional/namespaces/openshift-logging/loki.grafana.com/lokistacks/other-loki",
+	}, names)
+
+	_, errs = g.gatherResourcesOfKind(context.Background(), dynamicClient, GatherResourcesOfKindParams{
+		Group:     "loki.grafana.com",
+		Version:   "v1",
+		Resource:  "lokistacks",
+		Namespace: "customer",
+		Fields:    []string{"spec.size"},
+		MaxCount:  10,
+	})
+	assert.EqualError(t, errs[0], "gathering of the resources from the customer namespace is not allowed")
+
+	_, errs = g.gatherResourcesOfKind(context.Background(), dynamicClient, GatherResourcesOfKindParams{
+		Version:  "v1",
+		Resource: "secrets",
+		Fields:   []string{"data"},
+		MaxCount: 10,
+	})
+	assert.EqualError(t, errs[0], "gathering of the secrets is not allowed")
+
+	_, errs = g.gatherResourcesOfKind(context.Background(), dynamicClient, GatherResourcesOfKindParams{
+		Group:    "oauth.openshift.io",
+		Version:  "v1",
+		Resource: "oauthaccesstokens",
+		Fields:   []string{"metadata.name"},
+		MaxCount: 10,
+	})
+	assert.EqualError(t, errs[0], "gathering of the oauthaccesstokens.oauth.openshift.io is not allowed")
+
+	_, errs = g.gatherResourcesOfKind(context.Background(), dynamicClient, GatherResourcesOfKindParams{
+		Version:  "v1",
+		Resource: "serviceaccounts/token",
+		Fields:   []string{"status.token"},
+		MaxCount: 10,
+	})
+	assert.EqualError(t, errs[0], "gathering of the serviceaccounts/token is not allowed")
+}
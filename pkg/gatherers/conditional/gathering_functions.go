@@ -3,6 +3,8 @@ package conditional
 import (
 	"encoding/json"
 	"fmt"
+
+	"github.com/openshift/insights-operator/pkg/gatherers/common"
 )
 
 // To add a new gathering function, follow the next steps:
@@ -42,6 +44,22 @@ const (
 	// GatherPodDefinition is a function that collects the pod definitions
 	// See file gather_pod_definition.go
 	GatherPodDefinition GatheringFunctionName = "pod_definition"
+
+	// GatherEventsOfNamespace is a function collecting events of the provided namespace.
+	// See file gather_events_of_namespace.go
+	GatherEventsOfNamespace GatheringFunctionName = "events_of_namespace"
+
+	// GatherResourcesOfKind is a function collecting the allowed fields of the resources of the provided kind.
+	// See file gather_resources_of_kind.go
+	GatherResourcesOfKind GatheringFunctionName = "resources_of_kind"
+
+	// GatherNodeJournal is a function collecting the journal of the systemd unit from the nodes.
+	// See file gather_node_journal.go
+	GatherNodeJournal GatheringFunctionName = "node_journal"
+
+	// GatherMetricsRange is a function collecting the result of the PromQL range query.
+	// See file gather_metrics_range.go
+	GatherMetricsRange GatheringFunctionName = "metrics_range"
 )
 
 func (name GatheringFunctionName) NewParams(jsonParams []byte) (interface{}, error) {
@@ -66,6 +84,22 @@ func (name GatheringFunctionName) NewParams(jsonParams []byte) (interface{}, err
 		var params GatherPodDefinitionParams
 		err := json.Unmarshal(jsonParams, &params)
 		return params, err
+	case GatherEventsOfNamespace:
+		var params GatherEventsOfNamespaceParams
+		err := json.Unmarshal(jsonParams, &params)
+		return params, err
+	case GatherResourcesOfKind:
+		var params GatherResourcesOfKindParams
+		err := json.Unmarshal(jsonParams, &params)
+		return params, err
+	case GatherNodeJournal:
+		var params GatherNodeJournalParams
+		err := json.Unmarshal(jsonParams, &params)
+		return params, err
+	case GatherMetricsRange:
+		var params GatherMetricsRangeParams
+		err := json.Unmarshal(jsonParams, &params)
+		return params, err
 	}
 	return nil, fmt.Errorf("unable to create params for %T: %v", name, name)
 }
@@ -78,6 +112,11 @@ type GatherLogsOfNamespaceParams struct {
 	Namespace string `json:"namespace"`
 	// A number of log lines to keep for each container
 	TailLines int64 `json:"tail_lines"`
+	// Aggregate stores the distinct message templates with their counts instead of the log lines
+	Aggregate bool `json:"aggregate,omitempty"`
+	// FieldFilters keeps only the structured (JSON or logfmt) log lines matching all the filters
+	// and stores them as the JSON records, the aggregation is ignored when they are set
+	FieldFilters []common.LogFieldFilter `json:"field_filters,omitempty"`
 }
 
 // GatherImageStreamsOfNamespaceParams defines parameters for image streams of namespace gatherer
@@ -98,6 +137,8 @@ type GatherContainersLogsParams struct {
 	Container string `json:"container,omitempty"`
 	TailLines int64  `json:"tail_lines"`
 	Previous  bool   `json:"previous,omitempty"`
+	// Aggregate stores the distinct message templates with their counts instead of the log lines
+	Aggregate bool `json:"aggregate,omitempty"`
 }
 
 // GatherPodDefinitionParams defines parameters for pod_definition gatherer
@@ -105,6 +146,64 @@ type GatherPodDefinitionParams struct {
 	AlertName string `json:"alert_name"`
 }
 
+// GatherEventsOfNamespaceParams defines parameters for events_of_namespace gatherer
+type GatherEventsOfNamespaceParams struct {
+	// Namespace from which to collect events
+	Namespace string `json:"namespace"`
+	// Reasons of the events to collect, all the reasons if empty
+	Reasons []string `json:"reasons,omitempty"`
+	// Types of the events to collect (Normal or Warning), all the types if empty
+	Types []string `json:"types,omitempty"`
+	// TimeWindowMinutes limits the events to the ones seen in the last minutes, all the events if zero
+	TimeWindowMinutes int64 `json:"time_window_minutes,omitempty"`
+	// MaxCount is the maximum number of the most recent events to keep
+	MaxCount int `json:"max_count"`
+}
+
+// GatherResourcesOfKindParams defines parameters for resources_of_kind gatherer
+type GatherResourcesOfKindParams struct {
+	Group    string `json:"group"`
+	Version  string `json:"version"`
+	Resource string `json:"resource"`
+	// Namespace of the resources, all the openshift-* namespaces if empty
+	Namespace     string `json:"namespace,omitempty"`
+	LabelSelector string `json:"label_selector,omitempty"`
+	// Fields are the dot separated paths of the fields to keep, e.g. spec.replicas
+	Fields []string `json:"fields"`
+	// AnonymizedFields are the dot separated paths of the kept string fields to anonymize
+	AnonymizedFields []string `json:"anonymized_fields,omitempty"`
+	// MaxCount is the maximum number of the resources to collect
+	MaxCount int `json:"max_count"`
+}
+
+// GatherNodeJournalParams defines parameters for node_journal gatherer
+type GatherNodeJournalParams struct {
+	// NodeRole selects the nodes by the node-role.kubernetes.io/{role} label, e.g. master or worker
+	NodeRole string `json:"node_role"`
+	// Unit is the systemd unit, e.g. crio or NetworkManager
+	Unit string `json:"unit"`
+	// Patterns are the regular expressions of the journal lines to keep, all the lines if empty
+	Patterns []string `json:"patterns,omitempty"`
+	// SinceMinutes limits the journal to the lines logged in the last minutes, no limit if zero
+	SinceMinutes int64 `json:"since_minutes,omitempty"`
+	// MaxLines is the maximum number of the last journal lines to keep for each node
+	MaxLines int `json:"max_lines"`
+}
+
+// GatherMetricsRangeParams defines parameters for metrics_range gatherer
+type GatherMetricsRangeParams struct {
+	// Name identifies the query in the archive path
+	Name string `json:"name"`
+	// Query is a PromQL query
+	Query string `json:"query"`
+	// WindowMinutes is the length of the queried time range ending at the time of gathering
+	WindowMinutes int64 `json:"window_minutes"`
+	// StepSeconds is the resolution of the range query
+	StepSeconds int64 `json:"step_seconds"`
+	// MaxSeries is the maximum number of the series to keep
+	MaxSeries int `json:"max_series"`
+}
+
 // registered builders:
 
 // gatheringFunctionBuilders lists all the gatherers which can be run on some condition. Gatherers can have parameters,
@@ -115,4 +214,8 @@ var gatheringFunctionBuilders = map[GatheringFunctionName]GathererFunctionBuilde
 	GatherAPIRequestCounts:        (*Gatherer).BuildGatherAPIRequestCounts,
 	GatherContainersLogs:          (*Gatherer).BuildLegacyGatherContainersLogs,
 	GatherPodDefinition:           (*Gatherer).BuildGatherPodDefinition,
+	GatherEventsOfNamespace:       (*Gatherer).BuildGatherEventsOfNamespace,
+	GatherResourcesOfKind:         (*Gatherer).BuildGatherResourcesOfKind,
+	GatherNodeJournal:             (*Gatherer).BuildGatherNodeJournal,
+	GatherMetricsRange:            (*Gatherer).BuildGatherMetricsRange,
 }
@@ -0,0 +1,98 @@
+package conditional
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// lastKnownGoodConfigFileName is the name of the file on the storage path with the last known good remote configuration
+	lastKnownGoodConfigFileName = "remote-configuration-last-known-good.json"
+	// lastKnownGoodConfigMaxAge is the maximum age of the last known good remote configuration to be used
+	lastKnownGoodConfigMaxAge = 24 * time.Hour
+)
+
+// LastKnownGoodConfig is the last valid remote configuration stored on the storage path.
+// The data are stored as they were received, including the signature, so that the signature can be verified again.
+type LastKnownGoodConfig struct {
+	Version   string    `json:"version"`
+	FetchedAt time.Time `json:"fetched_at"`
+	Data      string    `json:"data"`
+}
+
+// lastKnownGoodConfigPath returns the path of the last known good remote configuration file
+func (g *Gatherer) lastKnownGoodConfigPath() (string, error) {
+	if g.configurator == nil {
+		return "", fmt.Errorf("no configurator was provided")
+	}
+	config := g.configurator.Config()
+	if config == nil || config.DataReporting.StoragePath == "" {
+		return "", fmt.Errorf("the storage path is not configured")
+	}
+	return filepath.Join(config.DataReporting.StoragePath, lastKnownGoodConfigFileName), nil
+}
+
+// storeLastKnownGoodConfig stores the valid remote configuration together with its version
+// and the current time on the storage path
+func (g *Gatherer) storeLastKnownGoodConfig(version string, data []byte) error {
+	path, err := g.lastKnownGoodConfigPath()
+	if err != nil {
+		return err
+	}
+
+	content, err := json.Marshal(LastKnownGoodConfig{
+		Version:   version,
+		FetchedAt: time.Now().UTC(),
+		Data:      string(data),
+	})
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first so that the previous configuration is never left half-written
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, content, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// loadLastKnownGoodConfig reads the last known good remote configuration from the storage path,
+// checks its age (if checkMaxAge is set) and validates it again, because the validation rules, the public keys
+// or the pinned version could have changed
+func (g *Gatherer) loadLastKnownGoodConfig(checkMaxAge bool) (*LastKnownGoodConfig, RemoteConfiguration, error) {
+	path, err := g.lastKnownGoodConfigPath()
+	if err != nil {
+		return nil, RemoteConfiguration{}, err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, RemoteConfiguration{}, err
+	}
+
+	var lastKnownGood LastKnownGoodConfig
+	if err := json.Unmarshal(content, &lastKnownGood); err != nil {
+		return nil, RemoteConfiguration{}, err
+	}
+
+	if age := time.Since(lastKnownGood.FetchedAt); checkMaxAge && age > lastKnownGoodConfigMaxAge {
+		return nil, RemoteConfiguration{}, fmt.Errorf(
+			"the configuration fetched at %s is older than %s", lastKnownGood.FetchedAt.Format(time.RFC3339), lastKnownGoodConfigMaxAge,
+		)
+	}
+
+	remoteConfig, _, err := g.validateRemoteConfiguration([]byte(lastKnownGood.Data))
+	if err != nil {
+		return nil, RemoteConfiguration{}, err
+	}
+
+	if err := g.checkRemoteConfigVersion(remoteConfig.Version); err != nil {
+		return nil, RemoteConfiguration{}, err
+	}
+
+	return &lastKnownGood, remoteConfig, nil
+}
@@ -0,0 +1,84 @@
+package conditional
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/insights-operator/pkg/config"
+	"github.com/openshift/insights-operator/pkg/gatherers"
+)
+
+func newGathererWithStoragePath(client *MockGatheringRulesServiceClient, storagePath string) *Gatherer {
+	mockConfigurator := config.NewMockConfigMapConfigurator(&config.InsightsConfiguration{
+		DataReporting: config.DataReporting{
+			ConditionalGathererEndpoint: "/gathering_rules",
+			StoragePath:                 storagePath,
+			RemoteConfigPublicKeys:      testPublicKeys,
+		},
+	})
+	return New(nil, nil, nil, mockConfigurator, client)
+}
+
+func Test_Gatherer_GetGatheringFunctions_LastKnownGood(t *testing.T) {
+	t.Setenv("RELEASE_VERSION", "1.2.3")
+	storagePath := t.TempDir()
+
+	gatherer := newGathererWithStoragePath(&MockGatheringRulesServiceClient{}, storagePath)
+	_, err := gatherer.GetGatheringFunctions(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, gatherers.RemoteConfigSourceRemote, gatherer.RemoteConfigStatus().Source)
+
+	content, err := os.ReadFile(filepath.Join(storagePath, lastKnownGoodConfigFileName))
+	assert.NoError(t, err)
+	var lastKnownGood LastKnownGoodConfig
+	assert.NoError(t, json.Unmarshal(content, &lastKnownGood))
+	assert.Equal(t, "1.0.0", lastKnownGood.Version)
+	assert.Equal(t, testRemoteConfig, lastKnownGood.Data)
+	assert.WithinDuration(t, time.Now(), lastKnownGood.FetchedAt, time.Minute)
+
+	tests := []struct {
+		name   string
+		client *MockGatheringRulesServiceClient
+	}{
+		{name: "remote configuration is not available", client: &MockGatheringRulesServiceClient{err: fmt.Errorf("connection refused")}},
+		{name: "remote configuration is not valid", client: &MockGatheringRulesServiceClient{value: testRemoteConfigInvalid}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gatherer := newGathererWithStoragePath(tt.client, storagePath)
+			gatheringClosures, err := gatherer.GetGatheringFunctions(context.Background())
+			assert.NoError(t, err)
+			assert.Contains(t, gatheringClosures, "remote_configuration")
+			status := gatherer.RemoteConfigStatus()
+			assert.Equal(t, gatherers.RemoteConfigSourceLastKnownGood, status.Source)
+			assert.Equal(t, testRemoteConfig, string(status.ConfigData))
+		})
+	}
+}
+
+func Test_Gatherer_GetGatheringFunctions_LastKnownGoodIsTooOld(t *testing.T) {
+	t.Setenv("RELEASE_VERSION", "1.2.3")
+	storagePath := t.TempDir()
+	content, err := json.Marshal(LastKnownGoodConfig{
+		Version:   "1.0.0",
+		FetchedAt: time.Now().Add(-lastKnownGoodConfigMaxAge - time.Minute),
+		Data:      testRemoteConfig,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(storagePath, lastKnownGoodConfigFileName), content, 0o600))
+
+	gatherer := newGathererWithStoragePath(&MockGatheringRulesServiceClient{err: fmt.Errorf("connection refused")}, storagePath)
+	_, err = gatherer.GetGatheringFunctions(context.Background())
+	assert.NoError(t, err)
+	status := gatherer.RemoteConfigStatus()
+	assert.Equal(t, gatherers.RemoteConfigSourceBuiltIn, status.Source)
+	assert.Equal(t, defaultRemoteConfiguration, string(status.ConfigData))
+	assert.Equal(t, "connection refused (configuration source: BuiltIn)", status.ErrorMessage())
+}
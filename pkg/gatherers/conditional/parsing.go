@@ -19,6 +19,11 @@ func parseRemoteConfiguration(data []byte) (RemoteConfiguration, error) {
 
 	var result []GatheringRule
 	for _, unmarshalledRule := range remoteConfig.ConditionalGatheringRules {
+		if err = validateConditionGroups(unmarshalledRule.Conditions, 0); err != nil {
+			klog.Errorf("skipping a rule because of an error: %v %v", err, unmarshalledRule)
+			continue
+		}
+
 		unmarshalledRule.GatheringFunctions, err = parseGatheringFunctions(unmarshalledRule.GatheringFunctions)
 		if err != nil {
 			klog.Errorf("skipping a rule because of an error: %v %v", err, unmarshalledRule)
@@ -114,3 +114,61 @@ func Test_ParseConditionalGathererConfig(t *testing.T) { //nolint:funlen
 	errs = validateGatheringRules(config.ConditionalGatheringRules)
 	assert.Empty(t, errs)
 }
+
+func Test_ParseConditionalGathererConfig_ConditionGroups(t *testing.T) {
+	config, err := parseRemoteConfiguration([]byte(`{
+		"version": "1.0.0",
+		"conditional_gathering_rules": [
+			{
+				"conditions": [
+					{
+						"type": "any",
+						"conditions": [
+							{ "type": "alert_is_firing", "alert": { "name": "AlertA" } },
+							{ "type": "alert_is_firing", "alert": { "name": "AlertB" } }
+						]
+					},
+					{
+						"type": "not",
+						"condition": { "type": "cluster_version_matches", "cluster_version_matches": { "version": "4.12.x" } }
+					}
+				],
+				"gathering_functions": {
+					"image_streams_of_namespace": { "namespace": "openshift-something" }
+				}
+			},
+			{
+				"conditions": [
+					{ "type": "not", "condition": { "type": "not", "condition": { "type": "not", "condition":
+						{ "type": "not", "condition": { "type": "not", "condition":
+							{ "type": "alert_is_firing", "alert": { "name": "AlertA" } } } } } } }
+				],
+				"gathering_functions": {
+					"image_streams_of_namespace": { "namespace": "openshift-something" }
+				}
+			}
+		]
+	}`))
+	assert.NoError(t, err)
+
+	// the rule with too deep nesting is skipped
+	rules := config.ConditionalGatheringRules
+	assert.Len(t, rules, 1)
+	assert.Equal(t, []ConditionWithParams{
+		{
+			Type: Any,
+			Conditions: []ConditionWithParams{
+				{Type: AlertIsFiring, Alert: &AlertConditionParams{Name: "AlertA"}},
+				{Type: AlertIsFiring, Alert: &AlertConditionParams{Name: "AlertB"}},
+			},
+		},
+		{
+			Type: Not,
+			Condition: &ConditionWithParams{
+				Type:                  ClusterVersionMatches,
+				ClusterVersionMatches: &ClusterVersionMatchesConditionParams{Version: "4.12.x"},
+			},
+		},
+	}, rules[0].Conditions)
+	assert.Empty(t, validateGatheringRules(rules))
+}
@@ -0,0 +1,112 @@
+package conditional
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/blang/semver/v4"
+	configv1client "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// remoteConfigVersionConstraints returns the pinned and the minimum version of the remote configuration
+// set in the support secret or in the insights-config configmap
+func (g *Gatherer) remoteConfigVersionConstraints() (pinned, minimum string) {
+	if g.configurator == nil {
+		return "", ""
+	}
+	config := g.configurator.Config()
+	if config == nil {
+		return "", ""
+	}
+	return config.DataReporting.RemoteConfigVersion, config.DataReporting.RemoteConfigMinVersion
+}
+
+// checkRemoteConfigVersion returns an error when the version of the remote configuration is not the pinned version
+// or when it is lower than the minimum version. There is nothing to check when none of the versions is configured.
+func (g *Gatherer) checkRemoteConfigVersion(version string) error {
+	pinned, minimum := g.remoteConfigVersionConstraints()
+	if pinned == "" && minimum == "" {
+		return nil
+	}
+
+	v, err := semver.Parse(version)
+	if err != nil {
+		return fmt.Errorf("unable to parse the remote configuration version %q: %v", version, err)
+	}
+
+	if pinned != "" {
+		pinnedVersion, err := semver.Parse(pinned)
+		if err != nil {
+			return fmt.Errorf("unable to parse the pinned remote configuration version %q: %v", pinned, err)
+		}
+		if !v.Equals(pinnedVersion) {
+			return fmt.Errorf("the remote configuration version %s does not match the pinned version %s", version, pinned)
+		}
+	}
+
+	if minimum != "" {
+		minVersion, err := semver.Parse(minimum)
+		if err != nil {
+			return fmt.Errorf("unable to parse the minimum remote configuration version %q: %v", minimum, err)
+		}
+		if v.LT(minVersion) {
+			return fmt.Errorf("the remote configuration version %s is lower than the minimum version %s", version, minimum)
+		}
+	}
+
+	return nil
+}
+
+// isInRollout checks whether the cluster is in the staged rollout of the remote configuration version.
+// The cluster is always in the rollout when the rollout percentage is not set or when the version is pinned.
+// The cluster is not in the rollout when its ID cannot be determined.
+func (g *Gatherer) isInRollout(ctx context.Context, remoteConfig *RemoteConfiguration) (bool, error) {
+	percentage := remoteConfig.RolloutPercentage
+	if percentage == nil || *percentage >= 100 {
+		return true, nil
+	}
+	if pinned, _ := g.remoteConfigVersionConstraints(); pinned != "" {
+		return true, nil
+	}
+
+	clusterID, err := g.getClusterID(ctx)
+	if err != nil {
+		return false, err
+	}
+	return rolloutBucket(clusterID, remoteConfig.Version) < *percentage, nil
+}
+
+// rolloutBucket assigns the cluster to one of the 100 buckets based on the hash of its ID. The version is part
+// of the hash so that the same clusters are not always the first ones to get the new versions.
+func rolloutBucket(clusterID, version string) int {
+	sum := sha256.Sum256([]byte(clusterID + "/" + version))
+	return int(binary.BigEndian.Uint64(sum[:8]) % 100)
+}
+
+// getClusterID returns the ID of the cluster, it is read from the cluster version only once
+func (g *Gatherer) getClusterID(ctx context.Context) (string, error) {
+	if g.clusterID != "" {
+		return g.clusterID, nil
+	}
+	if g.gatherKubeConfig == nil {
+		return "", fmt.Errorf("unable to get the cluster ID: no kube config was provided")
+	}
+
+	configClient, err := configv1client.NewForConfig(g.gatherKubeConfig)
+	if err != nil {
+		return "", err
+	}
+	clusterVersion, err := configClient.ClusterVersions().Get(ctx, "version", metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	g.clusterID = string(clusterVersion.Spec.ClusterID)
+	if g.clusterID == "" {
+		return "", fmt.Errorf("the cluster ID is empty")
+	}
+	return g.clusterID, nil
+}
@@ -0,0 +1,240 @@
+package conditional
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/insights-operator/pkg/config"
+	"github.com/openshift/insights-operator/pkg/gatherers"
+)
+
+// testRemoteConfigNewVersion is the next version of testRemoteConfig rolled out to none of the clusters
+var testRemoteConfigNewVersion = signTestRemoteConfig(strings.NewReplacer(
+	`"version": "1.0.0"`, `"version": "1.1.0"`,
+	`"container_logs":[]`, `"container_logs":[], "rollout_percentage": 0`,
+).Replace(testRemoteConfigUnsigned))
+
+func newGathererWithVersionConstraints(client *MockGatheringRulesServiceClient, storagePath, pinned, minimum string) *Gatherer {
+	mockConfigurator := config.NewMockConfigMapConfigurator(&config.InsightsConfiguration{
+		DataReporting: config.DataReporting{
+			ConditionalGathererEndpoint: "/gathering_rules",
+			StoragePath:                 storagePath,
+			RemoteConfigVersion:         pinned,
+			RemoteConfigMinVersion:      minimum,
+			RemoteConfigPublicKeys:      testPublicKeys,
+		},
+	})
+	return New(nil, nil, nil, mockConfigurator, client)
+}
+
+func writeLastKnownGoodConfig(t *testing.T, storagePath, version, data string, fetchedAt time.Time) {
+	content, err := json.Marshal(LastKnownGoodConfig{Version: version, FetchedAt: fetchedAt, Data: data})
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(storagePath, lastKnownGoodConfigFileName), content, 0o600))
+}
+
+func Test_rolloutBucket(t *testing.T) {
+	assert.Equal(t, rolloutBucket("cluster-id", "1.0.0"), rolloutBucket("cluster-id", "1.0.0"))
+
+	inFirstHalf := 0
+	for i := 0; i < 1000; i++ {
+		bucket := rolloutBucket(fmt.Sprintf("cluster-%d", i), "1.0.0")
+		assert.GreaterOrEqual(t, bucket, 0)
+		assert.Less(t, bucket, 100)
+		if bucket < 50 {
+			inFirstHalf++
+		}
+	}
+	assert.InDelta(t, 500, inFirstHalf, 100)
+}
+
+func Test_Gatherer_checkRemoteConfigVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		pinned  string
+		minimum string
+		wantErr string
+	}{
+		{name: "no constraints", version: "not a version"},
+		{name: "pinned version matches", version: "1.2.0", pinned: "1.2.0"},
+		{
+			name:    "pinned version does not match",
+			version: "1.3.0",
+			pinned:  "1.2.0",
+			wantErr: "the remote configuration version 1.3.0 does not match the pinned version 1.2.0",
+		},
+		{name: "version is equal to the minimum version", version: "1.2.0", minimum: "1.2.0"},
+		{name: "version is higher than the minimum version", version: "1.10.0", minimum: "1.2.0"},
+		{
+			name:    "version is lower than the minimum version",
+			version: "1.1.9",
+			minimum: "1.2.0",
+			wantErr: "the remote configuration version 1.1.9 is lower than the minimum version 1.2.0",
+		},
+		{
+			name:    "pinned version cannot be parsed",
+			version: "1.2.0",
+			pinned:  "latest",
+			wantErr: `unable to parse the pinned remote configuration version "latest": No Major.Minor.Patch elements found`,
+		},
+		{
+			name:    "version cannot be parsed",
+			version: "",
+			minimum: "1.2.0",
+			wantErr: `unable to parse the remote configuration version "": Version string empty`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newGathererWithVersionConstraints(&MockGatheringRulesServiceClient{}, "", tt.pinned, tt.minimum)
+			err := g.checkRemoteConfigVersion(tt.version)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_Gatherer_isInRollout(t *testing.T) {
+	percentage := func(p int) *int { return &p }
+	tests := []struct {
+		name       string
+		percentage *int
+		pinned     string
+		clusterID  string
+		want       bool
+		wantErr    string
+	}{
+		{name: "rollout percentage is not set", want: true},
+		{name: "version is rolled out to all the clusters", percentage: percentage(100), want: true},
+		{name: "version is rolled out to none of the clusters", percentage: percentage(0), clusterID: "cluster-id", want: false},
+		{name: "version is pinned", percentage: percentage(0), pinned: "1.0.0", want: true},
+		{
+			name:       "cluster ID is not known",
+			percentage: percentage(50),
+			want:       false,
+			wantErr:    "unable to get the cluster ID: no kube config was provided",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newGathererWithVersionConstraints(&MockGatheringRulesServiceClient{}, "", tt.pinned, "")
+			g.clusterID = tt.clusterID
+			got, err := g.isInRollout(context.Background(), &RemoteConfiguration{Version: "1.0.0", RolloutPercentage: tt.percentage})
+			assert.Equal(t, tt.want, got)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_Gatherer_GetGatheringFunctions_Rollout(t *testing.T) {
+	t.Setenv("RELEASE_VERSION", "1.2.3")
+	storagePath := t.TempDir()
+	fetchedAt := time.Now().Add(-lastKnownGoodConfigMaxAge + time.Hour).UTC()
+	writeLastKnownGoodConfig(t, storagePath, "1.0.0", testRemoteConfig, fetchedAt)
+
+	// the cluster is not in the rollout of the new version
+	gatherer := newGathererWithStoragePath(&MockGatheringRulesServiceClient{value: testRemoteConfigNewVersion}, storagePath)
+	gatherer.clusterID = "cluster-id"
+	_, err := gatherer.GetGatheringFunctions(context.Background())
+	assert.NoError(t, err)
+	status := gatherer.RemoteConfigStatus()
+	assert.True(t, status.ConfigValid)
+	assert.Equal(t, gatherers.RemoteConfigSourceLastKnownGood, status.Source)
+	assert.Equal(t, testRemoteConfig, string(status.ConfigData))
+
+	lastKnownGood, _, err := gatherer.loadLastKnownGoodConfig(true)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.0.0", lastKnownGood.Version)
+	assert.True(t, lastKnownGood.FetchedAt.Equal(fetchedAt), "the fetch time of the last known good configuration should be kept")
+
+	// the last known good configuration doesn't expire while the cluster is only outside of the rollout
+	writeLastKnownGoodConfig(t, storagePath, "1.0.0", testRemoteConfig, time.Now().Add(-lastKnownGoodConfigMaxAge-time.Hour))
+	_, err = gatherer.GetGatheringFunctions(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, gatherers.RemoteConfigSourceLastKnownGood, gatherer.RemoteConfigStatus().Source)
+	assert.Equal(t, testRemoteConfig, string(gatherer.RemoteConfigStatus().ConfigData))
+
+	// the old last known good configuration expires when the remote configuration is not available
+	gatherer = newGathererWithStoragePath(&MockGatheringRulesServiceClient{err: fmt.Errorf("endpoint not reachable")}, storagePath)
+	_, err = gatherer.GetGatheringFunctions(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, gatherers.RemoteConfigSourceBuiltIn, gatherer.RemoteConfigStatus().Source)
+
+	// the old last known good configuration expires when the rollout can't be checked
+	gatherer = newGathererWithStoragePath(&MockGatheringRulesServiceClient{value: testRemoteConfigNewVersion}, storagePath)
+	_, err = gatherer.GetGatheringFunctions(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, gatherers.RemoteConfigSourceBuiltIn, gatherer.RemoteConfigStatus().Source)
+
+	// the pinned version is used regardless of the rollout
+	gatherer = newGathererWithVersionConstraints(
+		&MockGatheringRulesServiceClient{value: testRemoteConfigNewVersion}, storagePath, "1.1.0", "",
+	)
+	_, err = gatherer.GetGatheringFunctions(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, gatherers.RemoteConfigSourceRemote, gatherer.RemoteConfigStatus().Source)
+}
+
+func Test_Gatherer_GetGatheringFunctions_VersionNotAllowed(t *testing.T) {
+	t.Setenv("RELEASE_VERSION", "1.2.3")
+
+	tests := []struct {
+		name          string
+		pinned        string
+		minimum       string
+		lastKnownGood string
+		wantSource    string
+		wantErr       string
+	}{
+		{
+			name:          "pinned version is used from the last known good configuration",
+			pinned:        "1.1.0",
+			lastKnownGood: testRemoteConfigNewVersion,
+			wantSource:    gatherers.RemoteConfigSourceLastKnownGood,
+			wantErr: "the remote configuration version 1.0.0 does not match the pinned version 1.1.0 " +
+				"(configuration source: LastKnownGood)",
+		},
+		{
+			name:          "last known good configuration is lower than the minimum version",
+			minimum:       "1.1.0",
+			lastKnownGood: testRemoteConfig,
+			wantSource:    gatherers.RemoteConfigSourceBuiltIn,
+			wantErr: "the remote configuration version 1.0.0 is lower than the minimum version 1.1.0 " +
+				"(configuration source: BuiltIn)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storagePath := t.TempDir()
+			var lastKnownGood RemoteConfiguration
+			assert.NoError(t, json.Unmarshal([]byte(tt.lastKnownGood), &lastKnownGood))
+			writeLastKnownGoodConfig(t, storagePath, lastKnownGood.Version, tt.lastKnownGood, time.Now())
+
+			gatherer := newGathererWithVersionConstraints(&MockGatheringRulesServiceClient{}, storagePath, tt.pinned, tt.minimum)
+			_, err := gatherer.GetGatheringFunctions(context.Background())
+			assert.NoError(t, err)
+			status := gatherer.RemoteConfigStatus()
+			assert.True(t, status.ConfigAvailable)
+			assert.False(t, status.ConfigValid)
+			assert.Equal(t, VersionNotAllowedReason, status.ValidReason)
+			assert.Equal(t, tt.wantSource, status.Source)
+			assert.Equal(t, tt.wantErr, status.ErrorMessage())
+		})
+	}
+}
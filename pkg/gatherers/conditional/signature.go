@@ -0,0 +1,137 @@
+package conditional
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	_ "embed"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// remoteConfigSignatureField is the top-level field of the remote configuration carrying the base64 encoded
+// detached signature of the rest of the remote configuration
+const remoteConfigSignatureField = "signature"
+
+// builtInPublicKeys are the PEM encoded public keys built into the operator which are used
+// to verify the signature of the remote configuration. The builds fetching the remote configuration
+// from the signing service should provide its keys in this file. While there is no key in this file
+// nor in the "insights-config" configmap, the remote configuration is used without the signature verification.
+//
+//go:embed remote_configuration_public_keys.pem
+var builtInPublicKeys string
+
+// errNoPublicKey is returned when there is no trusted public key to verify the remote configuration with
+var errNoPublicKey = errors.New("the signature of the remote configuration is not verified, no trusted public key is available")
+
+// signedRemoteConfigPayload returns the signed payload of the remote configuration and its signature.
+// The signature covers the remote configuration without the signature field in the canonical form:
+// the top-level fields sorted by their names and all the values in the compact JSON form.
+func signedRemoteConfigPayload(data []byte) (payload []byte, signature string, err error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, "", err
+	}
+	if rawSignature, ok := fields[remoteConfigSignatureField]; ok {
+		if err := json.Unmarshal(rawSignature, &signature); err != nil {
+			return nil, "", fmt.Errorf("the signature of the remote configuration is not a string: %v", err)
+		}
+		delete(fields, remoteConfigSignatureField)
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		encodedName, err := json.Marshal(name)
+		if err != nil {
+			return nil, "", err
+		}
+		buf.Write(encodedName)
+		buf.WriteByte(':')
+		if err := json.Compact(&buf, fields[name]); err != nil {
+			return nil, "", err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), signature, nil
+}
+
+// parsePublicKeys parses all the "PUBLIC KEY" PEM blocks of the provided data.
+// Supported are the ECDSA, Ed25519 and RSA keys.
+func parsePublicKeys(data string) ([]crypto.PublicKey, error) {
+	var keys []crypto.PublicKey
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			return nil, fmt.Errorf("unexpected PEM block type %q", block.Type)
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch key.(type) {
+		case *ecdsa.PublicKey, ed25519.PublicKey, *rsa.PublicKey:
+			keys = append(keys, key)
+		default:
+			return nil, fmt.Errorf("unsupported public key type %T", key)
+		}
+	}
+	if len(keys) == 0 && strings.TrimSpace(data) != "" {
+		return nil, fmt.Errorf("no PEM encoded public key found")
+	}
+	return keys, nil
+}
+
+// verifySignature checks that the base64 encoded signature of the data was created by any of the keys.
+// ECDSA and RSA (PKCS #1 v1.5) signatures are expected over the SHA-256 digest of the data.
+func verifySignature(data []byte, encodedSignature string, keys []crypto.PublicKey) error {
+	if encodedSignature == "" {
+		return fmt.Errorf("the remote configuration is not signed")
+	}
+	signature, err := base64.StdEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return fmt.Errorf("unable to decode the signature of the remote configuration: %v", err)
+	}
+
+	digest := sha256.Sum256(data)
+	for _, key := range keys {
+		switch k := key.(type) {
+		case *ecdsa.PublicKey:
+			if ecdsa.VerifyASN1(k, digest[:], signature) {
+				return nil
+			}
+		case ed25519.PublicKey:
+			if ed25519.Verify(k, data, signature) {
+				return nil
+			}
+		case *rsa.PublicKey:
+			if rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature) == nil {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("the signature of the remote configuration does not match any of the %d public keys", len(keys))
+}
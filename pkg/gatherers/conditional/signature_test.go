@@ -0,0 +1,240 @@
+package conditional
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/insights-operator/pkg/config"
+)
+
+// testSigningKey signs the remote configurations used in the tests, its public key is configured by the test gatherers
+var testSigningKey = ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+
+var testPublicKeys = func() string {
+	der, err := x509.MarshalPKIXPublicKey(testSigningKey.Public())
+	if err != nil {
+		panic(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}()
+
+// signTestRemoteConfig adds the signature created by the testSigningKey to the remote configuration
+func signTestRemoteConfig(data string) string {
+	return addSignature(data, testSigningKey)
+}
+
+func addSignature(data string, key ed25519.PrivateKey) string {
+	payload, _, err := signedRemoteConfigPayload([]byte(data))
+	if err != nil {
+		panic(err)
+	}
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload))
+	return strings.Replace(data, "{", fmt.Sprintf(`{"signature": %q, `, signature), 1)
+}
+
+func encodePublicKey(t *testing.T, key crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	assert.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func Test_parsePublicKeys(t *testing.T) {
+	edPublic, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	ecPrivate, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		data    string
+		keys    int
+		wantErr string
+	}{
+		{name: "no data", data: "", keys: 0},
+		{name: "multiple keys", data: encodePublicKey(t, edPublic) + encodePublicKey(t, &ecPrivate.PublicKey), keys: 2},
+		{name: "not PEM data", data: "not a key", wantErr: "no PEM encoded public key found"},
+		{
+			name:    "private key",
+			data:    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("key")})),
+			wantErr: `unexpected PEM block type "PRIVATE KEY"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := parsePublicKeys(tt.data)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, keys, tt.keys)
+		})
+	}
+}
+
+func Test_verifySignature(t *testing.T) {
+	data := []byte(testRemoteConfig)
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	ecPrivate, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	digest := sha256.Sum256(data)
+	ecSignature, err := ecdsa.SignASN1(rand.Reader, ecPrivate, digest[:])
+	assert.NoError(t, err)
+	edSignature := base64.StdEncoding.EncodeToString(ed25519.Sign(edPrivate, data))
+	keys := []crypto.PublicKey{edPublic, &ecPrivate.PublicKey}
+
+	tests := []struct {
+		name      string
+		data      []byte
+		signature string
+		wantErr   string
+	}{
+		{name: "valid Ed25519 signature", data: data, signature: edSignature},
+		{name: "valid ECDSA signature", data: data, signature: base64.StdEncoding.EncodeToString(ecSignature)},
+		{
+			name:      "modified data",
+			data:      []byte(testRemoteConfigInvalid),
+			signature: edSignature,
+			wantErr:   "the signature of the remote configuration does not match any of the 2 public keys",
+		},
+		{name: "missing signature", data: data, wantErr: "the remote configuration is not signed"},
+		{
+			name:      "malformed signature",
+			data:      data,
+			signature: "not base64!",
+			wantErr:   "unable to decode the signature of the remote configuration: illegal base64 data at input byte 3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifySignature(tt.data, tt.signature, keys)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_signedRemoteConfigPayload(t *testing.T) {
+	payload, signature, err := signedRemoteConfigPayload([]byte(`{
+		"version": "1.0.0",
+		"signature": "c2lnbmF0dXJl",
+		"container_logs": [ {"namespace": "openshift-etcd", "messages": ["<error>"]} ]
+	}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "c2lnbmF0dXJl", signature)
+	assert.Equal(t, `{"container_logs":[{"namespace":"openshift-etcd","messages":["<error>"]}],"version":"1.0.0"}`, string(payload))
+
+	_, signature, err = signedRemoteConfigPayload([]byte(`{"version": "1.0.0"}`))
+	assert.NoError(t, err)
+	assert.Empty(t, signature)
+
+	_, _, err = signedRemoteConfigPayload([]byte(`{"signature": 1}`))
+	assert.EqualError(t, err,
+		"the signature of the remote configuration is not a string: json: cannot unmarshal number into Go value of type string")
+}
+
+func Test_Gatherer_GetGatheringFunctions_Signature(t *testing.T) {
+	t.Setenv("RELEASE_VERSION", "1.2.3")
+	_, otherPrivate, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		remoteData  string
+		publicKeys  string
+		configValid bool
+		validReason string
+		configData  string
+		wantErr     string
+	}{
+		{
+			name:        "remote configuration is signed by the configured key",
+			remoteData:  testRemoteConfig,
+			publicKeys:  testPublicKeys,
+			configValid: true,
+			validReason: SucceededReason,
+			configData:  testRemoteConfig,
+		},
+		{
+			name:        "remote configuration is signed by an unknown key",
+			remoteData:  addSignature(testRemoteConfigUnsigned, otherPrivate),
+			publicKeys:  testPublicKeys,
+			validReason: InvalidSignatureReason,
+			configData:  defaultRemoteConfiguration,
+			wantErr:     "the signature of the remote configuration does not match any of the 1 public keys",
+		},
+		{
+			name:        "remote configuration was modified after it was signed",
+			remoteData:  strings.Replace(testRemoteConfig, `"tail_lines": 100`, `"tail_lines": 1000`, 1),
+			publicKeys:  testPublicKeys,
+			validReason: InvalidSignatureReason,
+			configData:  defaultRemoteConfiguration,
+			wantErr:     "the signature of the remote configuration does not match any of the 1 public keys",
+		},
+		{
+			name:        "remote configuration is not signed",
+			remoteData:  testRemoteConfigUnsigned,
+			publicKeys:  testPublicKeys,
+			validReason: InvalidSignatureReason,
+			configData:  defaultRemoteConfiguration,
+			wantErr:     "the remote configuration is not signed",
+		},
+		{
+			name:        "no public key is available",
+			remoteData:  testRemoteConfig,
+			configValid: true,
+			validReason: NoPublicKeyReason,
+			configData:  testRemoteConfig,
+			wantErr:     "the signature of the remote configuration is not verified, no trusted public key is available",
+		},
+		{
+			name:        "unsigned remote configuration is used when no public key is available",
+			remoteData:  testRemoteConfigUnsigned,
+			configValid: true,
+			validReason: NoPublicKeyReason,
+			configData:  testRemoteConfigUnsigned,
+			wantErr:     "the signature of the remote configuration is not verified, no trusted public key is available",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockConfigurator := config.NewMockConfigMapConfigurator(&config.InsightsConfiguration{
+				DataReporting: config.DataReporting{
+					ConditionalGathererEndpoint: "/gathering_rules",
+					RemoteConfigPublicKeys:      tt.publicKeys,
+				},
+			})
+			gatherer := New(nil, nil, nil, mockConfigurator, &MockGatheringRulesServiceClient{value: tt.remoteData})
+
+			_, err := gatherer.GetGatheringFunctions(context.Background())
+			assert.NoError(t, err)
+			status := gatherer.RemoteConfigStatus()
+			assert.True(t, status.ConfigAvailable)
+			assert.Equal(t, tt.configValid, status.ConfigValid)
+			assert.Equal(t, tt.validReason, status.ValidReason)
+			assert.Equal(t, tt.configData, string(status.ConfigData))
+			if tt.wantErr != "" {
+				assert.EqualError(t, status.Err, tt.wantErr)
+			} else {
+				assert.NoError(t, status.Err)
+			}
+		})
+	}
+}
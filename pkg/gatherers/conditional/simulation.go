@@ -0,0 +1,106 @@
+package conditional
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/openshift/insights-operator/pkg/utils"
+)
+
+// ClusterState is a snapshot of the cluster state used to evaluate the gathering rules offline.
+// It provides the same data as the caches of the conditional gatherer.
+type ClusterState struct {
+	Version             string        `json:"version,omitempty"`
+	Platform            string        `json:"platform,omitempty"`
+	FiringAlerts        []AlertLabels `json:"firing_alerts,omitempty"`
+	EnabledFeatureGates []string      `json:"enabled_feature_gates,omitempty"`
+	DegradedOperators   []string      `json:"degraded_operators,omitempty"`
+	// MetricValues are the values of the metric_matches queries
+	MetricValues map[string][]float64 `json:"metric_values,omitempty"`
+	// ExistingResources are the existence of the resource_exists resources by their
+	// "group/version/resource/namespace/name" keys
+	ExistingResources map[string]bool `json:"existing_resources,omitempty"`
+}
+
+// SimulatedRule is the result of the evaluation of a single gathering rule
+type SimulatedRule struct {
+	GatheringRuleMetadata
+	// GatheringFunctions are the names of the gathering functions run when the rule is triggered
+	GatheringFunctions []string `json:"gathering_functions"`
+}
+
+// SimulationResult is the result of the offline evaluation of the remote configuration
+type SimulationResult struct {
+	Version              string          `json:"version"`
+	Rules                []SimulatedRule `json:"conditional_gathering_rules"`
+	ContainerLogRequests []RawLogRequest `json:"container_logs"`
+}
+
+// Simulate parses and validates the remote configuration and evaluates its gathering rules against
+// the provided cluster state without accessing any cluster. The signature of the configuration is not verified.
+func Simulate(remoteConfigData []byte, state *ClusterState) (*SimulationResult, error) {
+	remoteConfig, err := parseRemoteConfiguration(remoteConfigData)
+	if err != nil {
+		return nil, err
+	}
+	if errs := validateRemoteConfig(remoteConfig); len(errs) > 0 {
+		return nil, fmt.Errorf("the remote configuration is not valid: %v", utils.UniqueErrors(errs))
+	}
+
+	g := newGathererFromClusterState(state)
+	result := &SimulationResult{
+		Version:              remoteConfig.Version,
+		Rules:                []SimulatedRule{},
+		ContainerLogRequests: remoteConfig.ContainerLogRequests,
+	}
+	if result.ContainerLogRequests == nil {
+		result.ContainerLogRequests = []RawLogRequest{}
+	}
+
+	for _, rule := range remoteConfig.ConditionalGatheringRules {
+		ruleMetadata, functions := g.evaluateGatheringRule(rule)
+		simulatedRule := SimulatedRule{
+			GatheringRuleMetadata: ruleMetadata,
+			GatheringFunctions:    []string{},
+		}
+		for name := range functions {
+			simulatedRule.GatheringFunctions = append(simulatedRule.GatheringFunctions, fmt.Sprintf("%s/%s", g.GetName(), name))
+		}
+		sort.Strings(simulatedRule.GatheringFunctions)
+		result.Rules = append(result.Rules, simulatedRule)
+	}
+
+	return result, nil
+}
+
+// newGathererFromClusterState creates the gatherer with the caches filled from the cluster state
+func newGathererFromClusterState(state *ClusterState) *Gatherer {
+	g := &Gatherer{
+		firingAlerts:        make(map[string][]AlertLabels),
+		clusterVersion:      state.Version,
+		metricValues:        state.MetricValues,
+		platform:            state.Platform,
+		enabledFeatureGates: make(map[string]struct{}),
+		degradedOperators:   make(map[string]struct{}),
+		existingResources:   state.ExistingResources,
+	}
+
+	for _, labels := range state.FiringAlerts {
+		alertName := labels["alertname"]
+		g.firingAlerts[alertName] = append(g.firingAlerts[alertName], labels)
+	}
+	for _, featureGate := range state.EnabledFeatureGates {
+		g.enabledFeatureGates[featureGate] = struct{}{}
+	}
+	for _, operator := range state.DegradedOperators {
+		g.degradedOperators[operator] = struct{}{}
+	}
+	if g.metricValues == nil {
+		g.metricValues = make(map[string][]float64)
+	}
+	if g.existingResources == nil {
+		g.existingResources = make(map[string]bool)
+	}
+
+	return g
+}
@@ -0,0 +1,47 @@
+package conditional
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Simulate(t *testing.T) {
+	remoteConfig := `{
+		"version": "1.0.0",
+		"conditional_gathering_rules": [
+			{
+				"conditions": [{"type": "alert_is_firing", "alert": {"name": "SamplesImagestreamImportFailing"}}],
+				"gathering_functions": {
+					"logs_of_namespace": {"namespace": "openshift-cluster-samples-operator", "tail_lines": 100},
+					"image_streams_of_namespace": {"namespace": "openshift-cluster-samples-operator"}
+				}
+			},
+			{
+				"conditions": [{"type": "cluster_version_matches", "cluster_version_matches": {"version": "4.17.x"}}],
+				"gathering_functions": {"image_streams_of_namespace": {"namespace": "openshift-image-registry"}}
+			}
+		],
+		"container_logs": []
+	}`
+
+	result, err := Simulate([]byte(remoteConfig), &ClusterState{
+		FiringAlerts: []AlertLabels{{"alertname": "SamplesImagestreamImportFailing"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "1.0.0", result.Version)
+	assert.Len(t, result.Rules, 2)
+	assert.True(t, result.Rules[0].WasTriggered)
+	assert.Equal(t, []string{
+		"conditional/image_streams_of_namespace/namespace=openshift-cluster-samples-operator",
+		"conditional/logs_of_namespace/namespace=openshift-cluster-samples-operator,tail_lines=100",
+	}, result.Rules[0].GatheringFunctions)
+	assert.False(t, result.Rules[1].WasTriggered)
+	assert.Empty(t, result.Rules[1].GatheringFunctions)
+	assert.Equal(t, []string{"cluster version is missing"}, result.Rules[1].Errors)
+	assert.Empty(t, result.ContainerLogRequests)
+
+	_, err = Simulate([]byte(testRemoteConfigInvalid), &ClusterState{})
+	assert.EqualError(t, err, "the remote configuration is not valid: "+
+		"0.namespace: Does not match pattern '^openshift-[a-zA-Z0-9_.-]{1,128}$|^kube-[a-zA-Z0-9_.-]{1,128}$'")
+}
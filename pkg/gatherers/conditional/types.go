@@ -4,13 +4,17 @@ import (
 	"regexp"
 
 	"github.com/openshift/insights-operator/pkg/gatherers"
+	"github.com/openshift/insights-operator/pkg/gatherers/common"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 const (
-	InvalidReason      = "Invalid"
-	SucceededReason    = "Succeeded"
-	NotAvailableReason = "NotAvailable"
+	InvalidReason           = "Invalid"
+	InvalidSignatureReason  = "InvalidSignature"
+	NoPublicKeyReason       = "NoPublicKey"
+	VersionNotAllowedReason = "VersionNotAllowed"
+	SucceededReason         = "Succeeded"
+	NotAvailableReason      = "NotAvailable"
 )
 
 // RemoteConfiguration is a structure to hold gathering rules with their version
@@ -18,6 +22,11 @@ type RemoteConfiguration struct {
 	Version                   string          `json:"version"`
 	ConditionalGatheringRules []GatheringRule `json:"conditional_gathering_rules"`
 	ContainerLogRequests      []RawLogRequest `json:"container_logs"`
+	// RolloutPercentage is the percentage of the clusters the version is rolled out to,
+	// the version is used by all the clusters when it is not set
+	RolloutPercentage *int `json:"rollout_percentage,omitempty"`
+	// Signature is the base64 encoded detached signature of the rest of the remote configuration
+	Signature string `json:"signature,omitempty"`
 }
 
 // GatheringRule is a rule consisting of conditions and gathering functions to run if all conditions are met,
@@ -70,6 +79,15 @@ type RawLogRequest struct {
 	PodNameRegex string   `json:"pod_name_regex"`
 	Messages     []string `json:"messages"`
 	Previous     bool     `json:"previous,omitempty"`
+	Aggregate    bool     `json:"aggregate,omitempty"`
+	// ContextBefore and ContextAfter are the numbers of the lines kept before and after each matching line
+	ContextBefore int `json:"context_before,omitempty"`
+	ContextAfter  int `json:"context_after,omitempty"`
+	// ContinuationLines keeps the continuation lines (e.g. stack trace frames) of the matching log entries
+	ContinuationLines bool `json:"continuation_lines,omitempty"`
+	// FieldFilters keeps only the structured (JSON or logfmt) log lines matching all the filters,
+	// the matching lines are stored as the JSON records and the aggregation and the context are ignored
+	FieldFilters []common.LogFieldFilter `json:"field_filters,omitempty"`
 }
 
 // LogRequest is a "sanitized" type, because
@@ -83,9 +101,16 @@ type LogRequest struct {
 // PodNameRegexPrevious is a helper struct storing
 // the Pod name regular expression value together with
 // a flag saying whether it is for previous container log or not
+// and a flag saying whether the log should be aggregated
+// and the context lines kept around the matching lines
+// and the field filters of the structured log lines
 type PodNameRegexPrevious struct {
 	PodNameRegex string
 	Previous     bool
+	Aggregate    bool
+	LinesContext common.LogLinesContext
+	// FieldFilters are JSON encoded, because the slice can't be used in the map key
+	FieldFilters string
 }
 
 // ContainerLogRequest is a type representing concrete and unique
@@ -95,5 +120,8 @@ type ContainerLogRequest struct {
 	PodName       string
 	ContainerName string
 	Previous      bool
+	Aggregate     bool
+	LinesContext  common.LogLinesContext
+	FieldFilters  []common.LogFieldFilter
 	MessageRegex  *regexp.Regexp
 }
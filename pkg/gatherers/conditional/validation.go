@@ -45,6 +45,9 @@ func validateRemoteConfig(remoteConfig RemoteConfiguration) []error {
 	errs = append(errs, gatheringRulesErrs...)
 	containerLogErrs := validateContainerLogRequests(remoteConfig.ContainerLogRequests)
 	errs = append(errs, containerLogErrs...)
+	if p := remoteConfig.RolloutPercentage; p != nil && (*p < 0 || *p > 100) {
+		errs = append(errs, fmt.Errorf("rollout_percentage must be between 0 and 100, got %d", *p))
+	}
 	return errs
 }
 
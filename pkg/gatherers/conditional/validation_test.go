@@ -280,6 +280,79 @@ func Test_Validation_InvalidConditions(t *testing.T) {
 				`0.gathering_functions: Invalid type. Expected: object, given: null`,
 			},
 		},
+		{
+			Name: "Metric operator is invalid",
+			Rules: []GatheringRule{{
+				Conditions: []ConditionWithParams{
+					{
+						Type: MetricMatches,
+						MetricMatches: &MetricMatchesConditionParams{
+							Query:    "up",
+							Operator: "=~",
+						},
+					},
+				},
+			}},
+			Errors: []string{
+				`0.conditions.0.metric_matches.operator: 0.conditions.0.metric_matches.operator must be one of the following: "\u003e", "\u003e=", "\u003c", "\u003c=", "==", "!="`,
+				`0.conditions.0: Must validate at least one schema (anyOf)`,
+				`0.gathering_functions: Invalid type. Expected: object, given: null`,
+			},
+		},
+		{
+			Name: "Metric query cannot be empty",
+			Rules: []GatheringRule{{
+				Conditions: []ConditionWithParams{
+					{
+						Type: MetricMatches,
+						MetricMatches: &MetricMatchesConditionParams{
+							Operator:  ">",
+							Threshold: 1,
+						},
+					},
+				},
+			}},
+			Errors: []string{
+				`0.conditions.0.metric_matches.query: String length must be greater than or equal to 1`,
+				`0.conditions.0: Must validate at least one schema (anyOf)`,
+				`0.gathering_functions: Invalid type. Expected: object, given: null`,
+			},
+		},
+		{
+			Name: "Resource version is invalid",
+			Rules: []GatheringRule{{
+				Conditions: []ConditionWithParams{
+					{
+						Type: ResourceExists,
+						Resource: &ResourceExistsConditionParams{
+							Group:    "loki.grafana.com",
+							Version:  "1",
+							Resource: "lokistacks",
+						},
+					},
+				},
+			}},
+			Errors: []string{
+				`0.conditions.0.resource.version: Does not match pattern '^v[0-9]+((alpha|beta)[0-9]+)?$'`,
+				`0.conditions.0: Must validate at least one schema (anyOf)`,
+				`0.gathering_functions: Invalid type. Expected: object, given: null`,
+			},
+		},
+		{
+			Name: "Cluster operator name is missing",
+			Rules: []GatheringRule{{
+				Conditions: []ConditionWithParams{
+					{
+						Type: ClusterOperatorDegraded,
+					},
+				},
+			}},
+			Errors: []string{
+				`0.conditions.0: Must validate at least one schema (anyOf)`,
+				`0.conditions.0: cluster_operator is required`,
+				`0.gathering_functions: Invalid type. Expected: object, given: null`,
+			},
+		},
 		{
 			Name: "Alert name is invalid",
 			Rules: []GatheringRule{{
@@ -540,6 +613,143 @@ func Test_Validation_InvalidGatheringFunctions(t *testing.T) { //nolint:funlen
 				`0.gathering_functions.image_streams_of_namespace.namespace: Does not match pattern '^openshift-[a-zA-Z0-9_.-]{1,128}$'`,
 			},
 		},
+		{
+			Name: "GatherEventsOfNamespace invalid params",
+			Rules: []GatheringRule{
+				{
+					Conditions: []ConditionWithParams{},
+					GatheringFunctions: map[GatheringFunctionName]interface{}{
+						GatherEventsOfNamespace: GatherEventsOfNamespaceParams{
+							Namespace: "openshift-monitoring",
+							Types:     []string{"Error"},
+							MaxCount:  1001,
+						},
+					},
+				},
+			},
+			Errors: []string{
+				`0.gathering_functions.events_of_namespace.max_count: Must be less than or equal to 1000`,
+				`0.gathering_functions.events_of_namespace.types.0: 0.gathering_functions.events_of_namespace.types.0 must be one of the following: "Normal", "Warning"`,
+			},
+		},
+		{
+			Name: "GatherResourcesOfKind secrets are not allowed",
+			Rules: []GatheringRule{
+				{
+					Conditions: []ConditionWithParams{},
+					GatheringFunctions: map[GatheringFunctionName]interface{}{
+						GatherResourcesOfKind: GatherResourcesOfKindParams{
+							Version:  "v1",
+							Resource: "secrets",
+							Fields:   []string{"data"},
+							MaxCount: 1,
+						},
+					},
+				},
+			},
+			Errors: []string{
+				`0.gathering_functions.resources_of_kind: Must not validate the schema (not)`,
+			},
+		},
+		{
+			Name: "GatherResourcesOfKind OAuth tokens are not allowed",
+			Rules: []GatheringRule{
+				{
+					Conditions: []ConditionWithParams{},
+					GatheringFunctions: map[GatheringFunctionName]interface{}{
+						GatherResourcesOfKind: GatherResourcesOfKindParams{
+							Group:    "oauth.openshift.io",
+							Version:  "v1",
+							Resource: "oauthaccesstokens",
+							Fields:   []string{"userName"},
+							MaxCount: 1,
+						},
+					},
+				},
+			},
+			Errors: []string{
+				`0.gathering_functions.resources_of_kind: Must not validate the schema (not)`,
+			},
+		},
+		{
+			Name: "GatherResourcesOfKind namespace other than openshift-*",
+			Rules: []GatheringRule{
+				{
+					Conditions: []ConditionWithParams{},
+					GatheringFunctions: map[GatheringFunctionName]interface{}{
+						GatherResourcesOfKind: GatherResourcesOfKindParams{
+							Group:     "loki.grafana.com",
+							Version:   "v1",
+							Resource:  "lokistacks",
+							Namespace: "customer",
+							Fields:    []string{"spec.size"},
+							MaxCount:  1,
+						},
+					},
+				},
+			},
+			Errors: []string{
+				`0.gathering_functions.resources_of_kind.namespace: Does not match pattern '^openshift-[a-zA-Z0-9_.-]{1,128}$'`,
+			},
+		},
+		{
+			Name: "GatherResourcesOfKind invalid field path",
+			Rules: []GatheringRule{
+				{
+					Conditions: []ConditionWithParams{},
+					GatheringFunctions: map[GatheringFunctionName]interface{}{
+						GatherResourcesOfKind: GatherResourcesOfKindParams{
+							Group:    "loki.grafana.com",
+							Version:  "v1",
+							Resource: "lokistacks",
+							Fields:   []string{"spec..size"},
+							MaxCount: 1,
+						},
+					},
+				},
+			},
+			Errors: []string{
+				`0.gathering_functions.resources_of_kind.fields.0: Does not match pattern '^[a-zA-Z0-9_-]{1,64}(\.[a-zA-Z0-9_-]{1,64}){0,8}$'`,
+			},
+		},
+		{
+			Name: "GatherNodeJournal invalid unit",
+			Rules: []GatheringRule{
+				{
+					Conditions: []ConditionWithParams{},
+					GatheringFunctions: map[GatheringFunctionName]interface{}{
+						GatherNodeJournal: GatherNodeJournalParams{
+							NodeRole: "master",
+							Unit:     "crio; rm",
+							MaxLines: 10,
+						},
+					},
+				},
+			},
+			Errors: []string{
+				`0.gathering_functions.node_journal.unit: Does not match pattern '^[a-zA-Z0-9@_.-]{1,128}$'`,
+			},
+		},
+		{
+			Name: "GatherMetricsRange too small step",
+			Rules: []GatheringRule{
+				{
+					Conditions: []ConditionWithParams{},
+					GatheringFunctions: map[GatheringFunctionName]interface{}{
+						GatherMetricsRange: GatherMetricsRangeParams{
+							Name:          "etcd_leader_changes",
+							Query:         "etcd_server_leader_changes_seen_total",
+							WindowMinutes: 60,
+							StepSeconds:   1,
+							MaxSeries:     10,
+						},
+					},
+				},
+			},
+			Errors: []string{
+				`0.gathering_functions.metrics_range.step_seconds: Must be greater than or equal to 15`,
+			},
+		},
 		{
 			Name: "GatherContainersLogs invalid container name",
 			Rules: []GatheringRule{
@@ -618,3 +828,97 @@ func assertErrsMatchStrings(t *testing.T, errs []error, expectedStrings []string
 
 	assert.Equal(t, expectedStrings, errStrings, message)
 }
+
+func Test_Validation_ConditionGroups(t *testing.T) {
+	gatheringFunctions := map[GatheringFunctionName]interface{}{
+		GatherImageStreamsOfNamespace: GatherImageStreamsOfNamespaceParams{
+			Namespace: "openshift-something",
+		},
+	}
+	validRules := []GatheringRule{{
+		Conditions: []ConditionWithParams{
+			{
+				Type: Any,
+				Conditions: []ConditionWithParams{
+					{Type: AlertIsFiring, Alert: &AlertConditionParams{Name: "AlertA"}},
+					{Type: AlertIsFiring, Alert: &AlertConditionParams{Name: "AlertB"}},
+				},
+			},
+			{
+				Type: Not,
+				Condition: &ConditionWithParams{
+					Type:                  ClusterVersionMatches,
+					ClusterVersionMatches: &ClusterVersionMatchesConditionParams{Version: "4.12.x"},
+				},
+			},
+		},
+		GatheringFunctions: gatheringFunctions,
+	}}
+	assert.Empty(t, validateGatheringRules(validRules))
+
+	clusterConditionRules := []GatheringRule{{
+		Conditions: []ConditionWithParams{
+			{Type: PlatformIs, Platform: &PlatformIsConditionParams{Type: "BareMetal"}},
+			{Type: FeatureGateEnabled, FeatureGate: &FeatureGateEnabledConditionParams{Name: "GatewayAPI"}},
+			{Type: ClusterOperatorDegraded, ClusterOperator: &ClusterOperatorConditionParams{Name: "machine-config"}},
+			{Type: ResourceExists, Resource: &ResourceExistsConditionParams{
+				Group: "loki.grafana.com", Version: "v1", Resource: "lokistacks", Namespace: "openshift-logging",
+			}},
+		},
+		GatheringFunctions: gatheringFunctions,
+	}}
+	assert.Empty(t, validateGatheringRules(clusterConditionRules))
+
+	testCases := []validationTestCase{
+		{
+			Name: "empty any condition",
+			Rules: []GatheringRule{{
+				Conditions:         []ConditionWithParams{{Type: Any}},
+				GatheringFunctions: gatheringFunctions,
+			}},
+			Errors: []string{
+				`0.conditions.0: Must validate at least one schema (anyOf)`,
+				`0.conditions.0: conditions is required`,
+			},
+		},
+		{
+			Name: "invalid nested condition",
+			Rules: []GatheringRule{{
+				Conditions: []ConditionWithParams{{
+					Type:      Not,
+					Condition: &ConditionWithParams{Type: AlertIsFiring, Alert: &AlertConditionParams{Name: "$^#"}},
+				}},
+				GatheringFunctions: gatheringFunctions,
+			}},
+			Errors: []string{
+				`0.conditions.0.condition.alert.name: Does not match pattern '^[a-zA-Z0-9_]{1,128}$'`,
+				`0.conditions.0.condition: Must validate at least one schema (anyOf)`,
+				`0.conditions.0: Must validate at least one schema (anyOf)`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			errs := validateGatheringRules(tc.Rules)
+			assertErrsMatchStrings(t, errs, tc.Errors, tc.Name)
+		})
+	}
+}
+
+func Test_Validation_RolloutPercentage(t *testing.T) {
+	remoteConfig, err := parseRemoteConfiguration([]byte(testRemoteConfig))
+	assert.NoError(t, err)
+
+	for _, percentage := range []int{0, 50, 100} {
+		remoteConfig.RolloutPercentage = &percentage
+		assert.Empty(t, validateRemoteConfig(remoteConfig))
+	}
+
+	for _, percentage := range []int{-1, 101} {
+		remoteConfig.RolloutPercentage = &percentage
+		assertErrsMatchStrings(t, validateRemoteConfig(remoteConfig), []string{
+			fmt.Sprintf("rollout_percentage must be between 0 and 100, got %d", percentage),
+		}, "invalid rollout percentage")
+	}
+}
@@ -2,6 +2,7 @@ package gatherers
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/openshift/insights-operator/pkg/record"
 )
@@ -41,6 +42,15 @@ type GatheringClosure struct {
 	Run func(context.Context) ([]record.Record, []error)
 }
 
+const (
+	// RemoteConfigSourceRemote means that the remote configuration fetched from the service is used
+	RemoteConfigSourceRemote = "Remote"
+	// RemoteConfigSourceLastKnownGood means that the last valid remote configuration stored on the storage path is used
+	RemoteConfigSourceLastKnownGood = "LastKnownGood"
+	// RemoteConfigSourceBuiltIn means that the default configuration built into the operator is used
+	RemoteConfigSourceBuiltIn = "BuiltIn"
+)
+
 // RemoteConfigStatus is a struct providing information about the availability
 // and validity of the remote configuration
 type RemoteConfigStatus struct {
@@ -50,4 +60,14 @@ type RemoteConfigStatus struct {
 	ConfigData      []byte
 	ConfigAvailable bool
 	ConfigValid     bool
+	// Source is the source of the configuration used for the data gathering
+	Source string
+}
+
+// ErrorMessage returns the message of the error extended with the source of the used configuration
+func (s *RemoteConfigStatus) ErrorMessage() string {
+	if s.Source == "" {
+		return s.Err.Error()
+	}
+	return fmt.Sprintf("%v (configuration source: %s)", s.Err, s.Source)
 }
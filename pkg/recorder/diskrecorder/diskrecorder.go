@@ -5,6 +5,7 @@ import (
 	"compress/gzip"
 	"context"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -62,14 +63,75 @@ func (d *DiskRecorder) SaveAtPath(records record.MemoryRecords, path string) (re
 	}
 	defer f.Close()
 
-	completed := make([]record.MemoryRecord, 0, len(records))
-	defer func() {
-		wrote = len(completed)
-	}()
-
 	klog.Infof("Writing %d records to %s", len(records), path)
 
-	gw := gzip.NewWriter(f)
+	completed, err := WriteArchive(f, records)
+	wrote = len(completed)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("unable to close file: %v", err)
+	}
+
+	return completed, nil
+}
+
+// ReadArchive reads all the records from the archive at the path
+func ReadArchive(path string) (map[string]*record.MemoryRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	return ReadRecords(file)
+}
+
+// ReadRecords reads all the records from the gzipped tarball
+func ReadRecords(r io.Reader) (map[string]*record.MemoryRecord, error) {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+
+	records := make(map[string]*record.MemoryRecord)
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, err
+		}
+
+		records[header.Name] = &record.MemoryRecord{
+			Name: header.Name,
+			At:   header.ModTime,
+			Data: content,
+		}
+	}
+
+	return records, nil
+}
+
+// WriteArchive writes the records as a gzipped tarball to the writer and returns the written records
+func WriteArchive(w io.Writer, records record.MemoryRecords) (record.MemoryRecords, error) {
+	completed := make([]record.MemoryRecord, 0, len(records))
+
+	gw := gzip.NewWriter(w)
 	tw := tar.NewWriter(gw)
 
 	for _, r := range records {
@@ -80,22 +142,19 @@ func (d *DiskRecorder) SaveAtPath(records record.MemoryRecords, path string) (re
 			Size:     int64(len(r.Data)),
 			Typeflag: tar.TypeReg,
 		}); err != nil {
-			return nil, fmt.Errorf("unable to write tar header: %v", err)
+			return completed, fmt.Errorf("unable to write tar header: %v", err)
 		}
 		if _, err := tw.Write(r.Data); err != nil {
-			return nil, fmt.Errorf("unable to write tar entry: %v", err)
+			return completed, fmt.Errorf("unable to write tar entry: %v", err)
 		}
 		completed = append(completed, r)
 	}
 
 	if err := tw.Close(); err != nil {
-		return nil, fmt.Errorf("unable to close tar writer: %v", err)
+		return completed, fmt.Errorf("unable to close tar writer: %v", err)
 	}
 	if err := gw.Close(); err != nil {
-		return nil, fmt.Errorf("unable to close gzip writer: %v", err)
-	}
-	if err := f.Close(); err != nil {
-		return nil, fmt.Errorf("unable to close file: %v", err)
+		return completed, fmt.Errorf("unable to close gzip writer: %v", err)
 	}
 
 	return completed, nil
@@ -23,6 +23,9 @@ const MaxArchiveSize = 24 * 1024 * 1024
 // maxFilenameLength defines the maximum allowed filename length
 const maxFilenameLength = 255
 
+// maxReportedLeaks defines the maximum number of the logged sensitive values found in the obfuscated archive
+const maxReportedLeaks = 20
+
 // MetadataRecordName defines the metadata record name
 const MetadataRecordName = "insights-operator/gathers"
 
@@ -153,9 +156,40 @@ func (r *Recorder) Flush() error {
 		return err
 	}
 
+	r.verifyObfuscation(records)
 	return nil
 }
 
+// verifyObfuscation scans the flushed records for the known sensitive values
+// which remained in the archive after the obfuscation and logs them
+func (r *Recorder) verifyObfuscation(records record.MemoryRecords) {
+	if r.anonymizer == nil {
+		return
+	}
+
+	for _, anonymizer := range r.anonymizer.Anonymizers {
+		netAnonymizer, ok := anonymizer.(*anonymization.NetworkAnonymizer)
+		if !ok || !netAnonymizer.IsEnabled() {
+			continue
+		}
+
+		leaks := netAnonymizer.LeakDetector().ScanRecords(records)
+		if len(leaks) == 0 {
+			klog.Info("Obfuscation verified, no known sensitive values found in the archive")
+			continue
+		}
+
+		klog.Warningf("Found %d known sensitive values in the obfuscated archive", len(leaks))
+		for i := range leaks {
+			if i == maxReportedLeaks {
+				klog.Warningf("... and %d more", len(leaks)-maxReportedLeaks)
+				break
+			}
+			klog.Warningf("Sensitive value found in the obfuscated archive: %s", leaks[i])
+		}
+	}
+}
+
 func (r *Recorder) storeTranslationTables() {
 	if r.anonymizer == nil {
 		return